@@ -0,0 +1,196 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/phsym/kmip-explorer/internal/audit"
+	"github.com/phsym/kmip-explorer/internal/widgets"
+)
+
+// AuditMiddleware returns a client middleware appending an entry to the
+// JSON-lines audit log at path for each operation which may modify objects
+// (those [ReadOnlyMiddleware] refuses), with the address of the server, the
+// subject of the client certificate, the objects operated on, the parameters
+// of the request and its result. Key material is never recorded.
+//
+// Such requests are refused when the log can't be written. If the entry can't
+// be appended once the server answered, the request fails with an error
+// telling so.
+func AuditMiddleware(path, server, subject string) kmipclient.Middleware {
+	return func(next kmipclient.Next, ctx context.Context, msg *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		var audited []int
+		for i, item := range msg.BatchItem {
+			if !slices.Contains(readOnlyOperations, item.Operation) {
+				audited = append(audited, i)
+			}
+		}
+		if len(audited) == 0 {
+			return next(ctx, msg)
+		}
+		if err := audit.Check(path); err != nil {
+			return nil, fmt.Errorf("audit log: %w", err)
+		}
+
+		resp, err := next(ctx, msg)
+		now := time.Now()
+		entries := make([]audit.Entry, 0, len(audited))
+		for _, i := range audited {
+			item := msg.BatchItem[i]
+			e := audit.Entry{
+				Time:      now,
+				Server:    server,
+				Subject:   subject,
+				Operation: ttlv.EnumStr(item.Operation),
+				IDs:       auditIDs(nil, item.RequestPayload),
+				Params:    auditParams(item.RequestPayload),
+				Result:    audit.ResultSuccess,
+			}
+			switch {
+			case err != nil:
+				e.Result, e.Error = audit.ResultFailure, err.Error()
+			case resp == nil || i >= len(resp.BatchItem):
+				e.Result, e.Error = audit.ResultFailure, "no response"
+			case resp.BatchItem[i].ResultStatus != kmip.ResultStatusSuccess:
+				r := resp.BatchItem[i]
+				e.Result, e.Error = audit.ResultFailure, ttlv.EnumStr(r.ResultReason)
+				if r.ResultMessage != "" {
+					e.Error += ": " + r.ResultMessage
+				}
+			default:
+				e.IDs = auditIDs(e.IDs, resp.BatchItem[i].ResponsePayload)
+			}
+			entries = append(entries, e)
+		}
+		if aerr := audit.Append(path, entries...); aerr != nil && err == nil {
+			err = fmt.Errorf("done, but not recorded in the audit log: %w", aerr)
+		}
+		return resp, err
+	}
+}
+
+// auditIDs adds to ids the object identifiers found in a request or response
+// payload, those of its fields named "...UniqueIdentifier".
+func auditIDs(ids []string, payload any) []string {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ids
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ids
+	}
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() || !strings.HasSuffix(f.Name, "UniqueIdentifier") || f.Type.Kind() != reflect.String {
+			continue
+		}
+		if id := v.Field(i).String(); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// objectType is the type of the objects holding key material.
+var objectType = reflect.TypeFor[kmip.Object]()
+
+// auditParams returns the parameters of a request payload, by field or
+// attribute name, but its object identifiers and any key material: the values
+// of objects (keys, secrets, certificates ...) and byte strings are left out.
+func auditParams(payload kmip.OperationPayload) map[string]string {
+	params := map[string]string{}
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := range v.NumField() {
+		if f := v.Type().Field(i); f.IsExported() && !strings.HasSuffix(f.Name, "UniqueIdentifier") {
+			addAuditParam(params, f.Name, v.Field(i))
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+func addAuditParam(params map[string]string, name string, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() || v.Type().Implements(objectType) {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsZero() || v.Type().Implements(objectType) || reflect.PointerTo(v.Type()).Implements(objectType) {
+		return
+	}
+	switch x := v.Interface().(type) {
+	case []byte:
+		return
+	case kmip.Attribute:
+		addAuditParam(params, string(x.AttributeName), reflect.ValueOf(x.AttributeValue))
+		return
+	case time.Time:
+		setAuditParam(params, name, x.UTC().Format(time.RFC3339))
+		return
+	case kmip.Name, kmip.Link:
+		setAuditParam(params, name, widgets.AttributeText(x))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if f := v.Type().Field(i); f.IsExported() {
+				addAuditParam(params, f.Name, v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			addAuditParam(params, name, v.Index(i))
+		}
+	default:
+		setAuditParam(params, name, widgets.AttributeText(v.Interface()))
+	}
+}
+
+// setAuditParam sets a parameter, joining the values of a parameter found
+// several times, e.g. the names of an object.
+func setAuditParam(params map[string]string, name, value string) {
+	old, ok := params[name]
+	switch {
+	case !ok:
+		params[name] = value
+	case !slices.Contains(strings.Split(old, "; "), value):
+		params[name] = old + "; " + value
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+	"github.com/phsym/kmip-explorer/internal/audit"
+)
+
+// testSecret stands for an object holding key material.
+type testSecret struct {
+	Value string
+	Bytes []byte
+}
+
+func (*testSecret) ObjectType() kmip.ObjectType { return 0 }
+
+type testRegisterRequest struct {
+	TemplateAttribute struct{ Attribute []kmip.Attribute }
+	Object            kmip.Object
+}
+
+func (*testRegisterRequest) Operation() kmip.Operation { return kmip.OperationRegister }
+
+type testRegisterResponse struct{ UniqueIdentifier string }
+
+func (*testRegisterResponse) Operation() kmip.Operation { return kmip.OperationRegister }
+
+type testDestroyRequest struct{ UniqueIdentifier string }
+
+func (*testDestroyRequest) Operation() kmip.Operation { return kmip.OperationDestroy }
+
+func TestAuditMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	mw := AuditMiddleware(path, "kms:5696", "CN=alice")
+
+	register := &testRegisterRequest{Object: &testSecret{Value: "s3cr3t", Bytes: []byte("s3cr3t")}}
+	register.TemplateAttribute.Attribute = []kmip.Attribute{
+		{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "db-password"}},
+	}
+	next := func(_ context.Context, msg *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		resp := &kmip.ResponseMessage{}
+		for _, item := range msg.BatchItem {
+			switch item.RequestPayload.(type) {
+			case *testRegisterRequest:
+				resp.BatchItem = append(resp.BatchItem, kmip.ResponseBatchItem{ResponsePayload: &testRegisterResponse{UniqueIdentifier: "new-1"}})
+			default:
+				resp.BatchItem = append(resp.BatchItem, kmip.ResponseBatchItem{ResultStatus: kmip.ResultStatusOperationFailed, ResultMessage: "denied"})
+			}
+		}
+		return resp, nil
+	}
+	request := func(payloads ...kmip.OperationPayload) *kmip.RequestMessage {
+		msg := &kmip.RequestMessage{}
+		for _, p := range payloads {
+			msg.BatchItem = append(msg.BatchItem, kmip.RequestBatchItem{Operation: p.Operation(), RequestPayload: p})
+		}
+		return msg
+	}
+
+	if _, err := mw(next, context.Background(), request(register, &testDestroyRequest{UniqueIdentifier: "k1"})); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	// Lookups aren't recorded.
+	if _, err := mw(next, context.Background(), &kmip.RequestMessage{BatchItem: []kmip.RequestBatchItem{{Operation: kmip.OperationGetAttributes}}}); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	failing := func(context.Context, *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		return nil, errors.New("connection reset")
+	}
+	if _, err := mw(failing, context.Background(), request(&testDestroyRequest{UniqueIdentifier: "k2"})); err == nil {
+		t.Fatal("failing request: err = nil")
+	}
+
+	entries, err := audit.Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("%d entries, want 3: %+v", len(entries), entries)
+	}
+	reg, destroy, failed := entries[0], entries[1], entries[2]
+	if reg.Server != "kms:5696" || reg.Subject != "CN=alice" || reg.Result != audit.ResultSuccess ||
+		!slices.Equal(reg.IDs, []string{"new-1"}) || reg.Params["Name"] != "db-password" {
+		t.Fatalf("register entry = %+v", reg)
+	}
+	if destroy.Result != audit.ResultFailure || !strings.Contains(destroy.Error, "denied") || !slices.Equal(destroy.IDs, []string{"k1"}) {
+		t.Fatalf("destroy entry = %+v", destroy)
+	}
+	if failed.Result != audit.ResultFailure || failed.Error != "connection reset" || !slices.Equal(failed.IDs, []string{"k2"}) {
+		t.Fatalf("failed entry = %+v", failed)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cr3t") || strings.Contains(string(data), "czNjcjN0") {
+		t.Fatalf("key material in the audit log:\n%s", data)
+	}
+}
+
+func TestAuditMiddlewareRefusesWithoutLog(t *testing.T) {
+	// A directory can't be opened as the log.
+	mw := AuditMiddleware(t.TempDir(), "kms:5696", "")
+	sent := false
+	next := func(context.Context, *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		sent = true
+		return &kmip.ResponseMessage{}, nil
+	}
+	msg := &kmip.RequestMessage{BatchItem: []kmip.RequestBatchItem{{Operation: kmip.OperationDestroy, RequestPayload: &testDestroyRequest{UniqueIdentifier: "k1"}}}}
+	if _, err := mw(next, context.Background(), msg); err == nil || sent {
+		t.Fatalf("err = %v, sent = %v, want the request refused", err, sent)
+	}
+}
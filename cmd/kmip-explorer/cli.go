@@ -0,0 +1,594 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/phsym/kmip-explorer/internal/components"
+	"github.com/phsym/kmip-explorer/internal/locate"
+	"github.com/phsym/kmip-explorer/internal/query"
+	"github.com/phsym/kmip-explorer/internal/widgets"
+	"github.com/phsym/kmip-explorer/internal/widgets/modals"
+)
+
+// subcommands run a single operation instead of the UI, for scripting, e.g.
+// "kmip-explorer -profile dev ls -o json state=active".
+var subcommands = map[string]func(c *cli, args []string) error{
+	"ls":       (*cli).ls,
+	"get":      (*cli).get,
+	"attrs":    (*cli).attrs,
+	"create":   (*cli).create,
+	"register": (*cli).register,
+	"activate": (*cli).activate,
+	"revoke":   (*cli).revoke,
+	"rekey":    (*cli).rekey,
+	"destroy":  (*cli).destroy,
+}
+
+// subcommandUsages are the arguments of the subcommands, after their name.
+var subcommandUsages = map[string]string{
+	"ls":       "[-o format] [-columns list] [query terms...]",
+	"get":      "[-o format] id",
+	"attrs":    "[-o format] id",
+	"create":   "[-o format] -type AES|RSA|EC|HMAC [-size bits] [-curve name] [-hash name] [-name name] [-activation date] [-deactivation date] [-process-start date] [-protect-stop date]",
+	"register": "[-o format] -type type [-format format] [-name name] [-activation date] [-deactivation date] [-process-start date] [-protect-stop date] [file]",
+	"activate": "[-o format] id...",
+	"revoke":   "[-o format] [-reason reason] [-message text] id...",
+	"rekey":    "[-o format] [-offset days] id...",
+	"destroy":  "[-o format] id...",
+}
+
+// subcommandNames returns the names of the subcommands, sorted.
+func subcommandNames() []string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+var (
+	// errFailed is returned by the subcommands which already reported their
+	// errors.
+	errFailed = errors.New("some operations failed")
+	// errUsage is returned on invalid arguments, once the usage is printed.
+	errUsage = errors.New("invalid usage")
+)
+
+// cli runs the subcommands.
+type cli struct {
+	dial    func() (*kmipclient.Client, error)
+	client  *kmipclient.Client // connected once the arguments are parsed
+	columns []string           // the configured columns of ls
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// runSubcommand runs the subcommand named by args[0] with the rest of args.
+func runSubcommand(c *cli, args []string) error {
+	run, ok := subcommands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "Unknown command %q, expected one of %s\n", args[0], strings.Join(subcommandNames(), ", "))
+		return errUsage
+	}
+	return run(c, args[1:])
+}
+
+// output formats of the subcommands.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatTTLV  = "ttlv"
+)
+
+// flags creates the flag set of a subcommand, with its -o output format flag.
+func (c *cli) flags(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kmip-explorer [flags] %s %s\n", name, subcommandUsages[name])
+		fs.PrintDefaults()
+	}
+	format := fs.String("o", formatTable, "Output format: table, json or ttlv")
+	return fs, format
+}
+
+// parse parses the arguments of a subcommand and checks its output format and
+// its number of positional arguments, between minArgs and maxArgs (or more if
+// maxArgs is negative), then connects to the server.
+func (c *cli) parse(fs *flag.FlagSet, args []string, format *string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	switch *format {
+	case formatTable, formatJSON, formatTTLV:
+	default:
+		fmt.Fprintf(fs.Output(), "invalid output format %q\n", *format)
+		fs.Usage()
+		return errUsage
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return errUsage
+	}
+	client, err := c.dial()
+	if err != nil {
+		return err
+	}
+	c.client = client
+	return nil
+}
+
+// ls lists the objects matching a query, with the columns of the table.
+func (c *cli) ls(args []string) error {
+	fs, format := c.flags("ls")
+	columns := fs.String("columns", "", "Comma separated list of columns (default from the configuration file, else "+strings.Join(widgets.DefaultColumns, ",")+")")
+	if err := c.parse(fs, args, format, 0, -1); err != nil {
+		return err
+	}
+	criteria, err := query.Parse(queryText(fs.Args()))
+	if err != nil {
+		return err
+	}
+	names := c.columns
+	if *columns != "" {
+		names = strings.Split(*columns, ",")
+	}
+	names = widgets.ColumnNames(names)
+
+	ids, err := locate.All(c.client, criteria...)
+	if err != nil {
+		return err
+	}
+	objects := make([]*payloads.GetAttributesResponsePayload, 0, len(ids))
+	for _, id := range ids {
+		attrs, err := c.client.GetAttributes(id).Exec()
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		objects = append(objects, attrs)
+	}
+
+	switch *format {
+	case formatTTLV:
+		values := make([]any, len(objects))
+		for i, obj := range objects {
+			values[i] = obj
+		}
+		return c.writeTTLV(values...)
+	case formatJSON:
+		rows := make([]map[string]string, len(objects))
+		for i, obj := range objects {
+			rows[i] = map[string]string{}
+			for j, txt := range widgets.ColumnTexts(obj, names) {
+				rows[i][names[j]] = txt
+			}
+		}
+		return c.writeJSON(rows)
+	}
+	rows := make([][]string, len(objects))
+	for i, obj := range objects {
+		rows[i] = widgets.ColumnTexts(obj, names)
+	}
+	return c.writeTable(names, rows)
+}
+
+// queryText joins query terms given as arguments, quoting back those the shell
+// unquoted, e.g. name="prod key" received as `name=prod key`.
+func queryText(args []string) string {
+	terms := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsFunc(arg, unicode.IsSpace) && !strings.Contains(arg, `"`) {
+			arg = `"` + arg + `"`
+		}
+		terms[i] = arg
+	}
+	return strings.Join(terms, " ")
+}
+
+// get prints the material of an object, as the content viewer shows it.
+func (c *cli) get(args []string) error {
+	fs, format := c.flags("get")
+	if err := c.parse(fs, args, format, 1, 1); err != nil {
+		return err
+	}
+	resp, err := c.client.Get(fs.Arg(0)).Exec()
+	if err != nil {
+		return err
+	}
+	if *format == formatTTLV {
+		return c.writeTTLV(resp)
+	}
+	content, err := modals.ObjectContent(resp.Object)
+	if err != nil {
+		return err
+	}
+	if *format == formatJSON {
+		return c.writeJSON(map[string]string{
+			"id":      resp.UniqueIdentifier,
+			"type":    ttlv.EnumStr(resp.ObjectType),
+			"content": content,
+		})
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	_, err = io.WriteString(c.stdout, content)
+	return err
+}
+
+// attrs prints the attributes of an object.
+func (c *cli) attrs(args []string) error {
+	fs, format := c.flags("attrs")
+	if err := c.parse(fs, args, format, 1, 1); err != nil {
+		return err
+	}
+	resp, err := c.client.GetAttributes(fs.Arg(0)).Exec()
+	if err != nil {
+		return err
+	}
+	if *format == formatTTLV {
+		return c.writeTTLV(resp)
+	}
+	type attribute struct {
+		Name  string `json:"name"`
+		Index *int32 `json:"index,omitempty"`
+		Value string `json:"value"`
+	}
+	attrs := make([]attribute, len(resp.Attribute))
+	rows := make([][]string, len(resp.Attribute))
+	for i, attr := range resp.Attribute {
+		attrs[i] = attribute{Name: string(attr.AttributeName), Index: attr.AttributeIndex, Value: widgets.AttributeText(attr.AttributeValue)}
+		index := ""
+		if attr.AttributeIndex != nil {
+			index = fmt.Sprint(*attr.AttributeIndex)
+		}
+		rows[i] = []string{attrs[i].Name, index, attrs[i].Value}
+	}
+	if *format == formatJSON {
+		return c.writeJSON(attrs)
+	}
+	return c.writeTable([]string{"Name", "Index", "Value"}, rows)
+}
+
+// create creates a key, with the request of the create dialog.
+func (c *cli) create(args []string) error {
+	fs, format := c.flags("create")
+	types, params := modals.CreateKeyTypes()
+	kty := fs.String("type", "", "Key type: "+strings.Join(types, ", "))
+	size := fs.String("size", "", "Key size of AES keys ("+strings.Join(params["AES"], ", ")+", default "+params["AES"][0]+") or modulus size of RSA keys ("+strings.Join(params["RSA"], ", ")+", default "+params["RSA"][0]+")")
+	curve := fs.String("curve", "", "Curve of EC keys: "+strings.Join(params["EC"], ", ")+" (default "+params["EC"][0]+")")
+	hash := fs.String("hash", "", "Hash of HMAC keys: "+strings.Join(params["HMAC"], ", ")+" (default "+params["HMAC"][0]+")")
+	name := fs.String("name", "", "Name of the key, suffixed with -Private and -Public for key pairs")
+	schedule := scheduleFlags(fs)
+	if err := c.parse(fs, args, format, 0, 0); err != nil {
+		return err
+	}
+	dates, err := schedule(time.Now())
+	if err != nil {
+		return err
+	}
+	keyType, err := matchOption("key type", *kty, types)
+	if err != nil {
+		return err
+	}
+	param := map[string]string{"AES": *size, "RSA": *size, "EC": *curve, "HMAC": *hash}[keyType]
+	if param == "" {
+		param = params[keyType][0]
+	} else if param, err = matchOption("parameter", param, params[keyType]); err != nil {
+		return err
+	}
+	req, err := modals.CreateRequest(keyType, param, *name, dates)
+	if err != nil {
+		return err
+	}
+	resp, err := req(c.client)
+	if err != nil {
+		return err
+	}
+	return c.writeResults(*format, false, resp)
+}
+
+// register registers an object read from a file, or from the standard input,
+// with the request of the register dialog.
+func (c *cli) register(args []string) error {
+	fs, format := c.flags("register")
+	types := modals.RegisterTypes()
+	oType := fs.String("type", "", "Object type: "+strings.Join(types, ", "))
+	valueFormat := fs.String("format", "", `Encoding of secrets ("text", the default, or "base64") and AES keys ("hex", the default, or "base64"); certificates and asymmetric keys are read in PEM format`)
+	name := fs.String("name", "", "Name of the object")
+	schedule := scheduleFlags(fs)
+	if err := c.parse(fs, args, format, 0, 1); err != nil {
+		return err
+	}
+	dates, err := schedule(time.Now())
+	if err != nil {
+		return err
+	}
+	objectType, err := matchOption("object type", *oType, types)
+	if err != nil {
+		return err
+	}
+	vFormat := ""
+	if *valueFormat != "" {
+		if vFormat, err = matchOption("format", *valueFormat, []string{"Text", "Hex", "Base 64"}); err != nil {
+			return err
+		}
+	}
+	value, err := c.readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	switch {
+	case objectType == "Secret" && (vFormat == "" || vFormat == "Text"):
+		// Like "echo password | kmip-explorer register -type secret".
+		value = strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+	case objectType == "Secret" || objectType == "AES Key":
+		value = strings.Join(strings.Fields(value), "")
+	}
+	req, err := modals.RegisterRequest(objectType, *name, value, vFormat, dates)
+	if err != nil {
+		return err
+	}
+	resp, err := req(c.client)
+	if err != nil {
+		return err
+	}
+	return c.writeResults(*format, false, resp)
+}
+
+// scheduleFlags adds a flag for each of the dates which can be scheduled on a
+// new object (see modals.ScheduleDates), e.g. -activation for the Activation
+// Date. The returned function reads them relative to now.
+func scheduleFlags(fs *flag.FlagSet) func(now time.Time) ([]kmip.Attribute, error) {
+	texts := make([]*string, len(modals.ScheduleDates))
+	for i, name := range modals.ScheduleDates {
+		flagName := strings.ToLower(strings.ReplaceAll(strings.TrimSuffix(string(name), " Date"), " ", "-"))
+		texts[i] = fs.String(flagName, "", string(name)+", relative to now such as +30d, or in RFC 3339")
+	}
+	return func(now time.Time) ([]kmip.Attribute, error) {
+		var attrs []kmip.Attribute
+		for i, text := range texts {
+			if *text == "" {
+				continue
+			}
+			date, err := components.ParseDate(*text, now)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", modals.ScheduleDates[i], err)
+			}
+			attrs = append(attrs, kmip.Attribute{AttributeName: modals.ScheduleDates[i], AttributeValue: date})
+		}
+		return attrs, nil
+	}
+}
+
+// readInput reads a file, or the standard input if path is empty or "-".
+func (c *cli) readInput(path string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "" || path == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	return string(data), err
+}
+
+// activate activates objects.
+func (c *cli) activate(args []string) error {
+	fs, format := c.flags("activate")
+	if err := c.parse(fs, args, format, 1, -1); err != nil {
+		return err
+	}
+	return c.forEach(*format, fs.Args(), func(id string) (any, error) {
+		return c.client.Activate(id).Exec()
+	})
+}
+
+// revoke revokes objects, with the request of the revoke dialog.
+func (c *cli) revoke(args []string) error {
+	fs, format := c.flags("revoke")
+	reason := fs.String("reason", modals.RevocationReasons[0], "Revocation reason: "+strings.Join(modals.RevocationReasons, ", "))
+	msg := fs.String("message", "", "Revocation message")
+	if err := c.parse(fs, args, format, 1, -1); err != nil {
+		return err
+	}
+	r, err := matchOption("reason", *reason, modals.RevocationReasons)
+	if err != nil {
+		return err
+	}
+	//nolint: gosec // there are only a few reasons
+	req := modals.RevokeRequest(kmip.RevocationReasonCode(slices.Index(modals.RevocationReasons, r)+1), *msg)
+	return c.forEach(*format, fs.Args(), func(id string) (any, error) {
+		return req(c.client, id)
+	})
+}
+
+// rekey rekeys symmetric keys, or key pairs given their private key, with the
+// request of the rekey dialog.
+func (c *cli) rekey(args []string) error {
+	fs, format := c.flags("rekey")
+	offset := fs.Int("offset", -1, "Days between the initialization date of the old key and the activation date of the new one (default: left to the server)")
+	if err := c.parse(fs, args, format, 1, -1); err != nil {
+		return err
+	}
+	off := time.Duration(-1)
+	if *offset >= 0 {
+		off = time.Duration(*offset) * 24 * time.Hour
+	}
+	// The server tells which rekey operations it supports with its errors.
+	req := modals.RekeyRequest(off, modals.Capabilities{})
+	return c.forEach(*format, fs.Args(), func(id string) (any, error) {
+		return req(c.client, id)
+	})
+}
+
+// destroy destroys objects.
+func (c *cli) destroy(args []string) error {
+	fs, format := c.flags("destroy")
+	if err := c.parse(fs, args, format, 1, -1); err != nil {
+		return err
+	}
+	return c.forEach(*format, fs.Args(), func(id string) (any, error) {
+		return c.client.Destroy(id).Exec()
+	})
+}
+
+// forEach runs an operation on each id, reporting the errors and going on with
+// the next id, then writes the results.
+func (c *cli) forEach(format string, ids []string, op func(id string) (any, error)) error {
+	var results []any
+	failed := false
+	for _, id := range ids {
+		resp, err := op(id)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "ERROR: %s: %s\n", id, err)
+			failed = true
+			continue
+		}
+		results = append(results, resp)
+	}
+	if err := c.writeResults(format, true, results...); err != nil {
+		return err
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// matchOption returns the option matching value, ignoring case, spaces, dashes
+// and underscores, so that e.g. "aes-key" matches "AES Key".
+func matchOption(what, value string, options []string) (string, error) {
+	normalize := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r == ' ' || r == '-' || r == '_' {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, s)
+	}
+	for _, opt := range options {
+		if normalize(opt) == normalize(value) {
+			return opt, nil
+		}
+	}
+	return "", fmt.Errorf("invalid %s %q, expected one of %s", what, value, strings.Join(options, ", "))
+}
+
+// resultIDs returns the JSON keys and the ids of the objects an operation
+// created or changed.
+func resultIDs(resp any) ([]string, []string) {
+	switch r := resp.(type) {
+	case *payloads.CreateKeyPairResponsePayload:
+		return []string{"private_key_id", "public_key_id"}, []string{r.PrivateKeyUniqueIdentifier, r.PublicKeyUniqueIdentifier}
+	case *payloads.RekeyKeyPairResponsePayload:
+		return []string{"private_key_id", "public_key_id"}, []string{r.PrivateKeyUniqueIdentifier, r.PublicKeyUniqueIdentifier}
+	case *payloads.CreateResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	case *payloads.RegisterResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	case *payloads.ActivateResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	case *payloads.RevokeResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	case *payloads.RekeyResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	case *payloads.DestroyResponsePayload:
+		return []string{"id"}, []string{r.UniqueIdentifier}
+	}
+	return nil, nil
+}
+
+// writeResults writes the ids returned by operations: one line per operation in
+// table format, with the private then the public key id for key pairs, so that
+// scripts can capture them. In JSON, a list is written if list is set, else the
+// single result.
+func (c *cli) writeResults(format string, list bool, results ...any) error {
+	switch format {
+	case formatTTLV:
+		return c.writeTTLV(results...)
+	case formatJSON:
+		objs := make([]map[string]string, len(results))
+		for i, resp := range results {
+			objs[i] = map[string]string{}
+			keys, ids := resultIDs(resp)
+			for j, key := range keys {
+				objs[i][key] = ids[j]
+			}
+		}
+		if !list && len(objs) == 1 {
+			return c.writeJSON(objs[0])
+		}
+		return c.writeJSON(objs)
+	}
+	for _, resp := range results {
+		_, ids := resultIDs(resp)
+		if _, err := fmt.Fprintln(c.stdout, strings.Join(ids, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cli) writeTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	upper := make([]string, len(header))
+	for i, h := range header {
+		upper[i] = strings.ToUpper(h)
+	}
+	fmt.Fprintln(w, strings.Join(upper, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func (c *cli) writeJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) writeTTLV(values ...any) error {
+	for _, v := range values {
+		if _, err := fmt.Fprintf(c.stdout, "%s\n", ttlv.MarshalText(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
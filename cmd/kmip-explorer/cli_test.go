@@ -0,0 +1,106 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+)
+
+func TestMatchOption(t *testing.T) {
+	options := []string{"Secret", "X509 Certificate", "AES Key", "Base 64"}
+	for value, want := range map[string]string{
+		"secret":           "Secret",
+		"x509-certificate": "X509 Certificate",
+		"aes_key":          "AES Key",
+		"base64":           "Base 64",
+	} {
+		got, err := matchOption("option", value, options)
+		if err != nil || got != want {
+			t.Fatalf("matchOption(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := matchOption("option", "aes", options); err == nil {
+		t.Fatalf("matchOption(%q) should fail", "aes")
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	got := queryText([]string{"state=active", "name=prod key", `group="a b"`})
+	want := `state=active "name=prod key" group="a b"`
+	if got != want {
+		t.Fatalf("queryText() = %q, want %q", got, want)
+	}
+}
+
+func TestUsageErrorsDontConnect(t *testing.T) {
+	for _, args := range [][]string{
+		{"unknown"},
+		{"get"},
+		{"attrs", "id1", "id2"},
+		{"activate"},
+		{"ls", "-o", "yaml"},
+		{"destroy", "-nope", "id1"},
+	} {
+		var stderr bytes.Buffer
+		c := &cli{
+			dial: func() (*kmipclient.Client, error) {
+				t.Fatalf("%v: connected to the server", args)
+				return nil, nil
+			},
+			stdout: &bytes.Buffer{},
+			stderr: &stderr,
+		}
+		if err := runSubcommand(c, args); !errors.Is(err, errUsage) {
+			t.Fatalf("%v: got error %v, want a usage error", args, err)
+		}
+		if stderr.Len() == 0 {
+			t.Fatalf("%v: no usage printed", args)
+		}
+	}
+}
+
+func TestScheduleFlags(t *testing.T) {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	schedule := scheduleFlags(fs)
+	if err := fs.Parse([]string{"-activation", "+1d", "-protect-stop", "2030-01-01T00:00:00Z"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	attrs, err := schedule(now)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if len(attrs) != 2 ||
+		attrs[0].AttributeName != kmip.AttributeNameActivationDate || !attrs[0].AttributeValue.(time.Time).Equal(now.Add(24*time.Hour)) ||
+		attrs[1].AttributeName != kmip.AttributeNameProtectStopDate || !attrs[1].AttributeValue.(time.Time).Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("schedule() = %v", attrs)
+	}
+
+	fs = flag.NewFlagSet("create", flag.ContinueOnError)
+	schedule = scheduleFlags(fs)
+	if err := fs.Parse([]string{"-deactivation", "soon"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := schedule(now); err == nil {
+		t.Fatalf("schedule() with an invalid date should fail")
+	}
+}
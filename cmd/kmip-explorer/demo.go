@@ -0,0 +1,220 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/phsym/kmip-explorer/memkms"
+)
+
+// startDemo starts the in-memory server of the demo mode, seeded with sample
+// objects in every state, and returns the connection to it. dir receives the
+// certificates of the server.
+func startDemo(dir string) (connection, *memkms.Endpoint, error) {
+	srv := memkms.New()
+	ep, err := srv.Start(dir)
+	if err != nil {
+		return connection{}, nil, err
+	}
+	client, err := kmipclient.Dial(ep.Addr,
+		kmipclient.WithRootCAFile(ep.CAFile),
+		kmipclient.WithClientCertFiles(ep.CertFile, ep.KeyFile),
+	)
+	if err != nil {
+		ep.Close()
+		return connection{}, nil, err
+	}
+	defer client.Close()
+	if err := seedDemo(srv, client, time.Now()); err != nil {
+		ep.Close()
+		return connection{}, nil, fmt.Errorf("seeding the demo server: %w", err)
+	}
+	return connection{addr: ep.Addr, cert: ep.CertFile, key: ep.KeyFile, ca: ep.CAFile}, ep, nil
+}
+
+// seedDemo creates the sample objects of the demo: keys, a key pair, a
+// certificate and secrets, with a history of a few years before now.
+func seedDemo(srv *memkms.Server, client *kmipclient.Client, now time.Time) error {
+	s := &seeder{srv: srv, client: client, now: now}
+	const encrypt = kmip.CryptographicUsageEncrypt | kmip.CryptographicUsageDecrypt
+
+	payments := s.id(client.Create().AES(256, encrypt).WithName("payments-dek").Exec())
+	s.age(payments, 400)
+	s.activate(payments, 400)
+	s.add(payments, "x-owner", "payments-team")
+
+	orders := s.id(client.Create().AES(256, encrypt|kmip.CryptographicUsageWrapKey|kmip.CryptographicUsageUnwrapKey).
+		WithName("orders-kek").Exec())
+	s.age(orders, 800)
+	s.activate(orders, 800)
+	newOrders := s.id(client.Rekey(orders).Exec())
+	s.age(newOrders, 30)
+	s.date(newOrders, kmip.AttributeNameActivationDate, -30)
+	s.do(client.Revoke(orders).WithRevocationReasonCode(kmip.RevocationReasonCodeSuperseded).
+		WithRevocationMessage("Yearly rotation").Exec())
+	s.date(orders, kmip.AttributeNameDeactivationDate, -30)
+
+	staging := s.id(client.Create().AES(128, encrypt).WithName("staging-dek").Exec())
+	s.date(staging, kmip.AttributeNameActivationDate, 7)
+
+	leaked := s.id(client.Create().AES(256, encrypt).WithName("leaked-dek").Exec())
+	s.age(leaked, 200)
+	s.activate(leaked, 200)
+	s.do(client.Revoke(leaked).WithRevocationReasonCode(kmip.RevocationReasonCodeKeyCompromise).
+		WithRevocationMessage("Found in a public repository").Exec())
+
+	retired := s.id(client.Create().AES(128, encrypt).WithName("retired-dek").Exec())
+	s.age(retired, 1200)
+	s.activate(retired, 1200)
+	s.do(client.Revoke(retired).WithRevocationReasonCode(kmip.RevocationReasonCodeCessationOfOperation).Exec())
+	s.do(client.Destroy(retired).Exec())
+
+	hmacKey := sha256.Sum256([]byte("reports-hmac"))
+	reports := s.id(client.Register().SymmetricKey(kmip.CryptographicAlgorithmHMACSHA256,
+		kmip.CryptographicUsageMACGenerate|kmip.CryptographicUsageMACVerify, hmacKey[:]).
+		WithName("reports-hmac").Exec())
+	s.age(reports, 360)
+	s.activate(reports, 360)
+	s.date(reports, kmip.AttributeNameDeactivationDate, 5)
+
+	priv, pub, cert, err := demoKeyPair()
+	if err != nil {
+		return err
+	}
+	pubID := s.id(client.Register().PemPublicKey(pub, kmip.CryptographicUsageVerify).WithName("api-signing-pub").Exec())
+	privID := s.id(client.Register().PemPrivateKey(priv, kmip.CryptographicUsageSign).WithName("api-signing").
+		WithLink(kmip.LinkTypePublicKeyLink, pubID).Exec())
+	s.add(pubID, kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypePrivateKeyLink, LinkedObjectIdentifier: privID})
+	certID := s.id(client.Register().PemCertificate(cert).WithName("api.example.com").
+		WithLink(kmip.LinkTypePublicKeyLink, pubID).Exec())
+	s.add(pubID, kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypeCertificateLink, LinkedObjectIdentifier: certID})
+	for _, id := range []string{pubID, privID, certID} {
+		s.age(id, 90)
+		s.activate(id, 90)
+	}
+	s.date(certID, kmip.AttributeNameDeactivationDate, 275)
+
+	db := s.id(client.Register().Secret(kmip.SecretDataTypePassword, []byte("correct horse battery staple")).
+		WithName("db-password").Exec())
+	s.age(db, 60)
+	s.activate(db, 60)
+	s.date(db, kmip.AttributeNameDeactivationDate, 20)
+
+	legacy := s.id(client.Register().Secret(kmip.SecretDataTypePassword, []byte("hunter2")).
+		WithName("legacy-token").Exec())
+	s.age(legacy, 500)
+	return s.err
+}
+
+// demoKeyPair generates an ECDSA key pair and a certificate for it, in PEM.
+func demoKeyPair() (priv, pub, cert []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "api.example.com", Organization: []string{"Example"}},
+		DNSNames:     []string{"api.example.com"},
+		NotBefore:    time.Now().AddDate(0, 0, -90),
+		NotAfter:     time.Now().AddDate(0, 0, 275),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		nil
+}
+
+// seeder keeps the first error of the steps of seedDemo, which then fails as a
+// whole.
+type seeder struct {
+	srv    *memkms.Server
+	client *kmipclient.Client
+	now    time.Time
+	err    error
+}
+
+// id returns the identifier of the object an operation created or changed,
+// from its response.
+func (s *seeder) id(resp any, err error) string {
+	if s.do(resp, err); s.err != nil {
+		return ""
+	}
+	_, ids := resultIDs(resp)
+	if len(ids) != 1 {
+		s.err = fmt.Errorf("unexpected response %T", resp)
+		return ""
+	}
+	return ids[0]
+}
+
+// do keeps the error of an operation, if the first.
+func (s *seeder) do(_ any, err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// activate activates an object, the given number of days ago.
+func (s *seeder) activate(id string, daysAgo int) {
+	if s.err == nil {
+		_, s.err = s.client.Activate(id).Exec()
+	}
+	s.date(id, kmip.AttributeNameActivationDate, -daysAgo)
+}
+
+// age makes an object created the given number of days ago.
+func (s *seeder) age(id string, days int) {
+	s.date(id, kmip.AttributeNameInitialDate, -days)
+}
+
+// date sets a date attribute of an object, the given number of days from now.
+func (s *seeder) date(id string, name kmip.AttributeName, days int) {
+	if s.err == nil {
+		s.err = s.srv.SetAttribute(id, name, s.now.AddDate(0, 0, days).UTC().Truncate(time.Second))
+	}
+}
+
+func (s *seeder) add(id string, name kmip.AttributeName, value any) {
+	if s.err == nil {
+		_, s.err = s.client.AddAttribute(id, name, value).Exec()
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/phsym/kmip-explorer/memkms"
+)
+
+func TestSeedDemo(t *testing.T) {
+	srv := memkms.New()
+	ep, err := srv.Start(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ep.Close()
+	client, err := kmipclient.Dial(ep.Addr,
+		kmipclient.WithRootCAFile(ep.CAFile),
+		kmipclient.WithClientCertFiles(ep.CertFile, ep.KeyFile),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := seedDemo(srv, client, time.Now()); err != nil {
+		t.Fatalf("seedDemo: %v", err)
+	}
+
+	states := map[kmip.State]bool{}
+	for _, id := range srv.IDs() {
+		for _, attr := range srv.Attributes(id) {
+			if attr.AttributeName != kmip.AttributeNameState {
+				continue
+			}
+			st := attr.AttributeValue.(kmip.State)
+			states[st] = true
+			if st == kmip.StateDestroyed || st == kmip.StateDestroyedCompromised {
+				continue
+			}
+			if _, err := client.Get(id).Exec(); err != nil {
+				t.Errorf("Get %s: %v", id, err)
+			}
+		}
+	}
+	for _, st := range []kmip.State{kmip.StatePreActive, kmip.StateActive, kmip.StateDeactivated, kmip.StateCompromised, kmip.StateDestroyed} {
+		if !states[st] {
+			t.Errorf("no object in state %v", st)
+		}
+	}
+}
@@ -15,20 +15,31 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ovh/kmip-go/kmipclient"
 	explorer "github.com/phsym/kmip-explorer"
+	"github.com/phsym/kmip-explorer/internal/audit"
+	"github.com/phsym/kmip-explorer/internal/config"
+	"github.com/phsym/kmip-explorer/memkms"
+	"github.com/rivo/tview"
 	"golang.org/x/mod/semver"
 
 	"flag"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/google/uuid"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -43,15 +54,22 @@ var (
 )
 
 var (
-	addr       = flag.String("addr", os.Getenv("KMIP_ADDR"), "Address and port of the KMIP Server")
-	cert       = flag.String("cert", os.Getenv("KMIP_CERT"), "Path to the client certificate")
-	key        = flag.String("key", os.Getenv("KMIP_KEY"), "Path to the client private key")
-	ca         = flag.String("ca", os.Getenv("KMIP_CA"), "Server's CA (optional)")
-	noCcv      = flag.Bool("no-ccv", false, "Do not add client correlation value to requests")
+	configPath = flag.String("config", "", "Path to the configuration file (env KMIP_CONFIG, default ~/.config/kmip-explorer/config)")
+	addr       = flag.String("addr", "", "Address and port of the KMIP Server (env KMIP_ADDR)")
+	cert       = flag.String("cert", "", "Path to the client certificate (env KMIP_CERT)")
+	key        = flag.String("key", "", "Path to the client private key (env KMIP_KEY)")
+	ca         = flag.String("ca", "", "Server's CA (optional, env KMIP_CA)")
+	profile    = flag.String("profile", "", "Name of the server profile to connect to, from the configuration file (env KMIP_PROFILE)")
+	noCcv      = flag.Bool("no-ccv", false, "Do not add client correlation value to requests (env KMIP_NO_CCV)")
+	auditLog   = flag.String("audit-log", "", "Path to the audit log of the operations modifying objects (env KMIP_AUDIT_LOG, default ~/.config/kmip-explorer/audit.jsonl)")
+	readOnly   = flag.Bool("read-only", false, "Refuse every operation modifying objects, on any server (env KMIP_READ_ONLY)")
+	expiry     = flag.String("expiry-window", "", "How soon a Deactivation Date or certificate expiry must come to be listed in the Expiring tab, e.g. 30d or 2w (env KMIP_EXPIRY_WINDOW, default 30d)")
+	rotation   = flag.String("rotation-age", "", "Age past which an active key is listed as due for rotation in the Expiring tab, 0 to disable (env KMIP_ROTATION_AGE, default 365d)")
+	demo       = flag.Bool("demo", false, "Connect to an in-memory server seeded with sample objects, to try the explorer without a KMS (env KMIP_DEMO)")
 	vers       = flag.Bool("version", false, "Display version information")
-	tlsCiphers = flag.String("tls12-ciphers", "", "Coma separated list of tls 1.2 ciphers to allow. Defaults to a list of secured ciphers")
+	tlsCiphers = flag.String("tls12-ciphers", "", "Coma separated list of tls 1.2 ciphers to allow (env KMIP_TLS12_CIPHERS). Defaults to a list of secured ciphers")
 
-	skipUpdate = flag.Bool("no-check-update", false, "Do not check for update")
+	skipUpdate = flag.Bool("no-check-update", false, "Do not check for update (env KMIP_NO_CHECK_UPDATE)")
 )
 
 func main() {
@@ -62,53 +80,356 @@ func main() {
 		}
 	}
 	flag.Parse()
-	latestVersion := ""
-	if !*skipUpdate {
-		latestVersion = checkLatestVersion(version)
+
+	cfgPath := setting("config", *configPath, "KMIP_CONFIG", "")
+	var err error
+	if cfgPath == "" {
+		cfgPath, err = config.DefaultPath()
+	}
+	checkUpdate := func(cfg *config.Config) string {
+		if boolSetting("no-check-update", *skipUpdate, "KMIP_NO_CHECK_UPDATE", cfg != nil && cfg.CheckUpdate != nil && !*cfg.CheckUpdate) {
+			return ""
+		}
+		return checkLatestVersion(version)
 	}
 	if *vers {
+		// The configuration file only tells whether to check for a newer
+		// version: one which can't be read doesn't prevent printing this one.
+		var cfg *config.Config
+		if err == nil {
+			cfg, _ = config.Load(cfgPath)
+		}
 		fmt.Printf("Version: %s\nCommit: %s\nBuild Date: %s\nGo Version: %s\nOS: %s\nArch: %s\n", version, commit, date, runtime.Version(), runtime.GOOS, runtime.GOARCH)
-		if latestVersion != "" {
+		if latestVersion := checkUpdate(cfg); latestVersion != "" {
 			fmt.Println("New version available:", latestVersion)
 		}
 		return
 	}
-	if *addr == "" || *cert == "" || *key == "" {
-		fmt.Fprintln(os.Stderr, "Missing one of arguments --addr, --cert or --key")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+
+	// Subcommands are meant for scripts: they don't check for update.
+	args := flag.Args()
+	latestVersion := ""
+	if len(args) == 0 {
+		latestVersion = checkUpdate(cfg)
+	}
+
+	dialer := dialer{
+		noCCV:     boolSetting("no-ccv", *noCcv, "KMIP_NO_CCV", cfg.NoCCV != nil && *cfg.NoCCV),
+		ciphers:   cfg.TLS12Ciphers,
+		auditPath: setting("audit-log", *auditLog, "KMIP_AUDIT_LOG", cfg.AuditLog),
+	}
+	if dialer.auditPath == "" {
+		if dialer.auditPath, err = audit.DefaultPath(); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			os.Exit(1)
+		}
+	}
+	if c := setting("tls12-ciphers", *tlsCiphers, "KMIP_TLS12_CIPHERS", ""); c != "" {
+		dialer.ciphers = strings.Split(c, ",")
+	}
+
+	profileName := setting("profile", *profile, "KMIP_PROFILE", cfg.Profile)
+	profileNames := cfg.ProfileNames()
+	var conn connection
+	// stopDemo stops the server of the demo mode, if any.
+	stopDemo := func() {}
+	if boolSetting("demo", *demo, "KMIP_DEMO", false) {
+		// The demo server is thrown away on exit, along with its audit log:
+		// nothing is left behind, and no real server can be reached from it.
+		dir, err := os.MkdirTemp("", "kmip-explorer-demo-")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			os.Exit(1)
+		}
+		var ep *memkms.Endpoint
+		conn, ep, err = startDemo(dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			os.Exit(1)
+		}
+		stopDemo = func() {
+			ep.Close()
+			os.RemoveAll(dir)
+		}
+		conn.readOnly = readOnlySetting(cfg, nil)
+		dialer.auditPath = filepath.Join(dir, "audit.jsonl")
+		profileName, profileNames = "demo", nil
+	} else if profileName != "" {
+		p := cfg.FindProfile(profileName)
+		if p == nil {
+			fmt.Fprintf(os.Stderr, "ERROR: unknown profile %q in %s\n", profileName, cfgPath)
+			os.Exit(1)
+		}
+		// The settings of the profile replace those of the top of the
+		// configuration file, still overridden by the environment and flags.
+		conn = connection{
+			addr:     setting("addr", *addr, "KMIP_ADDR", p.Addr),
+			cert:     setting("cert", *cert, "KMIP_CERT", p.Cert),
+			key:      setting("key", *key, "KMIP_KEY", p.Key),
+			ca:       setting("ca", *ca, "KMIP_CA", p.CA),
+			readOnly: readOnlySetting(cfg, p),
+		}
+	} else {
+		conn = connection{
+			addr:     setting("addr", *addr, "KMIP_ADDR", cfg.Addr),
+			cert:     setting("cert", *cert, "KMIP_CERT", cfg.Cert),
+			key:      setting("key", *key, "KMIP_KEY", cfg.Key),
+			ca:       setting("ca", *ca, "KMIP_CA", cfg.CA),
+			readOnly: readOnlySetting(cfg, nil),
+		}
+	}
+	if conn.addr == "" || conn.cert == "" || conn.key == "" {
+		fmt.Fprintln(os.Stderr, "Missing one of arguments --addr, --cert or --key, or a --profile")
 		flag.PrintDefaults()
+		if len(args) > 0 {
+			os.Exit(2)
+		}
 		return
 	}
+	if len(args) > 0 {
+		code := runCLI(args, dialer, conn, cfg.Columns)
+		stopDemo()
+		os.Exit(code)
+	}
+	defer stopDemo()
+
+	if err := applyTheme(cfg.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %s\n", cfgPath, err)
+		os.Exit(1)
+	}
+	expiryWindow, err := durationSetting("expiry-window", *expiry, "KMIP_EXPIRY_WINDOW", cfg.ExpiryWindow, explorer.DefaultExpiryWindow)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+	rotationAge, err := durationSetting("rotation-age", *rotation, "KMIP_ROTATION_AGE", cfg.RotationAge, explorer.DefaultRotationAge)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+	keys, err := explorer.ParseKeyBindings(cfg.Keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: keys: %s\n", cfgPath, err)
+		os.Exit(1)
+	}
 
-	// tview.Styles.PrimitiveBackgroundColor = tcell.ColorNone
-	client := newClient()
-	exp := explorer.New(client, version, latestVersion)
+	// Record the exchanges with every server for the protocol inspector.
+	dialer.inspector = explorer.NewInspector(200)
+	client, err := dialer.dial(conn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+	exp := explorer.New(client, version, latestVersion,
+		explorer.WithColumns(cfg.Columns, func(cols []string) error {
+			return config.SaveColumns(cfgPath, cols)
+		}),
+		explorer.WithProfiles(profileNames, profileName, func(name string) (*kmipclient.Client, error) {
+			p := cfg.FindProfile(name)
+			return dialer.dial(profileConnection(p, readOnlySetting(cfg, p)))
+		}),
+		explorer.WithReadOnly(func(name string) bool {
+			return readOnlySetting(cfg, cfg.FindProfile(name))
+		}),
+		explorer.WithAuditLog(dialer.auditPath),
+		explorer.WithInspector(dialer.inspector),
+		explorer.WithKeyBindings(keys),
+		explorer.WithExpiry(expiryWindow, rotationAge),
+	)
 	if err := exp.Run(); err != nil {
+		stopDemo()
 		fmt.Fprintln(os.Stderr, "ERROR:", err)
 		os.Exit(1)
 	}
 }
 
-func newClient() *kmipclient.Client {
+// runCLI runs the subcommand given by args, connecting to the server once its
+// arguments are valid, and returns the exit status.
+func runCLI(args []string, d dialer, conn connection, columns []string) int {
+	c := &cli{
+		dial:    func() (*kmipclient.Client, error) { return d.dial(conn) },
+		columns: columns,
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}
+	err := runSubcommand(c, args)
+	if c.client != nil {
+		c.client.Close()
+	}
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, errFailed):
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "ERROR:", err)
+	return 1
+}
+
+// setting resolves a string setting: the value of the named flag if given on
+// the command line, else the env environment variable if set, else the value
+// from the configuration file.
+func setting(name, flagValue, env, cfgValue string) string {
+	if isFlagSet(name) {
+		return flagValue
+	}
+	if v, ok := os.LookupEnv(env); ok {
+		return v
+	}
+	return cfgValue
+}
+
+// boolSetting resolves a boolean setting the same way as setting. An
+// environment variable which isn't a boolean is ignored.
+func boolSetting(name string, flagValue bool, env string, cfgValue bool) bool {
+	if isFlagSet(name) {
+		return flagValue
+	}
+	if v, err := strconv.ParseBool(os.Getenv(env)); err == nil {
+		return v
+	}
+	return cfgValue
+}
+
+// durationSetting resolves a duration setting the same way as setting, see
+// config.ParseDuration; def applies when none is given.
+func durationSetting(name, flagValue, env, cfgValue string, def time.Duration) (time.Duration, error) {
+	s := setting(name, flagValue, env, cfgValue)
+	if s == "" {
+		return def, nil
+	}
+	d, err := config.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// readOnlySetting tells whether to refuse the operations modifying objects on
+// the server of profile p (nil without profile). The flag, the environment, the
+// profile and the top-level setting can each only make it stricter: a false
+// value never turns off read-only mode set by another, so that a variable left
+// set in a shell can't make a protected profile writable.
+func readOnlySetting(cfg *config.Config, p *config.Profile) bool {
+	env, _ := strconv.ParseBool(os.Getenv("KMIP_READ_ONLY"))
+	return *readOnly || env ||
+		p != nil && p.ReadOnly != nil && *p.ReadOnly ||
+		cfg.ReadOnly != nil && *cfg.ReadOnly
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// themeColors maps the [theme] settings to the colors of the UI.
+var themeColors = map[string]*tcell.Color{
+	"background":               &tview.Styles.PrimitiveBackgroundColor,
+	"contrast_background":      &tview.Styles.ContrastBackgroundColor,
+	"more_contrast_background": &tview.Styles.MoreContrastBackgroundColor,
+	"border":                   &tview.Styles.BorderColor,
+	"title":                    &tview.Styles.TitleColor,
+	"graphics":                 &tview.Styles.GraphicsColor,
+	"text":                     &tview.Styles.PrimaryTextColor,
+	"secondary_text":           &tview.Styles.SecondaryTextColor,
+	"tertiary_text":            &tview.Styles.TertiaryTextColor,
+	"inverse_text":             &tview.Styles.InverseTextColor,
+	"contrast_secondary_text":  &tview.Styles.ContrastSecondaryTextColor,
+}
+
+// applyTheme sets the colors of the UI from the [theme] settings, where a color
+// is a name (e.g. "darkblue"), "#rrggbb" or "default" for the terminal's.
+func applyTheme(theme map[string]string) error {
+	for name, value := range theme {
+		color, ok := themeColors[name]
+		if !ok {
+			return fmt.Errorf("theme: unknown color %q", name)
+		}
+		c := tcell.GetColor(value)
+		if c == tcell.ColorDefault && !strings.EqualFold(value, "default") {
+			return fmt.Errorf("theme: invalid %s color %q", name, value)
+		}
+		*color = c
+	}
+	return nil
+}
+
+// connection holds the settings to connect to a server.
+type connection struct {
+	addr, cert, key, ca string
+	// readOnly refuses the requests modifying objects.
+	readOnly bool
+}
+
+func profileConnection(p *config.Profile, readOnly bool) connection {
+	return connection{addr: p.Addr, cert: p.Cert, key: p.Key, ca: p.CA, readOnly: readOnly}
+}
+
+// dialer holds the client settings shared by every server.
+type dialer struct {
+	noCCV   bool
+	ciphers []string
+	// auditPath is the audit log the operations modifying objects are
+	// recorded in.
+	auditPath string
+	// inspector, if not nil, records the messages exchanged with the server.
+	inspector *explorer.Inspector
+}
+
+func (d dialer) dial(conn connection) (*kmipclient.Client, error) {
 	middlewares := []kmipclient.Middleware{}
-	if !*noCcv {
+	if conn.readOnly {
+		middlewares = append(middlewares, explorer.ReadOnlyMiddleware())
+	}
+	middlewares = append(middlewares, explorer.AuditMiddleware(d.auditPath, conn.addr, certSubject(conn.cert)))
+	if !d.noCCV {
 		middlewares = append(middlewares, kmipclient.CorrelationValueMiddleware(uuid.NewString))
 	}
-	ciphers := []string{}
-	if *tlsCiphers != "" {
-		ciphers = strings.Split(*tlsCiphers, ",")
+	if d.inspector != nil {
+		middlewares = append(middlewares, d.inspector.Middleware())
 	}
-	client, err := kmipclient.Dial(
-		*addr,
-		kmipclient.WithRootCAFile(*ca),
-		kmipclient.WithClientCertFiles(*cert, *key),
+	return kmipclient.Dial(
+		conn.addr,
+		kmipclient.WithRootCAFile(conn.ca),
+		kmipclient.WithClientCertFiles(conn.cert, conn.key),
 		kmipclient.WithMiddlewares(middlewares...),
-		kmipclient.WithTlsCipherSuiteNames(ciphers...),
+		kmipclient.WithTlsCipherSuiteNames(d.ciphers...),
 	)
+}
+
+// certSubject returns the subject of the certificate in the PEM file at path,
+// e.g. "CN=alice,O=Example", or an empty string if it can't be read.
+func certSubject(path string) string {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "ERROR:", err)
-		os.Exit(1)
+		return ""
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
 	}
-	return client
+	return cert.Subject.String()
 }
 
 const RELEASE_URL = "https://api.github.com/repos/phsym/kmip-explorer/releases/latest"
@@ -0,0 +1,44 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/phsym/kmip-explorer/internal/config"
+)
+
+func TestReadOnlySetting(t *testing.T) {
+	yes, no := true, false
+	prod := &config.Profile{Name: "prod", ReadOnly: &yes}
+	dev := &config.Profile{Name: "dev", ReadOnly: &no}
+
+	t.Setenv("KMIP_READ_ONLY", "false")
+	if !readOnlySetting(&config.Config{}, prod) {
+		t.Fatal("KMIP_READ_ONLY=false made a read-only profile writable")
+	}
+	if readOnlySetting(&config.Config{}, dev) || readOnlySetting(&config.Config{}, nil) {
+		t.Fatal("read-only without any setting asking for it")
+	}
+	// A profile can't turn off the top-level setting either.
+	if !readOnlySetting(&config.Config{ReadOnly: &yes}, dev) {
+		t.Fatal("read_only = false in a profile made it writable")
+	}
+
+	t.Setenv("KMIP_READ_ONLY", "true")
+	if !readOnlySetting(&config.Config{ReadOnly: &no}, dev) {
+		t.Fatal("KMIP_READ_ONLY=true ignored")
+	}
+}
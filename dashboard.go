@@ -0,0 +1,146 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/phsym/kmip-explorer/internal/locate"
+	"github.com/phsym/kmip-explorer/internal/widgets"
+)
+
+// Default settings of the Expiring tab, see WithExpiry.
+const (
+	DefaultExpiryWindow = 30 * 24 * time.Hour
+	DefaultRotationAge  = 365 * 24 * time.Hour
+)
+
+// scanObjects fetches the attributes of the active and pre-active objects, the
+// only ones the Expiring tab reports, along with the end of validity of the
+// certificates among them. progress is called after each object; the scan
+// stops, returning nil, once it returns false.
+func scanObjects(client *kmipclient.Client, progress func(done, total int) bool) ([]*widgets.ScannedObject, error) {
+	var ids []string
+	for _, state := range []kmip.State{kmip.StateActive, kmip.StatePreActive} {
+		found, err := locate.All(client, kmip.Attribute{AttributeName: kmip.AttributeNameState, AttributeValue: state})
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, found...)
+	}
+	objs := make([]*widgets.ScannedObject, len(ids))
+	for i, id := range ids {
+		obj := &widgets.ScannedObject{ID: id}
+		obj.Attributes, obj.Err = client.GetAttributes(id).Exec()
+		if obj.Err == nil && scannedType(obj) == kmip.ObjectTypeCertificate {
+			obj.NotAfter = certificateNotAfter(client, id)
+		}
+		objs[i] = obj
+		if !progress(i+1, len(ids)) {
+			return nil, nil
+		}
+	}
+	return objs, nil
+}
+
+// scannedType returns the object type of obj, zero if unknown.
+func scannedType(obj *widgets.ScannedObject) kmip.ObjectType {
+	for _, attr := range obj.Attributes.Attribute {
+		if ot, ok := attr.AttributeValue.(kmip.ObjectType); ok {
+			return ot
+		}
+	}
+	return 0
+}
+
+// certificateNotAfter returns the end of validity of the X.509 certificate id,
+// zero if it can't be read.
+func certificateNotAfter(client *kmipclient.Client, id string) time.Time {
+	resp, err := client.Get(id).Exec()
+	if err != nil {
+		return time.Time{}
+	}
+	obj, ok := resp.Object.(*kmip.Certificate)
+	if !ok {
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(obj.CertificateValue)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter
+}
+
+// showDashboard shows the Expiring tab in place of the object table, and scans
+// the objects in the background.
+func (ex *Explorer) showDashboard() {
+	ex.onDashboard = true
+	ex.contentLayout.ResizeItem(ex.table, 0, 0).
+		ResizeItem(ex.details, 0, attrPanelHidden).
+		ResizeItem(ex.dashboard, 0, 1)
+	ex.app.SetFocus(ex.dashboard)
+	ex.refreshDashboard()
+}
+
+// hideDashboard gives its place back to the object table, reporting whether the
+// dashboard was shown.
+func (ex *Explorer) hideDashboard() bool {
+	if !ex.onDashboard {
+		return false
+	}
+	ex.onDashboard = false
+	ex.dashboardScan.Add(1)
+	ex.contentLayout.ResizeItem(ex.dashboard, 0, 0).
+		ResizeItem(ex.table, 0, 2)
+	ex.rebuildAttributes(ex.table.GetSelection())
+	ex.app.SetFocus(ex.table)
+	return true
+}
+
+// refreshDashboard scans the objects again for the dashboard. A scan started
+// before, or on another server, is discarded, as is one finishing once the
+// dashboard is hidden.
+func (ex *Explorer) refreshDashboard() {
+	scan, client := ex.dashboardScan.Add(1), ex.client.Load()
+	ex.dashboard.SetLoading(0, 0)
+	current := func() bool {
+		return ex.dashboardScan.Load() == scan && ex.client.Load() == client
+	}
+	go func() {
+		objs, err := scanObjects(client, func(done, total int) bool {
+			if done%20 == 0 || done == total {
+				ex.app.QueueUpdateDraw(func() {
+					if current() {
+						ex.dashboard.SetLoading(done, total)
+					}
+				})
+			}
+			return current()
+		})
+		ex.app.QueueUpdateDraw(func() {
+			if !current() {
+				return
+			}
+			if err != nil {
+				ex.dashboard.SetError(err)
+				return
+			}
+			ex.dashboard.SetObjects(objs)
+		})
+	}()
+}
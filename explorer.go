@@ -32,15 +32,24 @@
 package explorer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/ovh/kmip-go"
 	"github.com/ovh/kmip-go/kmipclient"
 	"github.com/ovh/kmip-go/payloads"
 	"github.com/ovh/kmip-go/ttlv"
+	"github.com/phsym/kmip-explorer/internal/locate"
+	"github.com/phsym/kmip-explorer/internal/query"
 	"github.com/phsym/kmip-explorer/internal/widgets"
 	"github.com/phsym/kmip-explorer/internal/widgets/modals"
 	"github.com/rivo/tview"
@@ -61,6 +70,21 @@ const (
 	attrPanelExpanded = 6 // focused: the user dived into the attributes
 )
 
+// actionOperations maps the help key of each action to the operations it runs;
+// the action is available when the server supports any of them.
+var actionOperations = map[string][]kmip.Operation{
+	"<shift+c>": {kmip.OperationCreate, kmip.OperationCreateKeyPair},
+	"<shift+r>": {kmip.OperationRegister},
+	"<space>":   {kmip.OperationGet},
+	"<a>":       {kmip.OperationActivate},
+	"<r>":       {kmip.OperationRevoke},
+	"<ctrl+d>":  {kmip.OperationDestroy},
+	"<ctrl+t>":  {kmip.OperationRekey, kmip.OperationRekeyKeyPair},
+	"<e>":       {kmip.OperationAddAttribute, kmip.OperationModifyAttribute, kmip.OperationDeleteAttribute},
+	"<w>": {kmip.OperationEncrypt, kmip.OperationDecrypt, kmip.OperationSign, kmip.OperationSignatureVerify,
+		kmip.OperationMAC, kmip.OperationMACVerify},
+}
+
 // Explorer is the KMIP browser terminal application. Create one with [New] and
 // start it with [Explorer.Run]. An Explorer is single-use and not safe for
 // concurrent use by multiple goroutines.
@@ -68,8 +92,11 @@ type Explorer struct {
 	app        *tview.Application
 	search     *tview.InputField
 	attributes *tview.TextView
+	timeline   *widgets.Timeline
 	table      *widgets.MobTable
 	tabs       *widgets.MobTypeTabs
+	dashboard  *widgets.Dashboard
+	banner     *widgets.Banner
 
 	errorModal        *tview.Modal
 	confirmModal      *tview.Modal
@@ -78,14 +105,164 @@ type Explorer struct {
 	createWidget      *modals.CreateKey
 	registerWidget    *modals.Register
 	keyMaterialWidget *modals.KeyMaterial
+	columnsWidget     *modals.Columns
+	attributeEditor   *modals.AttributeEditor
+	cryptoWidget      *modals.Crypto
+	serverWidget      *modals.Server
+	profilesWidget    *modals.Profiles
+	exportWidget      *modals.Export
+	auditWidget       *modals.AuditLog
+	linksWidget       *modals.Links
+	lineageWidget     *modals.Lineage
+	progressWidget    *modals.Progress
 
 	pages *tview.Pages
+	// layout is the main page: banner, tabs, search bar, content and the
+	// inspector pane.
+	layout *tview.Flex
 
 	contentLayout *tview.Flex
+	// details is the side of the content showing the lifecycle timeline above
+	// the attributes panel.
+	details *tview.Flex
 
 	typeFilter kmip.ObjectType
+	// onDashboard is set while the Expiring tab shows the dashboard in place
+	// of the table, and dashboardScan counts its scans, to discard those
+	// outdated (see refreshDashboard).
+	onDashboard   bool
+	dashboardScan atomic.Int64
+	// query holds the Locate criteria parsed from a search bar query (see
+	// applySearch); nil lists every object of the current tab.
+	query []kmip.Attribute
+
+	// client is swapped when the user switches to another profile (see
+	// WithProfiles).
+	client atomic.Pointer[kmipclient.Client]
+	// caps are the capabilities the server reported with Query; unknown until
+	// it answers.
+	caps modals.Capabilities
+
+	// profiles are the names of the server profiles the user can switch to,
+	// profile the current one (see WithProfiles).
+	profiles []string
+	profile  string
+	connect  func(string) (*kmipclient.Client, error)
+	// ownsClient is set once the client comes from connect rather than from the
+	// caller, so that it must be closed when switching away from it.
+	ownsClient bool
+
+	// isReadOnly tells whether the server of a profile must not be modified,
+	// and readOnly whether the current one is (see WithReadOnly).
+	isReadOnly func(profile string) bool
+	readOnly   bool
+
+	// inspector records the exchanges shown in inspectorPane, if any (see
+	// WithInspector); errorExchange is the ID of the exchange which produced
+	// the error shown, zero if unknown.
+	inspector      *Inspector
+	inspectorPane  *widgets.Inspector
+	inspectorShown atomic.Bool
+	errorExchange  int
+
+	// back are the objects jumped from to linked ones, the last one on top,
+	// and forward those left by going back (see jump).
+	back, forward []string
+
+	// auditPath is the audit log shown with <shift+l>; empty if none (see
+	// WithAuditLog).
+	auditPath string
+
+	// keys rebinds actions to other keys; nil keeps the defaults.
+	keys *KeyBindings
 
-	client *kmipclient.Client
+	// onColumnsChange is notified when the user picks other table columns (see
+	// WithColumns).
+	onColumnsChange func([]string) error
+}
+
+// Option customizes an [Explorer] built with [New].
+type Option func(*Explorer)
+
+// WithColumns sets the object table columns, by name and in display order. The
+// built-in columns are those of the default layout (ID, Type, Name, Algorithm,
+// Size, State, Age) and "<type> Link" columns showing the identifier of a linked
+// object (e.g. "Public Key Link"); any other name shows the attribute of that
+// name, custom "x-" attributes included. An empty list keeps the default layout.
+//
+// onChange, if not nil, is called with the new columns when the user changes
+// them from the UI, typically to save them; an error it returns is shown to
+// the user.
+func WithColumns(columns []string, onChange func([]string) error) Option {
+	return func(ex *Explorer) {
+		ex.table.SetColumns(columns)
+		ex.onColumnsChange = onChange
+	}
+}
+
+// WithKeyBindings binds actions to other keys than their default one, see
+// [ParseKeyBindings]. The help shows the new keys.
+func WithKeyBindings(kb *KeyBindings) Option {
+	return func(ex *Explorer) {
+		ex.keys = kb
+		for key, newKey := range kb.help {
+			ex.banner.SetActionKey(key, newKey)
+		}
+	}
+}
+
+// WithProfiles lets the user switch to another server profile with <p>, without
+// restarting. names are the profiles offered, current the profile of the client
+// given to [New] (empty if none), and connect dials the server of a profile.
+// The Explorer closes the clients returned by connect once it switches away
+// from them.
+func WithProfiles(names []string, current string, connect func(name string) (*kmipclient.Client, error)) Option {
+	return func(ex *Explorer) {
+		ex.profiles, ex.profile, ex.connect = names, current, connect
+		ex.banner.SetProfile(current)
+		ex.banner.SetActionEnabled("<p>", len(names) > 0)
+	}
+}
+
+// WithReadOnly removes the actions which modify objects (create, register,
+// activate, revoke, destroy, rekey and attribute edition) from the UI when
+// isReadOnly reports so for the current profile, called with an empty name
+// without profiles (see WithProfiles). It is checked again when the user
+// switches to another profile.
+//
+// It only hides the actions: to guard against any modification, the client
+// should also use [ReadOnlyMiddleware].
+func WithReadOnly(isReadOnly func(profile string) bool) Option {
+	return func(ex *Explorer) {
+		ex.isReadOnly = isReadOnly
+	}
+}
+
+// WithAuditLog lets the user browse the audit log at path with <shift+l>, see
+// [AuditMiddleware].
+func WithAuditLog(path string) Option {
+	return func(ex *Explorer) {
+		ex.auditPath = path
+		ex.banner.SetActionEnabled("<shift+l>", path != "")
+	}
+}
+
+// WithExpiry sets what the Expiring tab reports: the objects whose Deactivation
+// Date or certificate expiry comes within window, and the active keys older
+// than rotationAge from their Initial Date, zero to leave them out. The
+// defaults are DefaultExpiryWindow and DefaultRotationAge.
+func WithExpiry(window, rotationAge time.Duration) Option {
+	return func(ex *Explorer) {
+		ex.dashboard.SetPolicy(window, rotationAge)
+	}
+}
+
+// WithScreen draws the UI on screen instead of the terminal, e.g. a
+// [tcell.SimulationScreen] in tests (see the explorertest package).
+func WithScreen(screen tcell.Screen) Option {
+	return func(ex *Explorer) {
+		ex.app.SetScreen(screen)
+	}
 }
 
 // New builds an Explorer that operates against the given KMIP client. The
@@ -95,15 +272,15 @@ type Explorer struct {
 // string to hide the version line entirely. latestVersion is the newest
 // version available upstream, used to show an update hint next to the version;
 // pass an empty string to disable the hint (it is also suppressed when version
-// is empty). The returned Explorer is started with [Explorer.Run].
-func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
-	ex := &Explorer{
-		client: client,
-	}
+// is empty). Options are applied last. The returned Explorer is started with
+// [Explorer.Run].
+func New(client *kmipclient.Client, version, latestVersion string, opts ...Option) *Explorer {
+	ex := &Explorer{}
+	ex.client.Store(client)
 	ex.app = tview.NewApplication()
 	// ex.app.EnableMouse(true)
 
-	ex.search = tview.NewInputField().SetPlaceholder("Input").SetLabel("> ").
+	ex.search = tview.NewInputField().SetPlaceholder(`Filter by ID, name, algorithm or state, or query the server: state=active alg=AES len=256 name="prod"`).SetLabel("> ").
 		SetLabelColor(tcell.ColorDefault).
 		SetFieldBackgroundColor(tcell.ColorNone).
 		SetPlaceholderStyle(tcell.StyleDefault.
@@ -111,18 +288,48 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			Foreground(tcell.ColorDarkGray),
 		)
 	ex.search.SetBorder(true).SetTitle("Search").SetTitleAlign(tview.AlignLeft)
+	ex.search.SetChangedFunc(func(text string) {
+		// A query only runs on Enter (it is a server round-trip), so its text
+		// must not be used as a plain filter meanwhile.
+		if query.IsQuery(text) {
+			ex.search.SetTitle("Query")
+			ex.table.SetFilter("")
+			return
+		}
+		ex.search.SetTitle("Search")
+		ex.table.SetFilter(text)
+	})
 
 	ex.attributes = tview.NewTextView().SetDynamicColors(true)
 	ex.attributes.SetBorder(true).SetTitle("Attributes")
+	ex.attributes.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'e' {
+			ex.editAttributes()
+			return nil
+		}
+		if event.Rune() == 'f' {
+			if obj := ex.table.GetSelection(); obj != nil {
+				ex.showLinks(obj.UniqueIdentifier)
+			}
+			return nil
+		}
+		if event.Rune() == 'H' {
+			if obj := ex.table.GetSelection(); obj != nil {
+				ex.showLineage(obj.UniqueIdentifier)
+			}
+			return nil
+		}
+		return event
+	})
 
 	loader := func(id string) (*payloads.GetAttributesResponsePayload, error) {
-		return ex.client.GetAttributes(id).Exec()
+		return ex.client.Load().GetAttributes(id).Exec()
 	}
 	ex.table = widgets.NewMobTable(loader, ex.app).
 		OnSelected(func(garp *payloads.GetAttributesResponsePayload) {
 			if garp != nil {
 				ex.app.SetFocus(ex.attributes)
-				ex.contentLayout.ResizeItem(ex.attributes, 0, attrPanelExpanded)
+				ex.contentLayout.ResizeItem(ex.details, 0, attrPanelExpanded)
 			}
 		}).
 		OnSelectionChanged(func(garp *payloads.GetAttributesResponsePayload) {
@@ -141,13 +348,66 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			return nil
 		}
 		if event.Rune() == 'C' {
-			ex.pages.ShowPage("create")
-			ex.app.SetFocus(ex.createWidget)
+			if ex.can("<shift+c>") {
+				ex.pages.ShowPage("create")
+				ex.app.SetFocus(ex.createWidget)
+			}
 			return nil
 		}
 		if event.Rune() == 'R' {
-			ex.pages.ShowPage("register")
-			ex.app.SetFocus(ex.registerWidget)
+			if ex.can("<shift+r>") {
+				ex.pages.ShowPage("register")
+				ex.app.SetFocus(ex.registerWidget)
+			}
+			return nil
+		}
+		if event.Rune() == 'p' {
+			if len(ex.profiles) > 0 {
+				ex.profilesWidget.SetProfiles(ex.profiles, ex.profile)
+				ex.pages.ShowPage("profiles")
+				ex.app.SetFocus(ex.profilesWidget)
+			}
+			return nil
+		}
+		if event.Rune() == 'i' {
+			ex.pages.ShowPage("server")
+			ex.app.SetFocus(ex.serverWidget)
+			return nil
+		}
+		if event.Rune() == 'c' {
+			ex.showColumns()
+			return nil
+		}
+		if event.Rune() == 'x' {
+			ex.pages.ShowPage("export")
+			ex.app.SetFocus(ex.exportWidget)
+			return nil
+		}
+		if event.Rune() == 'I' {
+			if ex.inspector != nil {
+				ex.showInspector(0)
+			}
+			return nil
+		}
+		if event.Rune() == 'L' {
+			if ex.auditPath != "" {
+				ex.auditWidget.Load(ex.auditPath)
+				ex.pages.ShowPage("audit")
+				ex.app.SetFocus(ex.auditWidget)
+			}
+			return nil
+		}
+
+		if event.Rune() == '[' {
+			ex.goBack()
+			return nil
+		}
+		if event.Rune() == ']' {
+			ex.goForward()
+			return nil
+		}
+
+		if marked := ex.table.Marked(); len(marked) > 0 && ex.bulkAction(event, marked) {
 			return nil
 		}
 
@@ -155,13 +415,32 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 		if obj == nil {
 			return event
 		}
-		if event.Rune() == 'r' {
+		if event.Rune() == 'f' {
+			ex.showLinks(obj.UniqueIdentifier)
+			return nil
+		}
+		if event.Rune() == 'H' {
+			ex.showLineage(obj.UniqueIdentifier)
+			return nil
+		}
+		if key := actionKey(event); key != "" && !ex.can(key) {
+			return nil
+		}
+		if event.Rune() == 'e' {
+			ex.editAttributes()
+			return nil
+		} else if event.Rune() == 'w' {
+			ex.cryptoWidget.SetObject(obj)
+			ex.pages.ShowPage("crypto")
+			ex.app.SetFocus(ex.cryptoWidget)
+			return nil
+		} else if event.Rune() == 'r' {
 			ex.revokeModal.OnDone(func(f func(*kmipclient.Client, string) (*payloads.RevokeResponsePayload, error)) {
 				ex.pages.HidePage("revoke")
 				ex.app.SetFocus(ex.table)
 				ex.askConfirm("Confirm Revoke", fmt.Sprintf("Revoke object %s ?", obj.UniqueIdentifier), func() {
 					go func() {
-						resp, err := f(client, obj.UniqueIdentifier)
+						resp, err := f(ex.client.Load(), obj.UniqueIdentifier)
 						if err != nil {
 							ex.setError(err)
 							return
@@ -187,12 +466,18 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 				ex.app.SetFocus(ex.table)
 				ex.askConfirm("Confirm Rekeying", fmt.Sprintf("Rekey object %s ?", obj.UniqueIdentifier), func() {
 					go func() {
-						_, err := f(client, obj.UniqueIdentifier)
+						resp, err := f(ex.client.Load(), obj.UniqueIdentifier)
 						if err != nil {
 							ex.setError(err)
 							return
 						}
 						ex.refresh(false)
+						// Show the new key taking over from the old one.
+						if id := rekeyedID(resp); id != "" {
+							ex.app.QueueUpdateDraw(func() {
+								ex.showLineage(id)
+							})
+						}
 					}()
 				})
 			})
@@ -201,7 +486,7 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			return nil
 		} else if event.Rune() == ' ' {
 			go func() {
-				resp, err := ex.client.Get(obj.UniqueIdentifier).Exec()
+				resp, err := ex.client.Load().Get(obj.UniqueIdentifier).Exec()
 				if err != nil {
 					ex.setError(err)
 					return
@@ -217,15 +502,52 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 		return event
 	})
 
+	ex.timeline = widgets.NewTimeline()
+	ex.details = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(ex.timeline, 0, 0, false).
+		AddItem(ex.attributes, 0, 1, false)
+	ex.dashboard = widgets.NewDashboard(DefaultExpiryWindow, DefaultRotationAge).
+		OnSelected(func(id string) {
+			// Back to the tab the table still lists, without reloading it.
+			ex.tabs.SelectType(ex.typeFilter)
+			ex.jump(id)
+		})
+	ex.dashboard.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			ex.tabs.Next()
+			return nil
+		}
+		if event.Key() == tcell.KeyBacktab {
+			ex.tabs.Prev()
+			return nil
+		}
+		return event
+	})
+	// The dashboard takes the place of the table and the attributes on the
+	// Expiring tab (see showDashboard).
 	content := tview.NewFlex().
 		AddItem(ex.table, 0, 2, false).
-		AddItem(ex.attributes, 0, attrPanelHidden, false)
+		AddItem(ex.details, 0, attrPanelHidden, false).
+		AddItem(ex.dashboard, 0, 0, false)
 
 	banner := widgets.NewBanner(version, latestVersion)
 	banner.SetClientInfo(client)
+	banner.SetActionEnabled("<p>", false)
+	banner.SetActionEnabled("<shift+l>", false)
+	banner.SetActionEnabled("<shift+i>", false)
+	ex.banner = banner
 
 	ex.tabs = widgets.NewMobTypeTabs().
 		OnChange(func(ot kmip.ObjectType, s string) {
+			if ex.tabs.Dashboard() {
+				ex.showDashboard()
+				return
+			}
+			// Coming back from the dashboard, the table still lists the
+			// objects of the tab it was left on.
+			if ex.hideDashboard() && ot == ex.typeFilter {
+				return
+			}
 			ex.table.Clear(true)
 			ex.typeFilter = ot
 			ex.table.SetTitle(s)
@@ -233,7 +555,7 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 		})
 	ex.table.SetTitle(ex.tabs.Current())
 
-	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+	ex.layout = tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(banner, banner.Height(), 0, false).
 		AddItem(ex.tabs, 1, 0, false).
 		AddItem(ex.search, 0, 0, false).
@@ -248,7 +570,11 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 	ex.errorModal.AddButtons([]string{"OK"})
 	ex.errorModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 		ex.pages.HidePage("error")
-		ex.app.SetFocus(ex.table)
+		if buttonLabel == "Inspect" {
+			ex.showInspector(ex.errorExchange)
+			return
+		}
+		ex.focusFront()
 	})
 
 	ex.confirmModal = tview.NewModal().AddButtons([]string{"Yes", "No"})
@@ -274,7 +600,7 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			ex.pages.HidePage("create")
 			ex.app.SetFocus(ex.table)
 			go func() {
-				_, err := f(ex.client)
+				_, err := f(ex.client.Load())
 				if err != nil {
 					ex.setError(err)
 					return
@@ -291,7 +617,7 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			ex.pages.HidePage("register")
 			ex.app.SetFocus(ex.table)
 			go func() {
-				_, err := f(ex.client)
+				_, err := f(ex.client.Load())
 				if err != nil {
 					ex.setError(err)
 					return
@@ -306,25 +632,162 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			ex.app.SetFocus(ex.table)
 		})
 
+	ex.columnsWidget = modals.NewColumns().
+		OnCancel(func() {
+			ex.pages.HidePage("columns")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(cols []string) {
+			ex.pages.HidePage("columns")
+			ex.app.SetFocus(ex.table)
+			ex.table.SetColumns(cols)
+			if ex.onColumnsChange != nil {
+				go func() {
+					if err := ex.onColumnsChange(cols); err != nil {
+						ex.setError(fmt.Errorf("Failed to save columns: %w", err))
+					}
+				}()
+			}
+		})
+
+	ex.attributeEditor = modals.NewAttributeEditor().
+		OnCancel(func() {
+			ex.pages.HidePage("attribute-editor")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(change modals.AttributeChange) {
+			id := ex.attributeEditor.ObjectID()
+			apply := func() {
+				go func() {
+					if err := change.Exec(ex.client.Load(), id); err != nil {
+						ex.setError(fmt.Errorf("%s: %w", change.Description, err))
+					}
+					ex.update(id)
+				}()
+			}
+			if change.Delete {
+				ex.askConfirm("Confirm Delete", tview.Escape(fmt.Sprintf("%s from object %s ?", change.Description, id)), apply)
+				return
+			}
+			apply()
+		})
+
+	ex.cryptoWidget = modals.NewCrypto().
+		OnCancel(func() {
+			ex.pages.HidePage("crypto")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(f func(*kmipclient.Client) (kmip.OperationPayload, error)) {
+			go func() {
+				resp, err := f(ex.client.Load())
+				ex.app.QueueUpdateDraw(func() {
+					ex.cryptoWidget.SetResult(resp, err)
+				})
+			}()
+		})
+
+	ex.serverWidget = modals.NewServer().
+		OnDone(func() {
+			ex.pages.HidePage("server")
+			ex.app.SetFocus(ex.table)
+		})
+
+	ex.profilesWidget = modals.NewProfiles().
+		OnCancel(func() {
+			ex.pages.HidePage("profiles")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(name string) {
+			ex.pages.HidePage("profiles")
+			ex.app.SetFocus(ex.table)
+			go ex.switchProfile(name)
+		})
+
+	ex.exportWidget = modals.NewExport(widgets.ExportFormats).
+		OnCancel(func() {
+			ex.pages.HidePage("export")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(format, path string) {
+			ex.pages.HidePage("export")
+			ex.export(format, path)
+		})
+
+	ex.auditWidget = modals.NewAuditLog().
+		OnCancel(func() {
+			ex.pages.HidePage("audit")
+			ex.app.SetFocus(ex.table)
+		})
+
+	ex.linksWidget = modals.NewLinks().
+		OnCancel(func() {
+			ex.pages.HidePage("links")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(id string) {
+			ex.pages.HidePage("links")
+			ex.jump(id)
+		})
+
+	ex.lineageWidget = modals.NewLineage().
+		OnCancel(func() {
+			ex.pages.HidePage("lineage")
+			ex.app.SetFocus(ex.table)
+		}).
+		OnDone(func(id string) {
+			ex.pages.HidePage("lineage")
+			ex.jump(id)
+		})
+
+	ex.progressWidget = modals.NewProgress().
+		OnDone(func() {
+			ex.pages.HidePage("progress")
+			ex.app.SetFocus(ex.table)
+		})
+
 	ex.pages = tview.NewPages().
-		AddPage("main", layout, true, true).
+		AddPage("main", ex.layout, true, true).
+		// The attribute editor comes right after the main page so that the
+		// error and confirmation modals it opens are drawn on top of it.
+		AddPage("attribute-editor", ex.attributeEditor, true, false).
 		AddPage("error", ex.errorModal, true, false).
 		AddPage("confirm", ex.confirmModal, true, false).
 		AddPage("register", ex.registerWidget, true, false).
 		AddPage("revoke", ex.revokeModal, true, false).
 		AddPage("rekey", ex.rekeyModal, true, false).
 		AddPage("create", ex.createWidget, true, false).
-		AddPage("key-material", ex.keyMaterialWidget, true, false)
+		AddPage("key-material", ex.keyMaterialWidget, true, false).
+		AddPage("columns", ex.columnsWidget, true, false).
+		AddPage("crypto", ex.cryptoWidget, true, false).
+		AddPage("server", ex.serverWidget, true, false).
+		AddPage("profiles", ex.profilesWidget, true, false).
+		AddPage("export", ex.exportWidget, true, false).
+		AddPage("audit", ex.auditWidget, true, false).
+		AddPage("links", ex.linksWidget, true, false).
+		AddPage("lineage", ex.lineageWidget, true, false).
+		AddPage("progress", ex.progressWidget, true, false)
 
 	ex.app.SetRoot(ex.pages, true).SetFocus(ex.table).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Rune() == 'q' && !ex.search.HasFocus() && !ex.revokeModal.HasFocus() && !ex.createWidget.HasFocus() && !ex.registerWidget.HasFocus() && !ex.rekeyModal.HasFocus() {
+		// Rebound keys only apply to the object list: elsewhere, keys are
+		// typed in or drive a modal.
+		if ex.keys != nil && (ex.table.HasFocus() || ex.attributes.HasFocus()) {
+			if event = ex.keys.translate(event); event == nil {
+				return nil
+			}
+		}
+		// Modals and the search bar take every key typed, 'q' and '/' included.
+		onMain := !ex.search.HasFocus()
+		if name, _ := ex.pages.GetFrontPage(); name != "main" {
+			onMain = false
+		}
+		if event.Rune() == 'q' && onMain {
 			//TODO: Move to table input handler ?
 			ex.app.Stop()
 			return nil
 		}
-		if event.Rune() == '/' && !ex.search.HasFocus() && !ex.revokeModal.HasFocus() && !ex.createWidget.HasFocus() && !ex.registerWidget.HasFocus() && !ex.rekeyModal.HasFocus() {
+		if event.Rune() == '/' && onMain && !ex.onDashboard {
 			//TODO: Move to table input handler ?
-			layout.ResizeItem(ex.search, 3, 0)
+			ex.layout.ResizeItem(ex.search, 3, 0)
 			ex.app.SetFocus(ex.search)
 			return nil
 		}
@@ -332,26 +795,32 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 			if ex.search.HasFocus() {
 				//TODO: Move this handler to the searchbar input handler
 				ex.search.SetText("")
-				layout.ResizeItem(ex.search, 0, 0)
+				ex.applySearch()
+				ex.layout.ResizeItem(ex.search, 0, 0)
 				ex.app.SetFocus(ex.table)
 				return nil
 			} else if ex.attributes.HasFocus() {
 				//TODO: Move this handler to the attributes input handler
 				ex.attributes.ScrollToBeginning()
 				ex.app.SetFocus(ex.table)
-				ex.contentLayout.ResizeItem(ex.attributes, 0, attrPanelPreview)
+				ex.contentLayout.ResizeItem(ex.details, 0, attrPanelPreview)
 				return nil
 			}
 		}
 		if event.Key() == tcell.KeyEnter {
 			if ex.search.HasFocus() {
 				//TODO: Move this handler to the searchbar input handler
+				ex.applySearch()
 				ex.app.SetFocus(ex.table)
 				return nil
 			}
 		}
 		if event.Key() == tcell.KeyCtrlR {
 			//TODO: Move to table input handler ?
+			if ex.onDashboard {
+				ex.refreshDashboard()
+				return nil
+			}
 			go ex.refresh(false)
 			return nil
 		}
@@ -362,11 +831,120 @@ func New(client *kmipclient.Client, version, latestVersion string) *Explorer {
 		return event
 	})
 
+	for _, opt := range opts {
+		opt(ex)
+	}
+	ex.updateReadOnly()
 	return ex
 }
 
 func (ex *Explorer) init() {
 	go ex.refresh(true)
+	go ex.queryServer()
+}
+
+// queryServer asks the server what it supports, for the Server page, and
+// restricts the actions to those. A server that doesn't implement Query keeps
+// every action.
+func (ex *Explorer) queryServer() {
+	client := ex.client.Load()
+	resp, err := client.Request(context.Background(), &payloads.QueryRequestPayload{
+		QueryFunction: []kmip.QueryFunction{
+			kmip.QueryFunctionQueryOperations,
+			kmip.QueryFunctionQueryObjects,
+			kmip.QueryFunctionQueryServerInformation,
+			kmip.QueryFunctionQueryApplicationNamespaces,
+			kmip.QueryFunctionQueryExtensionList,
+		},
+	})
+	ex.app.QueueUpdateDraw(func() {
+		if ex.client.Load() != client {
+			return
+		}
+		ex.serverWidget.SetQuery(resp, err)
+		if q, ok := resp.(*payloads.QueryResponsePayload); ok && err == nil {
+			ex.setCapabilities(modals.QueryCapabilities(q))
+		}
+	})
+}
+
+// switchProfile connects to the server of the named profile and, once
+// connected, shows it in place of the current one. The current server is kept
+// if the connection fails.
+func (ex *Explorer) switchProfile(name string) {
+	client, err := ex.connect(name)
+	if err != nil {
+		ex.setError(fmt.Errorf("Failed to connect to %s: %w", name, err))
+		return
+	}
+	ex.app.QueueUpdateDraw(func() {
+		old := ex.client.Swap(client)
+		if ex.ownsClient {
+			go old.Close()
+		}
+		ex.ownsClient = true
+		ex.profile = name
+		// The objects jumped between are those of the previous server.
+		ex.back, ex.forward = nil, nil
+		ex.banner.SetClientInfo(client)
+		ex.banner.SetProfile(name)
+		ex.updateReadOnly()
+		ex.serverWidget.SetLoading()
+		ex.setCapabilities(modals.Capabilities{})
+		ex.table.Clear(true)
+		go ex.refresh(true)
+		go ex.queryServer()
+	})
+}
+
+// setCapabilities hides or disables the actions the server doesn't support.
+func (ex *Explorer) setCapabilities(caps modals.Capabilities) {
+	ex.caps = caps
+	for key, ops := range actionOperations {
+		ex.banner.SetActionEnabled(key, caps.Supports(ops...))
+	}
+	ex.createWidget.SetCapabilities(caps)
+	ex.registerWidget.SetCapabilities(caps)
+	ex.rekeyModal.SetCapabilities(caps)
+	ex.attributeEditor.SetCapabilities(caps)
+	ex.cryptoWidget.SetCapabilities(caps)
+}
+
+// updateReadOnly removes the actions which modify objects from the help when
+// the current profile is read-only, or puts them back.
+func (ex *Explorer) updateReadOnly() {
+	ex.readOnly = ex.isReadOnly != nil && ex.isReadOnly(ex.profile)
+	for _, key := range mutatingActions {
+		ex.banner.SetActionHidden(key, ex.readOnly)
+	}
+	ex.banner.SetReadOnly(ex.readOnly)
+}
+
+// can reports whether the action bound to the given help key is supported by
+// the server, and allowed in read-only mode.
+func (ex *Explorer) can(key string) bool {
+	if ex.readOnly && slices.Contains(mutatingActions, key) {
+		return false
+	}
+	return ex.caps.Supports(actionOperations[key]...)
+}
+
+// actionKey returns the help key of the object action bound to event, if any.
+func actionKey(event *tcell.EventKey) string {
+	switch event.Key() {
+	case tcell.KeyCtrlD:
+		return "<ctrl+d>"
+	case tcell.KeyCtrlT:
+		return "<ctrl+t>"
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case ' ':
+			return "<space>"
+		case 'a', 'r', 'e', 'w':
+			return "<" + string(event.Rune()) + ">"
+		}
+	}
+	return ""
 }
 
 // Run takes over the terminal, draws the UI and blocks until the user quits
@@ -379,9 +957,27 @@ func (ex *Explorer) Run() error {
 	return ex.app.Run()
 }
 
+// Stop makes [Explorer.Run] return, as if the user quit.
+func (ex *Explorer) Stop() {
+	ex.app.Stop()
+}
+
 func (ex *Explorer) setError(err error) {
 	ex.app.QueueUpdateDraw(func() {
 		ex.errorModal.SetText(err.Error())
+		// Offer to look at the exchange with the server which failed.
+		ex.errorExchange = 0
+		if ex.inspector != nil {
+			if e := ex.inspector.recorder.Find(err); e != nil {
+				ex.errorExchange = e.ID
+			}
+		}
+		ex.errorModal.ClearButtons()
+		if ex.errorExchange != 0 {
+			ex.errorModal.AddButtons([]string{"OK", "Inspect"})
+		} else {
+			ex.errorModal.AddButtons([]string{"OK"})
+		}
 		ex.pages.ShowPage("error")
 		ex.app.SetFocus(ex.errorModal)
 	})
@@ -389,8 +985,11 @@ func (ex *Explorer) setError(err error) {
 
 func (ex *Explorer) rebuildAttributes(garp *payloads.GetAttributesResponsePayload) {
 	ex.attributes.Clear()
-	if garp == nil {
-		ex.contentLayout.ResizeItem(ex.attributes, 0, attrPanelHidden)
+	ex.timeline.SetObject(garp)
+	ex.details.ResizeItem(ex.timeline, ex.timeline.Height(), 0)
+	// The dashboard takes the place of the details on the Expiring tab.
+	if garp == nil || ex.onDashboard {
+		ex.contentLayout.ResizeItem(ex.details, 0, attrPanelHidden)
 		return
 	}
 	// When the panel is focused the user has expanded it, so a content refresh
@@ -400,7 +999,7 @@ func (ex *Explorer) rebuildAttributes(garp *payloads.GetAttributesResponsePayloa
 	if ex.attributes.HasFocus() {
 		openSize = attrPanelExpanded
 	}
-	ex.contentLayout.ResizeItem(ex.attributes, 0, openSize)
+	ex.contentLayout.ResizeItem(ex.details, 0, openSize)
 	if len(garp.Attribute) == 0 {
 		// A stub for a selected row whose details aren't cached: either the fetch
 		// is still in flight, or it failed — in which case show why rather than a
@@ -429,36 +1028,320 @@ func (ex *Explorer) rebuildAttributes(garp *payloads.GetAttributesResponsePayloa
 	ex.attributes.SetText(strBld.String())
 }
 
+// applySearch runs the search bar's text as a Locate query when it is one, or
+// drops a previously applied query when it isn't (plain text is already applied
+// as a filter as it is typed). A query that doesn't parse is reported and leaves
+// the current listing untouched.
+func (ex *Explorer) applySearch() {
+	text := ex.search.GetText()
+	if !query.IsQuery(text) {
+		if ex.query != nil {
+			ex.query = nil
+			go ex.refresh(true)
+		}
+		return
+	}
+	attrs, err := query.Parse(text)
+	if err != nil {
+		ex.setError(fmt.Errorf("Invalid query: %w", err))
+		return
+	}
+	ex.query = attrs
+	go ex.refresh(true)
+}
+
 func (ex *Explorer) refresh(resetSelect bool) {
-	req := ex.client.Locate()
-	if ex.typeFilter != 0 {
-		req = req.WithObjectType(ex.typeFilter)
+	// Capture the criteria now so that every page of this listing uses the same
+	// ones, even if the tab or the query changes while it is being scrolled.
+	// The client too, so that a listing started before switching to another
+	// server doesn't replace the new one.
+	typeFilter, criteria, client := ex.typeFilter, ex.query, ex.client.Load()
+	paged := locate.Paged(client)
+	page := func(offset int) ([]string, int, error) {
+		req := client.Locate()
+		if typeFilter != 0 {
+			req = req.WithObjectType(typeFilter)
+		}
+		if len(criteria) > 0 {
+			req = req.WithAttributes(criteria...)
+		}
+		if paged {
+			//nolint: gosec // offsets are bounded by the number of objects the server returned
+			req = req.WithMaxItems(locate.PageSize).WithOffset(int32(offset))
+		}
+		resp, err := req.Exec()
+		if err != nil {
+			return nil, 0, err
+		}
+		total := -1
+		if resp.LocatedItems != nil {
+			total = int(*resp.LocatedItems)
+		}
+		return resp.UniqueIdentifier, total, nil
+	}
+	ids, total, err := page(0)
+	if ex.client.Load() != client {
+		return
 	}
-	resp, err := req.Exec()
 	if err != nil {
 		ex.setError(err)
 		return
 	}
 	ex.app.QueueUpdateDraw(func() {
+		if ex.client.Load() != client {
+			return
+		}
 		if resetSelect {
 			ex.table.ScrollToBeginning()
 			ex.table.Select(0, 0)
 		}
-		ex.table.SetIDs(resp.UniqueIdentifier)
+		if paged {
+			ex.table.SetPage(ids, total, page)
+		} else {
+			ex.table.SetIDs(ids)
+		}
 	})
 }
 
 func (ex *Explorer) update(id string) {
-	attrs, err := ex.client.GetAttributes(id).Exec()
+	attrs, err := ex.client.Load().GetAttributes(id).Exec()
 	if err != nil {
 		ex.setError(err)
 		return
 	}
 	ex.app.QueueUpdateDraw(func() {
 		ex.table.UpdateObject(attrs)
+		if ex.attributeEditor.ObjectID() == id {
+			ex.attributeEditor.SetObject(attrs)
+		}
 	})
 }
 
+// focusFront gives the focus back to the page on top once a modal is hidden:
+// the table, or the dashboard in its place, if it's the main page.
+func (ex *Explorer) focusFront() {
+	if name, page := ex.pages.GetFrontPage(); name != "main" {
+		ex.app.SetFocus(page)
+		return
+	}
+	if ex.onDashboard {
+		ex.app.SetFocus(ex.dashboard)
+		return
+	}
+	ex.app.SetFocus(ex.table)
+}
+
+// editAttributes opens the attribute editor on the selected object, once its
+// attributes are loaded.
+func (ex *Explorer) editAttributes() {
+	obj := ex.table.GetSelection()
+	if obj == nil || len(obj.Attribute) == 0 || !ex.can("<e>") {
+		return
+	}
+	ex.attributeEditor.SetObject(obj)
+	ex.pages.ShowPage("attribute-editor")
+	ex.app.SetFocus(ex.attributeEditor)
+}
+
+// showColumns opens the column chooser. Besides the displayed and default
+// columns, it offers the common attributes and those of the selected object.
+func (ex *Explorer) showColumns() {
+	var attrs []string
+	if obj := ex.table.GetSelection(); obj != nil {
+		for _, attr := range obj.Attribute {
+			attrs = append(attrs, string(attr.AttributeName))
+		}
+	}
+	ex.columnsWidget.SetColumns(ex.table.Columns(), widgets.DefaultColumns, widgets.SuggestedColumns, attrs)
+	ex.pages.ShowPage("columns")
+	ex.app.SetFocus(ex.columnsWidget)
+}
+
+// export writes the objects of the list, as filtered and sorted, with all their
+// attributes to a file, fetching the pages and the details not loaded yet in the
+// background. The progress page shows how far it got, and lets the user cancel.
+// Overwriting an existing file needs a confirmation.
+func (ex *Explorer) export(format, path string) {
+	if path == "" {
+		ex.setError(errors.New("Export: no file given"))
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		ex.askConfirm("Confirm Export", fmt.Sprintf("Overwrite %s ?", path), func() {
+			ex.runExport(format, path)
+		})
+		return
+	}
+	ex.runExport(format, path)
+}
+
+func (ex *Explorer) runExport(format, path string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ex.progressWidget.Start("Export", "Fetching the objects ...", cancel)
+	ex.pages.ShowPage("progress")
+	ex.app.SetFocus(ex.progressWidget)
+	go func() {
+		defer cancel()
+		objects, err := ex.table.Export(ctx, func(done, total int) {
+			ex.app.QueueUpdateDraw(func() {
+				ex.progressWidget.SetProgress(done, total)
+			})
+		})
+		if err == nil {
+			err = writeExport(path, format, objects)
+		}
+		ex.app.QueueUpdateDraw(func() {
+			switch {
+			case errors.Is(err, context.Canceled):
+				ex.progressWidget.Finish("Export canceled")
+			case err != nil:
+				ex.progressWidget.Finish("[red]Export failed:[-] " + tview.Escape(err.Error()))
+			default:
+				ex.progressWidget.Finish(tview.Escape(fmt.Sprintf("Exported %d objects to %s", len(objects), path)))
+			}
+		})
+	}()
+}
+
+// writeExport writes objects to a temporary file next to path, then renames it
+// into place, so that a failed export leaves any previous file untouched.
+func writeExport(path, format string, objects []*payloads.GetAttributesResponsePayload) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if err := widgets.WriteExport(f, format, objects); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// bulkAction runs the lifecycle action bound to event (activate, revoke,
+// destroy or rekey) on the marked objects, after a single confirmation, and
+// reports whether event was such an action.
+func (ex *Explorer) bulkAction(event *tcell.EventKey, ids []string) bool {
+	key := actionKey(event)
+	switch key {
+	case "<a>", "<r>", "<ctrl+d>", "<ctrl+t>":
+	default:
+		return false
+	}
+	if !ex.can(key) {
+		return true
+	}
+	objects := fmt.Sprintf("%d objects", len(ids))
+	if len(ids) == 1 {
+		objects = "object " + ids[0]
+	}
+	switch key {
+	case "<a>":
+		ex.askConfirm("Confirm Activate", fmt.Sprintf("Activate %s ?", objects), func() {
+			ex.bulk("Activate", ids, func(c *kmipclient.Client, id string) error {
+				_, err := c.Activate(id).Exec()
+				return err
+			})
+		})
+	case "<r>":
+		ex.revokeModal.OnDone(func(f func(*kmipclient.Client, string) (*payloads.RevokeResponsePayload, error)) {
+			ex.pages.HidePage("revoke")
+			ex.app.SetFocus(ex.table)
+			ex.askConfirm("Confirm Revoke", fmt.Sprintf("Revoke %s ?", objects), func() {
+				ex.bulk("Revoke", ids, func(c *kmipclient.Client, id string) error {
+					_, err := f(c, id)
+					return err
+				})
+			})
+		})
+		ex.pages.ShowPage("revoke")
+		ex.app.SetFocus(ex.revokeModal)
+	case "<ctrl+d>":
+		ex.askConfirm("Confirm Destroy", fmt.Sprintf("Destroy %s ?", objects), func() {
+			ex.bulk("Destroy", ids, func(c *kmipclient.Client, id string) error {
+				_, err := c.Destroy(id).Exec()
+				return err
+			})
+		})
+	case "<ctrl+t>":
+		ex.rekeyModal.OnDone(func(f func(*kmipclient.Client, string) (any, error)) {
+			ex.pages.HidePage("rekey")
+			ex.app.SetFocus(ex.table)
+			ex.askConfirm("Confirm Rekeying", fmt.Sprintf("Rekey %s ?", objects), func() {
+				ex.bulk("Rekey", ids, func(c *kmipclient.Client, id string) error {
+					_, err := f(c, id)
+					return err
+				})
+			})
+		})
+		ex.pages.ShowPage("rekey")
+		ex.app.SetFocus(ex.rekeyModal)
+	}
+	return true
+}
+
+// bulk runs an operation on each of the given objects in the background, one
+// after the other, showing the progress, then the outcome for each object. The
+// marks are cleared and the list reloaded once done.
+func (ex *Explorer) bulk(title string, ids []string, op func(*kmipclient.Client, string) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ex.progressWidget.Start(title, fmt.Sprintf("%s %d objects ...", title, len(ids)), cancel)
+	ex.progressWidget.SetProgress(0, len(ids))
+	ex.pages.ShowPage("progress")
+	ex.app.SetFocus(ex.progressWidget)
+	client := ex.client.Load()
+	go func() {
+		defer cancel()
+		errs := make([]error, 0, len(ids))
+		for i, id := range ids {
+			if ctx.Err() != nil {
+				break
+			}
+			errs = append(errs, op(client, id))
+			ex.app.QueueUpdateDraw(func() {
+				ex.progressWidget.SetProgress(i+1, len(ids))
+			})
+		}
+		summary := bulkSummary(title, ids, errs)
+		ex.app.QueueUpdateDraw(func() {
+			ex.progressWidget.Finish(summary)
+			ex.table.ClearMarks()
+		})
+		ex.refresh(false)
+	}()
+}
+
+// bulkSummary tells the outcome of a bulk operation for each object, where errs
+// holds the outcome of the objects processed before it completed or was
+// canceled, in order.
+func bulkSummary(title string, ids []string, errs []error) string {
+	failed := 0
+	var lines strings.Builder
+	for i, id := range ids {
+		switch {
+		case i >= len(errs):
+			lines.WriteString("[gray]- " + tview.Escape(id) + ": canceled[-]\n")
+		case errs[i] != nil:
+			failed++
+			lines.WriteString("[red]✗[-] " + tview.Escape(id) + ": " + tview.Escape(errs[i].Error()) + "\n")
+		default:
+			lines.WriteString("[green]✓[-] " + tview.Escape(id) + "\n")
+		}
+	}
+	head := fmt.Sprintf("%s: %d succeeded, %d failed", title, len(errs)-failed, failed)
+	if canceled := len(ids) - len(errs); canceled > 0 {
+		head += fmt.Sprintf(", %d canceled", canceled)
+	}
+	return head + "\n\n" + lines.String()
+}
+
 func (ex *Explorer) askConfirm(title, question string, f func()) {
 	ex.confirmModal.SetText(question).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
@@ -466,7 +1349,7 @@ func (ex *Explorer) askConfirm(title, question string, f func()) {
 				f()
 			}
 			ex.pages.HidePage("confirm")
-			ex.app.SetFocus(ex.table)
+			ex.focusFront()
 		}).
 		SetFocus(1). // Focus on the "No" button
 		SetTitle(title)
@@ -475,7 +1358,7 @@ func (ex *Explorer) askConfirm(title, question string, f func()) {
 }
 
 func (ex *Explorer) activate(id string) {
-	if _, err := ex.client.Activate(id).Exec(); err != nil {
+	if _, err := ex.client.Load().Activate(id).Exec(); err != nil {
 		ex.setError(err)
 		return
 	}
@@ -483,7 +1366,7 @@ func (ex *Explorer) activate(id string) {
 }
 
 func (ex *Explorer) destroy(id string) {
-	if _, err := ex.client.Destroy(id).Exec(); err != nil {
+	if _, err := ex.client.Load().Destroy(id).Exec(); err != nil {
 		ex.setError(err)
 		return
 	}
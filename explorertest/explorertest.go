@@ -0,0 +1,217 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package explorertest runs an [explorer.Explorer] headless, against an
+// in-memory KMIP server, for end-to-end tests of the explorer and of the
+// applications embedding it.
+//
+// A test sends keystrokes to the explorer, and waits for the screen to show
+// what it expects:
+//
+//	h := explorertest.New(t)
+//	h.Server.Add(kmip.ObjectTypeSecretData, nil, kmip.Attribute{
+//		AttributeName:  kmip.AttributeNameName,
+//		AttributeValue: kmip.Name{NameValue: "db-password"},
+//	})
+//	h.Press(tcell.KeyCtrlR)
+//	h.WaitFor("db-password")
+package explorertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go/kmipclient"
+	explorer "github.com/phsym/kmip-explorer"
+	"github.com/phsym/kmip-explorer/memkms"
+)
+
+// Screen size of the explorer, large enough to show every column and the help.
+const (
+	Width  = 200
+	Height = 50
+)
+
+// Timeout is how long [Harness.WaitFor] and [Harness.WaitUntil] wait.
+var Timeout = 5 * time.Second
+
+// Harness is an Explorer running on a simulated screen, connected to an
+// in-memory server.
+type Harness struct {
+	// Server is the server the explorer is connected to, to seed objects or
+	// check their attributes.
+	Server *memkms.Server
+	// Client is the connection of the explorer to Server.
+	Client *kmipclient.Client
+	// Explorer is the explorer under test.
+	Explorer *explorer.Explorer
+
+	t      testing.TB
+	screen *screen
+}
+
+// New starts an Explorer built with the given options, connected to a new
+// server. The explorer, the connection and the server are stopped at the end
+// of the test.
+func New(t testing.TB, opts ...explorer.Option) *Harness {
+	t.Helper()
+	srv := memkms.New()
+	ep, err := srv.Start(t.TempDir())
+	if err != nil {
+		t.Fatalf("starting the server: %v", err)
+	}
+	t.Cleanup(func() { ep.Close() })
+	client, err := kmipclient.Dial(ep.Addr,
+		kmipclient.WithRootCAFile(ep.CAFile),
+		kmipclient.WithClientCertFiles(ep.CertFile, ep.KeyFile),
+	)
+	if err != nil {
+		t.Fatalf("connecting to the server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	sc := &screen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	ex := explorer.New(client, "", "", append(opts, explorer.WithScreen(sc))...)
+	sc.SetSize(Width, Height)
+	h := &Harness{Server: srv, Client: client, Explorer: ex, t: t, screen: sc}
+
+	done := make(chan error, 1)
+	go func() { done <- ex.Run() }()
+	t.Cleanup(func() {
+		ex.Stop()
+		if err := <-done; err != nil {
+			t.Errorf("explorer: %v", err)
+		}
+	})
+	return h
+}
+
+// Type types text, one key per character.
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
+
+// Press presses a special key, such as [tcell.KeyEnter] or [tcell.KeyCtrlD].
+func (h *Harness) Press(key tcell.Key) {
+	h.Key(key, 0, tcell.ModNone)
+}
+
+// Key sends a key with modifiers.
+func (h *Harness) Key(key tcell.Key, r rune, mod tcell.ModMask) {
+	h.screen.InjectKey(key, r, mod)
+}
+
+// Screen returns the text last drawn on the screen, one line per row with the
+// trailing spaces trimmed.
+func (h *Harness) Screen() string {
+	return h.screen.text()
+}
+
+// WaitFor waits until the screen shows text, and fails the test with the
+// screen contents if it doesn't in time.
+func (h *Harness) WaitFor(text string) {
+	h.t.Helper()
+	h.WaitUntil(func(screen string) bool { return strings.Contains(screen, text) }, "the screen to show "+text)
+}
+
+// WaitUntil waits until cond holds for the text of the screen, and fails the
+// test with the screen contents if it doesn't in time. what tells what the
+// test waits for.
+func (h *Harness) WaitUntil(cond func(screen string) bool, what string) {
+	h.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	for {
+		drawn := h.screen.drawn()
+		text := h.Screen()
+		if cond(text) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			h.t.Fatalf("timed out waiting for %s, the screen shows:\n%s", what, text)
+		case <-drawn:
+		}
+	}
+}
+
+// screen is the simulated screen of the explorer, keeping a copy of what it
+// shows: the simulation screen changes its contents in place when drawn.
+type screen struct {
+	tcell.SimulationScreen
+
+	mu       sync.Mutex
+	contents string
+	// changed is closed on the next draw.
+	changed chan struct{}
+}
+
+func (s *screen) Show() {
+	s.SimulationScreen.Show()
+	s.snapshot()
+}
+
+func (s *screen) Sync() {
+	s.SimulationScreen.Sync()
+	s.snapshot()
+}
+
+// snapshot copies the contents of the screen. It runs in the UI goroutine,
+// right after the drawing.
+func (s *screen) snapshot() {
+	cells, width, height := s.GetContents()
+	var b strings.Builder
+	for y := range height {
+		var line strings.Builder
+		for _, cell := range cells[y*width : (y+1)*width] {
+			if len(cell.Runes) == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(string(cell.Runes))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contents = b.String()
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+func (s *screen) text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contents
+}
+
+// drawn returns a channel closed on the next draw.
+func (s *screen) drawn() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
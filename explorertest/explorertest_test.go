@@ -0,0 +1,208 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorertest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/phsym/kmip-explorer/explorertest"
+)
+
+func name(n string) kmip.Attribute {
+	return kmip.Attribute{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: n}}
+}
+
+func attribute(h *explorertest.Harness, id string, attrName kmip.AttributeName) any {
+	for _, attr := range h.Server.Attributes(id) {
+		if attr.AttributeName == attrName {
+			return attr.AttributeValue
+		}
+	}
+	return nil
+}
+
+// waitState waits until the object of the given ID is in state.
+func waitState(h *explorertest.Harness, id string, state kmip.State) {
+	h.WaitUntil(func(string) bool {
+		return attribute(h, id, kmip.AttributeNameState) == state
+	}, fmt.Sprintf("object %s to be in state %v", id, state))
+}
+
+// seed adds a symmetric key named n to the server, active if asked, and
+// selects it in the explorer.
+func seed(t testing.TB, h *explorertest.Harness, n string, active bool) string {
+	t.Helper()
+	id := h.Server.Add(kmip.ObjectTypeSymmetricKey, nil, name(n),
+		kmip.Attribute{AttributeName: kmip.AttributeNameCryptographicAlgorithm, AttributeValue: kmip.CryptographicAlgorithmAES},
+		kmip.Attribute{AttributeName: kmip.AttributeNameCryptographicLength, AttributeValue: int32(256)},
+	)
+	if active {
+		if err := h.Server.SetAttribute(id, kmip.AttributeNameActivationDate, time.Now().Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.Press(tcell.KeyCtrlR)
+	h.WaitFor(n)
+	h.Press(tcell.KeyDown)
+	return id
+}
+
+// confirm answers yes to the confirmation asked.
+func confirm(h *explorertest.Harness, question string) {
+	h.WaitFor(question)
+	h.Press(tcell.KeyLeft)
+	h.Press(tcell.KeyEnter)
+}
+
+func TestCreate(t *testing.T) {
+	h := explorertest.New(t)
+	h.Type("C")
+	h.WaitFor("Create object")
+	h.Type("new-key")
+	h.Press(tcell.KeyTab)   // Key Type
+	h.Press(tcell.KeyEnter) // opens the list
+	h.Press(tcell.KeyEnter) // AES
+	h.Press(tcell.KeyTab)   // Key Size
+	h.Press(tcell.KeyTab)   // Activation Date
+	h.Type("+7d")
+	h.Press(tcell.KeyTab) // Deactivation Date
+	h.Press(tcell.KeyTab) // Process Start Date
+	h.Press(tcell.KeyTab) // Protect Stop Date
+	h.Press(tcell.KeyTab) // OK
+	h.Press(tcell.KeyEnter)
+	h.WaitFor("new-key")
+
+	ids := h.Server.IDs()
+	if len(ids) != 1 {
+		t.Fatalf("%d objects created, want 1", len(ids))
+	}
+	if alg := attribute(h, ids[0], kmip.AttributeNameCryptographicAlgorithm); alg != kmip.CryptographicAlgorithmAES {
+		t.Fatalf("algorithm = %v, want AES", alg)
+	}
+	activation, _ := attribute(h, ids[0], kmip.AttributeNameActivationDate).(time.Time)
+	if d := time.Until(activation); d < 6*24*time.Hour || d > 7*24*time.Hour {
+		t.Fatalf("activation date = %v, want in 7 days", activation)
+	}
+	if st := attribute(h, ids[0], kmip.AttributeNameState); st != kmip.StatePreActive {
+		t.Fatalf("state = %v, want pre-active until the activation date", st)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	h := explorertest.New(t)
+	h.Type("R")
+	h.WaitFor("Register object")
+	h.Type("db-password")
+	h.Press(tcell.KeyTab)   // Object Type
+	h.Press(tcell.KeyEnter) // opens the list
+	h.Press(tcell.KeyEnter) // Secret
+	h.Press(tcell.KeyTab)   // Secret Value
+	h.Type("s3cret")
+	h.Press(tcell.KeyTab) // Base64
+	h.Press(tcell.KeyTab) // Activation Date
+	h.Press(tcell.KeyTab) // Deactivation Date
+	h.Press(tcell.KeyTab) // Process Start Date
+	h.Press(tcell.KeyTab) // Protect Stop Date
+	h.Press(tcell.KeyTab) // OK
+	h.Press(tcell.KeyEnter)
+	h.WaitFor("db-password")
+
+	ids := h.Server.IDs()
+	if len(ids) != 1 {
+		t.Fatalf("%d objects registered, want 1", len(ids))
+	}
+	if ot := attribute(h, ids[0], kmip.AttributeNameObjectType); ot != kmip.ObjectTypeSecretData {
+		t.Fatalf("object type = %v, want secret data", ot)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	h := explorertest.New(t)
+	id := seed(t, h, "to-revoke", true)
+	h.Type("r")
+	h.WaitFor("Revoke an object")
+	h.Press(tcell.KeyTab) // Message
+	h.Type("rotated")
+	h.Press(tcell.KeyTab) // OK
+	h.Press(tcell.KeyEnter)
+	confirm(h, "Revoke object "+id)
+	waitState(h, id, kmip.StateDeactivated)
+
+	reason, _ := attribute(h, id, kmip.AttributeNameRevocationReason).(kmip.RevocationReason)
+	if reason.RevocationMessage != "rotated" {
+		t.Fatalf("revocation message = %q, want rotated", reason.RevocationMessage)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	h := explorertest.New(t)
+	id := seed(t, h, "to-rekey", true)
+	h.Press(tcell.KeyCtrlT)
+	h.WaitFor("Offset days")
+	h.Press(tcell.KeyTab) // OK
+	h.Press(tcell.KeyEnter)
+	confirm(h, "Rekey object "+id)
+	h.WaitUntil(func(string) bool { return len(h.Server.IDs()) == 2 }, "the new key")
+
+	newID := h.Server.IDs()[1]
+	h.WaitFor(newID)
+	want := kmip.Link{LinkType: kmip.LinkTypeReplacementObjectLink, LinkedObjectIdentifier: newID}
+	if link := attribute(h, id, kmip.AttributeNameLink); link != want {
+		t.Fatalf("link of the old key = %v, want %v", link, want)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	h := explorertest.New(t)
+	id := seed(t, h, "to-destroy", false)
+	h.Press(tcell.KeyCtrlD)
+	confirm(h, "Destroy object "+id)
+	waitState(h, id, kmip.StateDestroyed)
+}
+
+func TestDestroyActive(t *testing.T) {
+	h := explorertest.New(t)
+	id := seed(t, h, "still-active", true)
+	h.Press(tcell.KeyCtrlD)
+	confirm(h, "Destroy object "+id)
+	h.WaitFor("revoke it first")
+	if st := attribute(h, id, kmip.AttributeNameState); st != kmip.StateActive {
+		t.Fatalf("state = %v, want active", st)
+	}
+}
+
+func TestExpiring(t *testing.T) {
+	h := explorertest.New(t)
+	id := seed(t, h, "expiring-key", true)
+	// An hour more, for the deadline to still be 3 days ahead once scanned.
+	if err := h.Server.SetAttribute(id, kmip.AttributeNameDeactivationDate, time.Now().Add(73*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	seed(t, h, "quiet-key", true)
+	h.Press(tcell.KeyBacktab) // Expiring, after the type tabs
+	h.WaitFor("Deactivation Date in 3d")
+	if strings.Contains(h.Screen(), "quiet-key") {
+		t.Fatalf("a key far from expiry is listed:\n%s", h.Screen())
+	}
+	// Selecting it jumps back to the table, on the object: its timeline shows.
+	h.Press(tcell.KeyEnter)
+	h.WaitFor("All Objects")
+	h.WaitFor("Deactivation, in 3d")
+}
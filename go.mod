@@ -3,6 +3,7 @@ module github.com/phsym/kmip-explorer
 go 1.26.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/atotto/clipboard v0.1.4
 	github.com/gdamore/tcell/v2 v2.13.10
 	github.com/google/uuid v1.6.0
@@ -0,0 +1,77 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/phsym/kmip-explorer/internal/inspect"
+	"github.com/phsym/kmip-explorer/internal/widgets"
+	"github.com/rivo/tview"
+)
+
+// Inspector records the latest messages exchanged with KMIP servers, for the
+// protocol inspector pane (see [WithInspector]).
+type Inspector struct {
+	recorder *inspect.Recorder
+}
+
+// NewInspector returns an Inspector keeping the last size exchanges.
+func NewInspector(size int) *Inspector {
+	return &Inspector{recorder: inspect.NewRecorder(size)}
+}
+
+// Middleware returns the client middleware recording the exchanges. It should
+// be the last middleware of the client, to record the messages as they are
+// sent.
+func (in *Inspector) Middleware() kmipclient.Middleware {
+	return in.recorder.Middleware()
+}
+
+// WithInspector adds the protocol inspector pane, toggled with <shift+i>,
+// showing the exchanges in recorded: timing, correlation value, and request
+// and response messages as TTLV, hex, XML or JSON. An error shown to the user
+// offers to jump to the exchange which produced it.
+func WithInspector(in *Inspector) Option {
+	return func(ex *Explorer) {
+		ex.inspector = in
+		ex.inspectorPane = widgets.NewInspector(in.recorder, func(p tview.Primitive) { ex.app.SetFocus(p) }).
+			OnClose(ex.hideInspector)
+		ex.layout.AddItem(ex.inspectorPane, 0, 0, false)
+		in.recorder.OnRecord(func() {
+			if ex.inspectorShown.Load() {
+				ex.app.QueueUpdateDraw(ex.inspectorPane.Refresh)
+			}
+		})
+		ex.banner.SetActionEnabled("<shift+i>", true)
+	}
+}
+
+// showInspector opens the inspector pane, on the exchange of the given ID if
+// not zero.
+func (ex *Explorer) showInspector(id int) {
+	ex.inspectorShown.Store(true)
+	ex.inspectorPane.Refresh()
+	if id != 0 {
+		ex.inspectorPane.Select(id)
+	}
+	ex.layout.ResizeItem(ex.inspectorPane, 0, 1)
+	ex.app.SetFocus(ex.inspectorPane)
+}
+
+func (ex *Explorer) hideInspector() {
+	ex.inspectorShown.Store(false)
+	ex.layout.ResizeItem(ex.inspectorPane, 0, 0)
+	ex.app.SetFocus(ex.table)
+}
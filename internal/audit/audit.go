@@ -0,0 +1,139 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit reads and appends to the audit log, a JSON-lines file with an
+// [Entry] per operation modifying objects on a server.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Results of an [Entry].
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Entry records an operation sent to a server.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Server is the address of the server.
+	Server string `json:"server"`
+	// Subject is the subject of the client certificate, e.g.
+	// "CN=alice,O=Example".
+	Subject string `json:"subject,omitempty"`
+	// Operation is the name of the KMIP operation, e.g. "Destroy".
+	Operation string `json:"operation"`
+	// IDs are the identifiers of the objects operated on, or created.
+	IDs []string `json:"ids,omitempty"`
+	// Params are the parameters of the request, by name; never key material.
+	Params map[string]string `json:"params,omitempty"`
+	// Result is ResultSuccess or ResultFailure, with Error telling why.
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DefaultPath returns the default location of the audit log:
+// kmip-explorer/audit.jsonl in the user's configuration directory (e.g.
+// ~/.config/kmip-explorer/audit.jsonl on Linux).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kmip-explorer", "audit.jsonl"), nil
+}
+
+// mu serializes the writes to audit logs, which every client of the process
+// may share.
+var mu sync.Mutex
+
+// Check tells whether entries can be appended to the log at path, creating it
+// and its directory if needed.
+func Check(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	f, err := open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Append adds entries to the end of the log at path, creating it and its
+// directory if needed.
+func Append(path string, entries ...Entry) error {
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	f, err := open(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func open(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+}
+
+// Read returns the entries of the log at path, oldest first. A missing log
+// has no entries.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return entries, fmt.Errorf("%s: line %d: %w", path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
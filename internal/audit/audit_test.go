@@ -0,0 +1,75 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	if entries, err := Read(path); err != nil || len(entries) != 0 {
+		t.Fatalf("Read of a missing log = %v, %v, want no entries", entries, err)
+	}
+	if err := Check(path); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	destroy := Entry{Time: now, Server: "kms:5696", Subject: "CN=alice", Operation: "Destroy", IDs: []string{"k1"}, Result: ResultSuccess}
+	create := Entry{Time: now, Server: "kms:5696", Operation: "Create", Params: map[string]string{"Cryptographic Length": "256"},
+		Result: ResultFailure, Error: "Permission Denied"}
+	if err := Append(path, destroy); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := Append(path, create); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Fatalf("log has %d lines, want one per entry:\n%s", len(lines), data)
+	}
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != "Destroy" || !slices.Equal(entries[0].IDs, []string{"k1"}) ||
+		!entries[0].Time.Equal(now) || entries[1].Params["Cryptographic Length"] != "256" || entries[1].Error != "Permission Denied" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestReadReportsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(`{"operation":"Activate","result":"success"}`+"\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Read(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Read err = %v, want an error on line 2", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Read returned %d entries, want the one before the bad line", len(entries))
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// relativeUnits are the units of relative dates, e.g. "+30d".
+var relativeUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// datePresets are the relative dates a DateField suggests.
+var datePresets = []string{"+1d", "+7d", "+30d", "+90d", "+180d", "+365d"}
+
+// ParseDate parses a date relative to now, a signed number of minutes, hours,
+// days or weeks such as "+30d" or "-2h", or an absolute one, in RFC 3339 or as
+// YYYY-MM-DD for midnight UTC.
+func ParseDate(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if len(text) > 2 && (text[0] == '+' || text[0] == '-') {
+		unit, ok := relativeUnits[text[len(text)-1]]
+		n, err := strconv.Atoi(text[1 : len(text)-1])
+		if !ok || err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid relative date %q, expected e.g. +30d, +12h or +2w", text)
+		}
+		d := time.Duration(n) * unit
+		if text[0] == '-' {
+			d = -d
+		}
+		return now.Add(d).Truncate(time.Second), nil
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, text); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected e.g. +30d or 2025-01-31T12:00:00Z", text)
+}
+
+// DateField is an input field for a date, relative to now such as "+30d" or
+// absolute (see ParseDate). Typing "+" or pressing <down> suggests common
+// relative dates along with the date they stand for. The text is shown in red
+// while it isn't a valid date.
+type DateField struct {
+	*tview.InputField
+	invalid bool
+	// suggest is set when <down> asks for the suggestions of an empty field,
+	// which aren't shown otherwise so that the field can be tabbed through.
+	suggest bool
+}
+
+func NewDateField(label string) *DateField {
+	df := &DateField{InputField: tview.NewInputField().SetLabel(label).SetPlaceholder("+30d or 2025-01-31T12:00:00Z")}
+	df.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		df.suggest = event.Key() == tcell.KeyDown
+		return event
+	})
+	df.SetAutocompleteFunc(func(text string) []string {
+		if text == "" && !df.suggest || text != "" && !strings.HasPrefix(text, "+") {
+			return nil
+		}
+		now := time.Now()
+		var entries []string
+		for _, p := range datePresets {
+			if strings.HasPrefix(p, text) && p != text {
+				t, _ := ParseDate(p, now)
+				entries = append(entries, fmt.Sprintf("%s  %s", p, t.Local().Format(time.DateTime)))
+			}
+		}
+		return entries
+	})
+	df.SetAutocompletedFunc(func(text string, _, source int) bool {
+		if source == tview.AutocompletedNavigate {
+			return false
+		}
+		df.SetText(strings.Fields(text)[0])
+		return true
+	})
+	df.SetChangedFunc(func(string) {
+		_, err := df.Date(time.Now())
+		df.invalid = err != nil
+	})
+	return df
+}
+
+// SetFormAttributes keeps the text in red while it isn't a valid date, as
+// [Form] sets the attributes of its items on every draw.
+func (df *DateField) SetFormAttributes(labelWidth int, labelColor, bgColor, fieldTextColor, fieldBgColor tcell.Color) tview.FormItem {
+	if df.invalid {
+		fieldTextColor = tcell.ColorRed
+	}
+	return df.InputField.SetFormAttributes(labelWidth, labelColor, bgColor, fieldTextColor, fieldBgColor)
+}
+
+// Date returns the date typed, relative to now, and zero if none.
+func (df *DateField) Date(now time.Time) (time.Time, error) {
+	if strings.TrimSpace(df.GetText()) == "" {
+		return time.Time{}, nil
+	}
+	return ParseDate(df.GetText(), now)
+}
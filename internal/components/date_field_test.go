@@ -0,0 +1,42 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 500, time.UTC)
+	for text, want := range map[string]time.Time{
+		"+30d":                 time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+		" +2w ":                time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC),
+		"+12h":                 time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
+		"-90m":                 time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC),
+		"2025-01-31T12:00:00Z": time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+		"2025-01-31":           time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		got, err := ParseDate(text, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"", "30d", "+30y", "+d", "+-3d", "tomorrow", "2025-13-01"} {
+		if _, err := ParseDate(text, now); err == nil {
+			t.Errorf("ParseDate(%q) should fail", text)
+		}
+	}
+}
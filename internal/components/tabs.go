@@ -80,6 +80,11 @@ func (t *Tabs) GetSelected() (int, string) {
 	return t.selected, t.choices[t.selected]
 }
 
+// Choices returns the names of the tabs, in order.
+func (t *Tabs) Choices() []string {
+	return t.choices
+}
+
 func (t *Tabs) SetChangedFunc(f func(int, string)) *Tabs {
 	t.changedCallback = f
 	return t
@@ -0,0 +1,316 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config reads and updates the kmip-explorer configuration file.
+//
+// The file is a TOML document (https://toml.io). Top-level settings come
+// first, then the tables: [theme], setting colors, [keys], rebinding actions,
+// and [profile.<name>], holding the connection settings of a server profile.
+// Unknown settings or tables are errors:
+//
+//	addr = "eu-west-rbx.okms.ovh.net:5696"
+//	cert = "/home/me/.kmip/client.crt"
+//	key = "/home/me/.kmip/client.key"
+//	check_update = false
+//	# Table columns, in display order
+//	columns = ["ID", "Name", "Activation Date", "x-Owner"]
+//	# Expiring tab: expiry within 2 weeks, keys to rotate after 180 days
+//	expiry_window = "14d"
+//	rotation_age = "180d"
+//
+//	[theme]
+//	border = "#87afff"
+//
+//	[keys]
+//	destroy = "ctrl+x"
+//
+//	[profile.dev]
+//	addr = "kms.dev.example.com:5696"
+//	cert = "/home/me/.kmip/dev.crt"
+//	key = "/home/me/.kmip/dev.key"
+//
+//	[profile.prod]
+//	addr = "kms.example.com:5696"
+//	cert = "/home/me/.kmip/prod.crt"
+//	key = "/home/me/.kmip/prod.key"
+//	read_only = true
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds the settings read from the configuration file. The zero value
+// is the default configuration.
+type Config struct {
+	// Addr, Cert, Key and CA are the connection settings used without a
+	// profile, see [Profile].
+	Addr string `toml:"addr"`
+	Cert string `toml:"cert"`
+	Key  string `toml:"key"`
+	CA   string `toml:"ca"`
+	// Profile is the name of the profile to connect to by default.
+	Profile string `toml:"default_profile"`
+	// TLS12Ciphers are the names of the TLS 1.2 cipher suites to allow; empty
+	// means the secure defaults.
+	TLS12Ciphers []string `toml:"tls12_ciphers"`
+	// CheckUpdate tells whether to check for a newer release; nil if unset.
+	CheckUpdate *bool `toml:"check_update"`
+	// NoCCV disables the client correlation value of requests; nil if unset.
+	NoCCV *bool `toml:"no_ccv"`
+	// ReadOnly refuses every operation modifying objects, on every server; nil
+	// if unset.
+	ReadOnly *bool `toml:"read_only"`
+	// AuditLog is the path to the audit log; empty for the default one.
+	AuditLog string `toml:"audit_log"`
+	// Columns are the names of the object table columns, in display order.
+	// Empty means the default columns.
+	Columns []string `toml:"columns"`
+	// ExpiryWindow and RotationAge set what the Expiring tab reports, as read
+	// by [ParseDuration]; empty for the defaults.
+	ExpiryWindow string `toml:"expiry_window"`
+	RotationAge  string `toml:"rotation_age"`
+	// Theme maps UI elements (e.g. "border") to their color, as a name or
+	// "#rrggbb".
+	Theme map[string]string `toml:"theme"`
+	// Keys maps actions (e.g. "destroy") to the key they are bound to (e.g.
+	// "ctrl+x").
+	Keys map[string]string `toml:"keys"`
+	// Profiles are the server profiles, in file order.
+	Profiles []Profile `toml:"-"`
+}
+
+// Profile holds the connection settings of a named server.
+type Profile struct {
+	Name string `toml:"-"`
+	// Addr is the address and port of the server.
+	Addr string `toml:"addr"`
+	// Cert and Key are the paths to the client certificate and private key.
+	Cert string `toml:"cert"`
+	Key  string `toml:"key"`
+	// CA is the path to the server's CA; empty for the system roots.
+	CA string `toml:"ca"`
+	// ReadOnly refuses every operation modifying objects on this server, even
+	// when the top-level setting doesn't; nil if unset.
+	ReadOnly *bool `toml:"read_only"`
+}
+
+// FindProfile returns the profile with the given name, or nil if there is
+// none.
+func (c *Config) FindProfile(name string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of the profiles, in file order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, len(c.Profiles))
+	for i, p := range c.Profiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// DefaultPath returns the default location of the configuration file:
+// kmip-explorer/config in the user's configuration directory (e.g.
+// ~/.config/kmip-explorer/config on Linux).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kmip-explorer", "config"), nil
+}
+
+// Load reads the configuration file at path. A missing file is not an error:
+// it yields the default configuration.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// file is the layout of the configuration file, where the profiles are tables.
+type file struct {
+	Config
+	Profile map[string]Profile `toml:"profile"`
+}
+
+func parse(data []byte) (*Config, error) {
+	var f file
+	md, err := toml.Decode(string(data), &f)
+	if err != nil {
+		return nil, err
+	}
+	if t := md.Type("profile"); t != "" && t != "Hash" {
+		return nil, errors.New(`profile: expected [profile.<name>] tables, the default profile is set by default_profile`)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown setting %q", undecoded[0].String())
+	}
+	cfg := &f.Config
+	for key, value := range map[string]string{"expiry_window": cfg.ExpiryWindow, "rotation_age": cfg.RotationAge} {
+		if value == "" {
+			continue
+		}
+		if _, err := ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	// The keys come in file order, unlike the profile map.
+	for _, key := range md.Keys() {
+		if len(key) < 2 || key[0] != "profile" || cfg.FindProfile(key[1]) != nil {
+			continue
+		}
+		p := f.Profile[key[1]]
+		p.Name = key[1]
+		cfg.Profiles = append(cfg.Profiles, p)
+	}
+	return cfg, nil
+}
+
+// ParseDuration parses a duration as a number of days or weeks, e.g. "30d" or
+// "2w", or in the format of [time.ParseDuration], e.g. "720h".
+func ParseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	unit := 24 * time.Hour
+	n, ok := strings.CutSuffix(s, "d")
+	if !ok {
+		n, ok = strings.CutSuffix(s, "w")
+		unit *= 7
+	}
+	if ok {
+		var days int
+		days, err = strconv.Atoi(n)
+		d = time.Duration(days) * unit
+	}
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: expected e.g. \"30d\", \"2w\" or \"12h\"", s)
+	}
+	return d, nil
+}
+
+// SaveColumns stores the table columns in the configuration file at path,
+// creating the file (and its directory) if needed. The rest of the file,
+// comments included, is left untouched, as is its mode. An empty list removes
+// the setting, so the default columns apply again.
+func SaveColumns(path string, columns []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, err := parse(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	setting := ""
+	if len(columns) > 0 {
+		var b strings.Builder
+		if err := toml.NewEncoder(&b).Encode(struct {
+			Columns []string `toml:"columns"`
+		}{columns}); err != nil {
+			return err
+		}
+		setting = strings.TrimSuffix(b.String(), "\n")
+	}
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	lines = setTopLevel(lines, "columns", setting)
+
+	mode := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return err
+	}
+	// WriteFile only applies the mode to new files.
+	return os.Chmod(path, mode)
+}
+
+// setTopLevel replaces the statement setting the top-level key in the TOML
+// lines with setting, or adds it before the first table if the key isn't set
+// yet. An empty setting removes the key.
+func setTopLevel(lines []string, key, setting string) []string {
+	var out []string
+	done := setting == ""
+	starts := statements(lines)
+	for i := 0; i+1 < len(starts); i++ {
+		stmt := lines[starts[i]:starts[i+1]]
+		if strings.HasPrefix(strings.TrimSpace(stmt[0]), "[") {
+			if !done {
+				out = append(out, setting, "")
+				done = true
+			}
+			return append(out, lines[starts[i]:]...)
+		}
+		var values map[string]any
+		if _, err := toml.Decode(strings.Join(stmt, "\n"), &values); err == nil && values[key] != nil {
+			if !done {
+				out = append(out, setting)
+				done = true
+			}
+			continue
+		}
+		out = append(out, stmt...)
+	}
+	if !done {
+		out = append(out, setting)
+	}
+	return out
+}
+
+// statements returns the index of the first line of each statement of the TOML
+// document made of lines, such as a setting, possibly spanning several lines,
+// a table header or a comment, followed by len(lines). A statement ends where
+// the lines read so far make a valid document.
+func statements(lines []string) []int {
+	starts := []int{0}
+	for i := 1; i <= len(lines); i++ {
+		var values map[string]any
+		if _, err := toml.Decode(strings.Join(lines[:i], "\n"), &values); err == nil || i == len(lines) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
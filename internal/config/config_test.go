@@ -0,0 +1,256 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFileIsDefault(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "nope"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Columns) != 0 {
+		t.Fatalf("Columns = %v, want none", cfg.Columns)
+	}
+}
+
+func TestLoadColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := "# my columns\n\ncolumns = [\"ID\", \"x-Team # 1\",\"Name\",] # trailing\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"ID", "x-Team # 1", "Name"}; !slices.Equal(cfg.Columns, want) {
+		t.Fatalf("Columns = %q, want %q", cfg.Columns, want)
+	}
+}
+
+func TestLoadRejectsBadLines(t *testing.T) {
+	for _, data := range []string{
+		"columns",
+		"columns = ID, Name",
+		`columns = "ID"]`,
+		`columns = ["ID" "Name"]`,
+		`colums = ["ID"]`,
+		`check_update = yes`,
+		`addr = kms:5696`,
+		`expiry_window = "a month"`,
+		`rotation_age = 365`,
+		`columns = [`,
+		`profile = "dev"`,
+	} {
+		path := filepath.Join(t.TempDir(), "config")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), path) {
+			t.Fatalf("Load(%q) error = %v, want an error about %s", data, err, path)
+		}
+	}
+}
+
+func TestSaveColumnsKeepsTheRestOfTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config")
+	if err := SaveColumns(path, []string{"ID"}); err != nil {
+		t.Fatalf("SaveColumns (new file): %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if got := string(data); got != "columns = [\"ID\"]\n" {
+		t.Fatalf("new file = %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("# header\ncolumns = [\"ID\"]\n# footer\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveColumns(path, []string{"Name", `say "hi"`}); err != nil {
+		t.Fatalf("SaveColumns: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if got, want := string(data), "# header\ncolumns = [\"Name\", \"say \\\"hi\\\"\"]\n# footer\n"; got != want {
+		t.Fatalf("file = %q, want %q", got, want)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"Name", `say "hi"`}; !slices.Equal(cfg.Columns, want) {
+		t.Fatalf("Columns = %q, want %q", cfg.Columns, want)
+	}
+
+	if err := SaveColumns(path, nil); err != nil {
+		t.Fatalf("SaveColumns(nil): %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if got := string(data); got != "# header\n# footer\n" {
+		t.Fatalf("file after reset = %q", got)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := `columns = ["ID"]
+
+[profile.dev]
+addr = "kms.dev:5696" # local
+cert = "dev.crt"
+key = "dev.key"
+
+[profile."eu west"]
+addr = "kms.eu:5696"
+ca = "eu-ca.pem"
+read_only = true
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"dev", "eu west"}; !slices.Equal(cfg.ProfileNames(), want) {
+		t.Fatalf("ProfileNames = %q, want %q", cfg.ProfileNames(), want)
+	}
+	if got, want := *cfg.FindProfile("dev"), (Profile{Name: "dev", Addr: "kms.dev:5696", Cert: "dev.crt", Key: "dev.key"}); got != want {
+		t.Fatalf("dev = %+v, want %+v", got, want)
+	}
+	if got := cfg.FindProfile("eu west"); got.Addr != "kms.eu:5696" || got.CA != "eu-ca.pem" || got.ReadOnly == nil || !*got.ReadOnly {
+		t.Fatalf("eu west = %+v", got)
+	}
+	if cfg.FindProfile("prod") != nil {
+		t.Fatal("Profile(prod) should be nil")
+	}
+
+	for _, bad := range []string{"[servers]", "[profile.a b]", "[profile.dev]\nport = \"1\"", "[profile.dev]\naddr = kms", "[profile.dev]\nread_only = \"yes\"", "[profile.a]\n[profile.a]"} {
+		if err := os.WriteFile(path, []byte(bad), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Fatalf("Load(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSaveColumnsKeepsTheMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := "columns = [\n  \"ID\",\n  \"Name\",\n]\ncheck_update = false\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveColumns(path, []string{"State"}); err != nil {
+		t.Fatalf("SaveColumns: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if want := "columns = [\"State\"]\ncheck_update = false\n"; string(got) != want {
+		t.Fatalf("file = %q, want %q", got, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Fatalf("Stat = %v, %v, want mode 0644", info, err)
+	}
+}
+
+func TestSaveColumnsStaysOutOfProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[profile.dev]\naddr = \"kms:5696\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveColumns(path, []string{"ID"}); err != nil {
+		t.Fatalf("SaveColumns: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !slices.Equal(cfg.Columns, []string{"ID"}) || cfg.FindProfile("dev").Addr != "kms:5696" {
+		t.Fatalf("config = %+v", cfg)
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := `addr = "kms:5696"
+ca = "ca.pem"
+default_profile = "dev"
+tls12_ciphers = [
+  "A", # first
+  'B',
+]
+check_update = false
+read_only = true
+audit_log = "audit.jsonl"
+expiry_window = "2w"
+rotation_age = "180d"
+
+[theme]
+border = "#87afff"
+
+[keys]
+destroy = "ctrl+x"
+
+[profile.dev]
+addr = "kms.dev:5696"
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Addr != "kms:5696" || cfg.CA != "ca.pem" || cfg.Profile != "dev" || !slices.Equal(cfg.TLS12Ciphers, []string{"A", "B"}) {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if cfg.CheckUpdate == nil || *cfg.CheckUpdate || cfg.NoCCV != nil || cfg.ReadOnly == nil || !*cfg.ReadOnly || cfg.AuditLog != "audit.jsonl" {
+		t.Fatalf("CheckUpdate = %v, NoCCV = %v, ReadOnly = %v, AuditLog = %q", cfg.CheckUpdate, cfg.NoCCV, cfg.ReadOnly, cfg.AuditLog)
+	}
+	if cfg.ExpiryWindow != "2w" || cfg.RotationAge != "180d" {
+		t.Fatalf("ExpiryWindow = %q, RotationAge = %q", cfg.ExpiryWindow, cfg.RotationAge)
+	}
+	if cfg.Theme["border"] != "#87afff" || cfg.Keys["destroy"] != "ctrl+x" || len(cfg.Keys) != 1 {
+		t.Fatalf("Theme = %v, Keys = %v", cfg.Theme, cfg.Keys)
+	}
+	if cfg.FindProfile("dev").Addr != "kms.dev:5696" {
+		t.Fatalf("dev = %+v", cfg.FindProfile("dev"))
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"30d":  30 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"0d":   0,
+		"0":    0,
+		"720h": 720 * time.Hour,
+	} {
+		if got, err := ParseDuration(s); err != nil || got != want {
+			t.Fatalf("ParseDuration(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "d", "-3d", "1.5d", "a month", "-1h"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Fatalf("ParseDuration(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inspect records the messages exchanged with a KMIP server, to show
+// them as they went on the wire.
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/ttlv"
+)
+
+// Formats are the encodings [Render] supports.
+var Formats = []string{"Text", "Hex", "XML", "JSON"}
+
+// Exchange is a request sent to the server, and its response.
+type Exchange struct {
+	// ID numbers the exchanges from 1, in the order they started.
+	ID       int
+	Time     time.Time
+	Duration time.Duration
+	Request  *kmip.RequestMessage
+	// Response is nil when the request failed before the server answered,
+	// with Err telling why.
+	Response *kmip.ResponseMessage
+	Err      error
+}
+
+// Operations lists the operations of the request, e.g. "Locate" or
+// "Create, Activate".
+func (e *Exchange) Operations() string {
+	ops := make([]string, len(e.Request.BatchItem))
+	for i, item := range e.Request.BatchItem {
+		ops[i] = ttlv.EnumStr(item.Operation)
+	}
+	return strings.Join(ops, ", ")
+}
+
+// CorrelationValue returns the client correlation value of the request, if
+// any.
+func (e *Exchange) CorrelationValue() string {
+	return e.Request.Header.ClientCorrelationValue
+}
+
+// Failure tells why the exchange failed: the error of the transport, or the
+// result of the first batch item the server failed. It is empty on success.
+func (e *Exchange) Failure() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Response == nil {
+		return ""
+	}
+	for _, item := range e.Response.BatchItem {
+		if item.ResultStatus != kmip.ResultStatusSuccess {
+			msg := ttlv.EnumStr(item.ResultReason)
+			if item.ResultMessage != "" {
+				msg += ": " + item.ResultMessage
+			}
+			return msg
+		}
+	}
+	return ""
+}
+
+// Render encodes a message in one of Formats: the TTLV text dump, a hex dump
+// of the binary TTLV encoding, or the KMIP XML or JSON encoding.
+func Render(msg any, format string) string {
+	switch format {
+	case "Hex":
+		return hex.Dump(ttlv.MarshalTTLV(msg))
+	case "XML":
+		return string(ttlv.MarshalXML(msg))
+	case "JSON":
+		data := ttlv.MarshalJSON(msg)
+		var buf bytes.Buffer
+		if json.Indent(&buf, data, "", "  ") != nil {
+			return string(data)
+		}
+		return buf.String()
+	}
+	return string(ttlv.MarshalText(msg))
+}
+
+// Error is the error of a request, tagged with the ID of its exchange.
+type Error struct {
+	Exchange int
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Recorder keeps the latest exchanges made through its middleware. It is safe
+// for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	size      int
+	exchanges []*Exchange
+	lastID    int
+	onRecord  func()
+}
+
+// NewRecorder returns a recorder keeping the last size exchanges.
+func NewRecorder(size int) *Recorder {
+	return &Recorder{size: max(size, 1)}
+}
+
+// OnRecord sets the function called, off the UI goroutine, after each
+// exchange.
+func (r *Recorder) OnRecord(cb func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRecord = cb
+}
+
+// Middleware returns the client middleware recording the exchanges. It should
+// come last, to record the messages as they are sent. The errors it returns are
+// [Error]s, tagged with their exchange; the failure of a request of a single
+// operation is returned as such an error, telling the result of the server.
+func (r *Recorder) Middleware() kmipclient.Middleware {
+	return func(next kmipclient.Next, ctx context.Context, msg *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		r.mu.Lock()
+		r.lastID++
+		e := &Exchange{ID: r.lastID, Time: time.Now(), Request: msg}
+		r.mu.Unlock()
+
+		resp, err := next(ctx, msg)
+		e.Duration = time.Since(e.Time)
+		e.Response, e.Err = resp, err
+
+		r.mu.Lock()
+		r.exchanges = append(r.exchanges, e)
+		if len(r.exchanges) > r.size {
+			r.exchanges = r.exchanges[len(r.exchanges)-r.size:]
+		}
+		cb := r.onRecord
+		r.mu.Unlock()
+		if cb != nil {
+			cb()
+		}
+		if f := e.Failure(); err == nil && f != "" && len(msg.BatchItem) == 1 {
+			err = errors.New(f)
+		}
+		if err != nil {
+			err = &Error{Exchange: e.ID, Err: err}
+		}
+		return resp, err
+	}
+}
+
+// Exchanges returns the exchanges kept, oldest first.
+func (r *Recorder) Exchanges() []*Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Exchange(nil), r.exchanges...)
+}
+
+// Find returns the exchange which produced err, nil if err doesn't come from
+// the middleware or the exchange is no longer kept.
+func (r *Recorder) Find(err error) *Exchange {
+	var xerr *Error
+	if !errors.As(err, &xerr) {
+		return nil
+	}
+	for _, e := range r.Exchanges() {
+		if e.ID == xerr.Exchange {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(3)
+	records := 0
+	r.OnRecord(func() { records++ })
+	mw := r.Middleware()
+
+	send := func(op kmip.Operation, resp *kmip.ResponseMessage, err error) error {
+		msg := &kmip.RequestMessage{BatchItem: []kmip.RequestBatchItem{{Operation: op}}}
+		msg.Header.ClientCorrelationValue = fmt.Sprint("ccv-", op)
+		_, err = mw(func(context.Context, *kmip.RequestMessage) (*kmip.ResponseMessage, error) { return resp, err }, context.Background(), msg)
+		return err
+	}
+	ok := &kmip.ResponseMessage{BatchItem: []kmip.ResponseBatchItem{{}}}
+	denied := &kmip.ResponseMessage{BatchItem: []kmip.ResponseBatchItem{{ResultStatus: kmip.ResultStatusOperationFailed, ResultMessage: "access denied"}}}
+
+	if err := send(kmip.OperationLocate, ok, nil); err != nil {
+		t.Fatalf("Locate: %v", err)
+	}
+	denyErr := send(kmip.OperationDestroy, denied, nil)
+	if denyErr == nil || !strings.HasSuffix(denyErr.Error(), ": access denied") {
+		t.Fatalf("the failure of the server should be returned, got %v", denyErr)
+	}
+	resetErr := send(kmip.OperationGet, nil, errors.New("connection reset"))
+	if resetErr == nil || resetErr.Error() != "connection reset" {
+		t.Fatalf("the error of the transport should be returned, got %v", resetErr)
+	}
+	send(kmip.OperationGetAttributes, ok, nil)
+
+	exchanges := r.Exchanges()
+	if records != 4 || len(exchanges) != 3 {
+		t.Fatalf("%d records, %d exchanges kept, want 4 and 3", records, len(exchanges))
+	}
+	if exchanges[0].ID != 2 || exchanges[2].ID != 4 {
+		t.Fatalf("kept exchanges #%d..#%d, want #2..#4", exchanges[0].ID, exchanges[2].ID)
+	}
+	if got := exchanges[0].CorrelationValue(); got != fmt.Sprint("ccv-", kmip.OperationDestroy) {
+		t.Fatalf("CorrelationValue = %q", got)
+	}
+	if exchanges[2].Failure() != "" || exchanges[1].Failure() != "connection reset" {
+		t.Fatalf("failures = %q, %q", exchanges[1].Failure(), exchanges[2].Failure())
+	}
+
+	// The errors point to their exchange, the failure of the server included,
+	// as long as it is kept.
+	if e := r.Find(denyErr); e == nil || e.ID != 2 {
+		t.Fatalf("Find(access denied) = %v, want #2", e)
+	}
+	if e := r.Find(resetErr); e == nil || e.ID != 3 {
+		t.Fatalf("Find(connection reset) = %v, want #3", e)
+	}
+	if e := r.Find(fmt.Errorf("Failed: %w", resetErr)); e == nil || e.ID != 3 {
+		t.Fatalf("Find(wrapped connection reset) = %v, want #3", e)
+	}
+	if e := r.Find(errors.New("something else")); e != nil {
+		t.Fatalf("Find(something else) = %v, want nil", e)
+	}
+	send(kmip.OperationLocate, ok, nil)
+	if e := r.Find(denyErr); e != nil {
+		t.Fatalf("Find(access denied) = %v, want nil once #2 is dropped", e)
+	}
+	if e := NewRecorder(1).Find(errors.New("x")); e != nil {
+		t.Fatalf("Find without failed exchanges = %v, want nil", e)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package locate lists the objects of a server with Locate, a page at a time
+// when the server supports it.
+package locate
+
+import (
+	"math"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+// PageSize is the number of objects requested per Locate page when the server
+// supports paging (see Paged).
+const PageSize = 500
+
+// Paged reports whether Locate can be paged with Maximum Items and Offset
+// Items. Offset Items only exists since KMIP 1.3; older servers get a single
+// Locate returning every object.
+func Paged(client *kmipclient.Client) bool {
+	v := client.Version()
+	return v.ProtocolVersionMajor > 1 || v.ProtocolVersionMinor >= 3
+}
+
+// All returns the ids of every object matching criteria, in pages when the
+// server supports them.
+func All(client *kmipclient.Client, criteria ...kmip.Attribute) ([]string, error) {
+	paged := Paged(client)
+	return pages(paged, func(offset int32) (*payloads.LocateResponsePayload, error) {
+		req := client.Locate()
+		if len(criteria) > 0 {
+			req = req.WithAttributes(criteria...)
+		}
+		if paged {
+			req = req.WithMaxItems(PageSize).WithOffset(offset)
+		}
+		return req.Exec()
+	})
+}
+
+// pages gathers the ids of the pages returned by locate, from offset 0
+// until the last page, or the only one if the server can't page. As for the
+// object table, ids already returned are skipped, and a page bringing nothing
+// new ends the listing: a server ignoring the offset would otherwise return its
+// first page forever. So does an offset past the range of KMIP integers.
+func pages(paged bool, locate func(offset int32) (*payloads.LocateResponsePayload, error)) ([]string, error) {
+	var ids []string
+	seen := map[string]struct{}{}
+	for offset := 0; offset <= math.MaxInt32; {
+		resp, err := locate(int32(offset))
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, id := range resp.UniqueIdentifier {
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+			added++
+		}
+		offset += len(resp.UniqueIdentifier)
+		if !paged || added == 0 || len(resp.UniqueIdentifier) < PageSize ||
+			(resp.LocatedItems != nil && offset >= int(*resp.LocatedItems)) {
+			break
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestPages(t *testing.T) {
+	objects := make([]string, 1200)
+	for i := range objects {
+		objects[i] = fmt.Sprintf("id-%d", i)
+	}
+	for _, tt := range []struct {
+		name         string
+		paged        bool
+		ignoreOffset bool
+		want         int
+		wantCalls    int
+	}{
+		{name: "paged", paged: true, want: 1200, wantCalls: 3},
+		{name: "not paged", want: 1200, wantCalls: 1},
+		{name: "offset ignored", paged: true, ignoreOffset: true, want: PageSize, wantCalls: 2},
+	} {
+		calls := 0
+		ids, err := pages(tt.paged, func(offset int32) (*payloads.LocateResponsePayload, error) {
+			calls++
+			if !tt.paged {
+				return &payloads.LocateResponsePayload{UniqueIdentifier: objects}, nil
+			}
+			if tt.ignoreOffset {
+				offset = 0
+			}
+			end := min(int(offset)+PageSize, len(objects))
+			return &payloads.LocateResponsePayload{UniqueIdentifier: objects[offset:end]}, nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(ids) != tt.want || calls != tt.wantCalls || ids[0] != "id-0" {
+			t.Fatalf("%s: got %d ids in %d calls, want %d in %d", tt.name, len(ids), calls, tt.want, tt.wantCalls)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query turns the filter expressions typed in the search bar, such as
+//
+//	state=active alg=AES len=256 activation>=2025-01-01 name="prod key"
+//
+// into Locate attribute criteria, so the server does the filtering. Terms are
+// separated by spaces and all of them must match. Locate can only match
+// attribute values exactly, except for dates which accept a range, so any
+// other comparison is rejected with an error naming the offending term.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/ttlv"
+)
+
+// Operators understood in a term, longest first so that "<=" isn't read as "<".
+var operators = []string{"!=", "<=", ">=", "=", "~", "<", ">"}
+
+// term is a single "field op value" criterion.
+type term struct {
+	raw   string
+	field string
+	op    string
+	value string
+}
+
+// IsQuery reports whether text contains at least one "field op value" term, and
+// should therefore be run as a Locate query rather than used as a plain text
+// filter. Text with an unterminated quote is filter text until the quote is
+// closed.
+func IsQuery(text string) bool {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return false
+	}
+	for _, tok := range tokens {
+		if _, ok := splitTerm(tok); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse converts a query into the attributes to pass to Locate. An empty query
+// yields no attributes.
+func Parse(text string) ([]kmip.Attribute, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		attrs  []kmip.Attribute
+		ranges = map[kmip.AttributeName]*dateRange{}
+		order  []kmip.AttributeName
+	)
+	for _, tok := range tokens {
+		t, ok := splitTerm(tok)
+		if !ok {
+			return nil, fmt.Errorf("%q: expected a criterion like field=value", tok)
+		}
+		if t.op == "!=" {
+			return nil, fmt.Errorf("%s: unsupported operator %q: Locate cannot express a negation", t.raw, t.op)
+		}
+		if name, ok := dateFields[t.field]; ok {
+			rng, ok := ranges[name]
+			if !ok {
+				rng = &dateRange{}
+				ranges[name] = rng
+				order = append(order, name)
+			}
+			if err := rng.add(t); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		attr, err := t.attribute()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+	for _, name := range order {
+		attrs = append(attrs, ranges[name].attributes(name)...)
+	}
+	return attrs, nil
+}
+
+// tokenize splits text on spaces, keeping double-quoted sections (which may
+// contain spaces and \" escapes) within their token and unquoting them.
+func tokenize(text string) ([]string, error) {
+	var (
+		tokens  []string
+		cur     strings.Builder
+		inQuote bool
+		escaped bool
+		started bool
+	)
+	for _, r := range text {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && unicode.IsSpace(r):
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, errors.New("unterminated quote")
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// splitTerm splits a token into its field, operator and value. The field must be
+// a plain identifier so that free text containing e.g. "=" isn't mistaken for a
+// criterion.
+func splitTerm(tok string) (term, bool) {
+	i := strings.IndexAny(tok, "!=~<>")
+	if i <= 0 {
+		return term{}, false
+	}
+	field := tok[:i]
+	for _, r := range field {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return term{}, false
+		}
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(tok[i:], op) {
+			return term{raw: tok, field: strings.ToLower(field), op: op, value: tok[i+len(op):]}, true
+		}
+	}
+	return term{}, false
+}
+
+// attribute converts a non-date term into a single Locate criterion.
+func (t term) attribute() (kmip.Attribute, error) {
+	switch t.field {
+	case "name":
+		if t.op == "~" {
+			return kmip.Attribute{}, t.unsupported(fmt.Sprintf("Locate matches names exactly, use name=%q", t.value))
+		}
+		if err := t.wantEqual(); err != nil {
+			return kmip.Attribute{}, err
+		}
+		return kmip.Attribute{
+			AttributeName:  kmip.AttributeNameName,
+			AttributeValue: kmip.Name{NameValue: t.value, NameType: kmip.NameTypeUninterpretedTextString},
+		}, nil
+	case "group":
+		if err := t.wantEqual(); err != nil {
+			return kmip.Attribute{}, err
+		}
+		return kmip.Attribute{AttributeName: kmip.AttributeNameObjectGroup, AttributeValue: t.value}, nil
+	case "state":
+		if err := t.wantEqual(); err != nil {
+			return kmip.Attribute{}, err
+		}
+		st, ok := states[normalize(t.value)]
+		if !ok {
+			return kmip.Attribute{}, fmt.Errorf("%s: unknown state %q", t.raw, t.value)
+		}
+		return kmip.Attribute{AttributeName: kmip.AttributeNameState, AttributeValue: st}, nil
+	case "type":
+		if err := t.wantEqual(); err != nil {
+			return kmip.Attribute{}, err
+		}
+		ot, ok := objectTypes[normalize(t.value)]
+		if !ok {
+			return kmip.Attribute{}, fmt.Errorf("%s: unknown object type %q", t.raw, t.value)
+		}
+		return kmip.Attribute{AttributeName: kmip.AttributeNameObjectType, AttributeValue: ot}, nil
+	case "alg", "algorithm":
+		if err := t.wantEqual(); err != nil {
+			return kmip.Attribute{}, err
+		}
+		alg, ok := lookupAlgorithm(t.value)
+		if !ok {
+			return kmip.Attribute{}, fmt.Errorf("%s: unknown cryptographic algorithm %q", t.raw, t.value)
+		}
+		return kmip.Attribute{AttributeName: kmip.AttributeNameCryptographicAlgorithm, AttributeValue: alg}, nil
+	case "len", "length", "size":
+		if t.op != "=" {
+			return kmip.Attribute{}, t.unsupported(fmt.Sprintf("Locate can only match a cryptographic length exactly, use %s=<bits>", t.field))
+		}
+		l, err := strconv.ParseInt(t.value, 10, 32)
+		if err != nil || l <= 0 {
+			return kmip.Attribute{}, fmt.Errorf("%s: invalid length %q", t.raw, t.value)
+		}
+		return kmip.Attribute{AttributeName: kmip.AttributeNameCryptographicLength, AttributeValue: int32(l)}, nil
+	default:
+		return kmip.Attribute{}, fmt.Errorf("%s: unknown field %q", t.raw, t.field)
+	}
+}
+
+func (t term) wantEqual() error {
+	if t.op != "=" {
+		return t.unsupported(fmt.Sprintf("Locate can only match %s exactly, use %s=", t.field, t.field))
+	}
+	return nil
+}
+
+// unsupported returns the error rejecting the operator of the term, and why.
+func (t term) unsupported(why string) error {
+	return fmt.Errorf("%s: unsupported operator %q on %s: %s", t.raw, t.op, t.field, why)
+}
+
+// dateFields maps query fields to the date attributes they filter on.
+var dateFields = map[string]kmip.AttributeName{
+	"initial":      kmip.AttributeNameInitialDate,
+	"activation":   kmip.AttributeNameActivationDate,
+	"deactivation": kmip.AttributeNameDeactivationDate,
+	"destroy":      kmip.AttributeNameDestroyDate,
+	"compromise":   kmip.AttributeNameCompromiseDate,
+	"lastchange":   kmip.AttributeNameLastChangeDate,
+}
+
+// dateRange accumulates the bounds set on one date attribute. Locate reads two
+// instances of the same date attribute as an inclusive [min, max] range, and a
+// single one as an exact match.
+type dateRange struct {
+	exact    *time.Time
+	from, to *time.Time
+}
+
+func (r *dateRange) add(t term) error {
+	d, err := parseDate(t.value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", t.raw, err)
+	}
+	switch t.op {
+	case "=":
+		r.exact = &d
+	case ">=":
+		r.from = &d
+	case ">":
+		d = d.Add(time.Second)
+		r.from = &d
+	case "<=":
+		r.to = &d
+	case "<":
+		d = d.Add(-time.Second)
+		r.to = &d
+	default:
+		return fmt.Errorf("%s: unsupported operator %q on a date", t.raw, t.op)
+	}
+	if r.exact != nil && (r.from != nil || r.to != nil) {
+		return fmt.Errorf("%s: a date cannot be both matched exactly and ranged", t.raw)
+	}
+	return nil
+}
+
+// Bounds used for the open side of a date range.
+var (
+	minDate = time.Unix(0, 0).UTC()
+	maxDate = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+)
+
+func (r *dateRange) attributes(name kmip.AttributeName) []kmip.Attribute {
+	if r.exact != nil {
+		return []kmip.Attribute{{AttributeName: name, AttributeValue: *r.exact}}
+	}
+	from, to := minDate, maxDate
+	if r.from != nil {
+		from = *r.from
+	}
+	if r.to != nil {
+		to = *r.to
+	}
+	return []kmip.Attribute{
+		{AttributeName: name, AttributeValue: from},
+		{AttributeName: name, AttributeValue: to},
+	}
+}
+
+// parseDate accepts an RFC 3339 timestamp or a plain YYYY-MM-DD date (midnight UTC).
+func parseDate(s string) (time.Time, error) {
+	if d, err := time.Parse(time.RFC3339, s); err == nil {
+		return d, nil
+	}
+	if d, err := time.Parse(time.DateOnly, s); err == nil {
+		return d, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", s)
+}
+
+var states = map[string]kmip.State{
+	"preactive":            kmip.StatePreActive,
+	"active":               kmip.StateActive,
+	"deactivated":          kmip.StateDeactivated,
+	"compromised":          kmip.StateCompromised,
+	"destroyed":            kmip.StateDestroyed,
+	"destroyedcompromised": kmip.StateDestroyedCompromised,
+}
+
+var objectTypes = map[string]kmip.ObjectType{
+	"certificate":  kmip.ObjectTypeCertificate,
+	"cert":         kmip.ObjectTypeCertificate,
+	"symmetrickey": kmip.ObjectTypeSymmetricKey,
+	"symmetric":    kmip.ObjectTypeSymmetricKey,
+	"publickey":    kmip.ObjectTypePublicKey,
+	"public":       kmip.ObjectTypePublicKey,
+	"privatekey":   kmip.ObjectTypePrivateKey,
+	"private":      kmip.ObjectTypePrivateKey,
+	"secretdata":   kmip.ObjectTypeSecretData,
+	"secret":       kmip.ObjectTypeSecretData,
+	"opaqueobject": kmip.ObjectTypeOpaqueObject,
+	"opaque":       kmip.ObjectTypeOpaqueObject,
+}
+
+// maxAlgorithm bounds the scan in lookupAlgorithm; it comfortably covers every
+// algorithm defined by KMIP 1.4.
+const maxAlgorithm = 0x40
+
+// lookupAlgorithm resolves an algorithm by the name the table shows for it,
+// ignoring case and punctuation so that "hmac-sha256" finds "HMAC_SHA256".
+func lookupAlgorithm(s string) (kmip.CryptographicAlgorithm, bool) {
+	want := normalize(s)
+	for i := kmip.CryptographicAlgorithm(1); i <= maxAlgorithm; i++ {
+		if normalize(ttlv.EnumStr(i)) == want {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// normalize lowercases s and drops everything but letters and digits.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
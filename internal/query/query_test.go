@@ -0,0 +1,120 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+)
+
+func TestIsQuery(t *testing.T) {
+	for text, want := range map[string]bool{
+		"":                  false,
+		"prod":              false,
+		"my key":            false,
+		"state=active":      true,
+		"prod len=256":      true,
+		`name="unfinished`:  false,
+		`"quoted text`:      false,
+		"a=b":               true,
+		"=value":            false,
+		"some.thing=value":  false,
+		"activation>=today": true,
+	} {
+		if got := IsQuery(text); got != want {
+			t.Errorf("IsQuery(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestParseExactMatches(t *testing.T) {
+	attrs, err := Parse(`state=Active type=symmetric-key len=256 name="prod key" group=web`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []kmip.Attribute{
+		{AttributeName: kmip.AttributeNameState, AttributeValue: kmip.StateActive},
+		{AttributeName: kmip.AttributeNameObjectType, AttributeValue: kmip.ObjectTypeSymmetricKey},
+		{AttributeName: kmip.AttributeNameCryptographicLength, AttributeValue: int32(256)},
+		{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "prod key", NameType: kmip.NameTypeUninterpretedTextString}},
+		{AttributeName: kmip.AttributeNameObjectGroup, AttributeValue: "web"},
+	}
+	if len(attrs) != len(want) {
+		t.Fatalf("Parse returned %d attributes, want %d: %v", len(attrs), len(want), attrs)
+	}
+	for i := range want {
+		if attrs[i].AttributeName != want[i].AttributeName || attrs[i].AttributeValue != want[i].AttributeValue {
+			t.Errorf("attribute %d = %v, want %v", i, attrs[i], want[i])
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	attrs, err := Parse("activation>=2025-01-01 activation<2025-02-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(attrs) != 2 {
+		t.Fatalf("Parse returned %d attributes, want 2 (range bounds)", len(attrs))
+	}
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 31, 23, 59, 59, 0, time.UTC)
+	for i, want := range []time.Time{from, to} {
+		if attrs[i].AttributeName != kmip.AttributeNameActivationDate {
+			t.Errorf("attribute %d name = %q, want Activation Date", i, attrs[i].AttributeName)
+		}
+		if got, _ := attrs[i].AttributeValue.(time.Time); !got.Equal(want) {
+			t.Errorf("attribute %d value = %v, want %v", i, attrs[i].AttributeValue, want)
+		}
+	}
+
+	// A single lower bound is closed by the far-future maximum.
+	attrs, err = Parse("initial>=2025-01-01")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(attrs) != 2 || !attrs[1].AttributeValue.(time.Time).Equal(maxDate) {
+		t.Fatalf("open range = %v, want [2025-01-01, maxDate]", attrs)
+	}
+}
+
+func TestParseRejectsInexpressibleCriteria(t *testing.T) {
+	for text, wantErr := range map[string]string{
+		"len>=256":                              `unsupported operator ">=" on len`,
+		`name~"prod-*"`:                         `unsupported operator "~" on name`,
+		"group<web":                             `unsupported operator "<" on group`,
+		"state!=active":                         `unsupported operator "!=": Locate cannot express a negation`,
+		`len>=256 name~"prod-*"`:                `unsupported operator ">="`,
+		"activation~2025-01-01":                 `unsupported operator "~" on a date`,
+		"state=sleepy":                          "unknown state",
+		"colour=blue":                           "unknown field",
+		"prod state=active":                     "expected a criterion",
+		`name="prod`:                            "unterminated quote",
+		"initial=yesterday":                     "invalid date",
+		"initial=2025-01-01 initial>2024-01-01": "both matched exactly and ranged",
+	} {
+		_, err := Parse(text)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error containing %q", text, wantErr)
+			continue
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", text, err, wantErr)
+		}
+	}
+}
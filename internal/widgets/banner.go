@@ -16,6 +16,7 @@ package widgets
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ovh/kmip-go/kmipclient"
 
@@ -76,10 +77,17 @@ func (inf *Info) UpdateServerName(name string) {
 
 type Help struct {
 	*tview.Table
+	// entries locates the entry of each action by its default key, e.g.
+	// "<ctrl+d>".
+	entries map[string][2]int
+	// hidden holds the key and label texts of the hidden entries, by default
+	// key.
+	hidden map[string][2]string
 }
 
+var helpStyle = tcell.StyleDefault.Bold(true).Foreground(tcell.ColorDeepSkyBlue)
+
 func NewHelp() *Help {
-	helpStyle := tcell.StyleDefault.Bold(true).Foreground(tcell.ColorDeepSkyBlue)
 	help := tview.NewTable().SetSeparator('\t').
 		// 1st column
 		SetCell(0, 0, tview.NewTableCell("<ctrl+r>").SetStyle(helpStyle)).SetCell(0, 1, tview.NewTableCell("Refresh")).
@@ -95,15 +103,98 @@ func NewHelp() *Help {
 		SetCell(0, 4, tview.NewTableCell("<tab>").SetStyle(helpStyle)).SetCell(0, 5, tview.NewTableCell("Next page")).
 		SetCell(1, 4, tview.NewTableCell("<shift+tab>").SetStyle(helpStyle)).SetCell(1, 5, tview.NewTableCell("Previous page")).
 		SetCell(2, 4, tview.NewTableCell("<enter>").SetStyle(helpStyle)).SetCell(2, 5, tview.NewTableCell("Browse attributes")).
-		SetCell(3, 4, tview.NewTableCell("<q>").SetStyle(helpStyle)).SetCell(3, 5, tview.NewTableCell("Quit"))
-	return &Help{help}
+		SetCell(3, 4, tview.NewTableCell("<q>").SetStyle(helpStyle)).SetCell(3, 5, tview.NewTableCell("Quit")).
+		// 4th column
+		SetCell(0, 6, tview.NewTableCell("</>").SetStyle(helpStyle)).SetCell(0, 7, tview.NewTableCell("Search")).
+		SetCell(1, 6, tview.NewTableCell("<s>").SetStyle(helpStyle)).SetCell(1, 7, tview.NewTableCell("Sort column")).
+		SetCell(2, 6, tview.NewTableCell("<shift+s>").SetStyle(helpStyle)).SetCell(2, 7, tview.NewTableCell("Reverse sort")).
+		SetCell(3, 6, tview.NewTableCell("<c>").SetStyle(helpStyle)).SetCell(3, 7, tview.NewTableCell("Columns")).
+		// 5th column
+		SetCell(0, 8, tview.NewTableCell("<e>").SetStyle(helpStyle)).SetCell(0, 9, tview.NewTableCell("Edit attributes")).
+		SetCell(1, 8, tview.NewTableCell("<w>").SetStyle(helpStyle)).SetCell(1, 9, tview.NewTableCell("Crypto workbench")).
+		SetCell(2, 8, tview.NewTableCell("<i>").SetStyle(helpStyle)).SetCell(2, 9, tview.NewTableCell("Server")).
+		SetCell(3, 8, tview.NewTableCell("<p>").SetStyle(helpStyle)).SetCell(3, 9, tview.NewTableCell("Switch server")).
+		// 6th column
+		SetCell(0, 10, tview.NewTableCell("<x>").SetStyle(helpStyle)).SetCell(0, 11, tview.NewTableCell("Export")).
+		SetCell(1, 10, tview.NewTableCell("<m>").SetStyle(helpStyle)).SetCell(1, 11, tview.NewTableCell("Mark")).
+		SetCell(2, 10, tview.NewTableCell("<shift+v>").SetStyle(helpStyle)).SetCell(2, 11, tview.NewTableCell("Mark range")).
+		SetCell(3, 10, tview.NewTableCell("<ctrl+a>").SetStyle(helpStyle)).SetCell(3, 11, tview.NewTableCell("Mark all")).
+		// 7th column
+		SetCell(0, 12, tview.NewTableCell("<shift+l>").SetStyle(helpStyle)).SetCell(0, 13, tview.NewTableCell("Audit log")).
+		SetCell(1, 12, tview.NewTableCell("<shift+i>").SetStyle(helpStyle)).SetCell(1, 13, tview.NewTableCell("Inspector")).
+		SetCell(2, 12, tview.NewTableCell("<f>").SetStyle(helpStyle)).SetCell(2, 13, tview.NewTableCell("Linked objects")).
+		SetCell(3, 12, tview.NewTableCell("<[>").SetStyle(helpStyle)).SetCell(3, 13, tview.NewTableCell("Back")).
+		// 8th column
+		SetCell(0, 14, tview.NewTableCell("<]>").SetStyle(helpStyle)).SetCell(0, 15, tview.NewTableCell("Forward")).
+		SetCell(1, 14, tview.NewTableCell("<shift+h>").SetStyle(helpStyle)).SetCell(1, 15, tview.NewTableCell("Rekey lineage"))
+	entries := map[string][2]int{}
+	for row := range help.GetRowCount() {
+		for col := 0; col < help.GetColumnCount(); col += 2 {
+			if text := help.GetCell(row, col).Text; text != "" {
+				entries[text] = [2]int{row, col}
+			}
+		}
+	}
+	return &Help{help, entries, map[string][2]string{}}
+}
+
+// SetKey shows the entry of the action whose default key is key (e.g.
+// "<ctrl+d>") with another key.
+func (h *Help) SetKey(key, newKey string) {
+	if texts, ok := h.hidden[key]; ok {
+		h.hidden[key] = [2]string{newKey, texts[1]}
+		return
+	}
+	if pos, ok := h.entries[key]; ok {
+		h.GetCell(pos[0], pos[1]).SetText(newKey)
+	}
+}
+
+// SetEnabled greys out the entry of the action whose default key is key (e.g.
+// "<ctrl+d>") when the action is not available.
+func (h *Help) SetEnabled(key string, enabled bool) {
+	pos, ok := h.entries[key]
+	if !ok {
+		return
+	}
+	keyStyle, labelColor := helpStyle, tview.Styles.PrimaryTextColor
+	if !enabled {
+		keyStyle = tcell.StyleDefault.Foreground(tcell.ColorDarkGray)
+		labelColor = tcell.ColorDarkGray
+	}
+	h.GetCell(pos[0], pos[1]).SetStyle(keyStyle)
+	h.GetCell(pos[0], pos[1]+1).SetTextColor(labelColor)
+}
+
+// SetHidden removes the entry of the action whose default key is key (e.g.
+// "<ctrl+d>") from the help, or puts it back.
+func (h *Help) SetHidden(key string, hidden bool) {
+	pos, ok := h.entries[key]
+	if !ok {
+		return
+	}
+	keyCell, labelCell := h.GetCell(pos[0], pos[1]), h.GetCell(pos[0], pos[1]+1)
+	texts, isHidden := h.hidden[key]
+	switch {
+	case hidden && !isHidden:
+		h.hidden[key] = [2]string{keyCell.Text, labelCell.Text}
+		keyCell.SetText("")
+		labelCell.SetText("")
+	case !hidden && isHidden:
+		delete(h.hidden, key)
+		keyCell.SetText(texts[0])
+		labelCell.SetText(texts[1])
+	}
 }
 
 type Banner struct {
 	*tview.Flex
-	info *Info
-	help *Help
-	logo *Logo
+	info     *Info
+	help     *Help
+	logo     *Logo
+	addr     string
+	profile  string
+	readOnly bool
 }
 
 func NewBanner(version, latest string) *Banner {
@@ -114,14 +205,60 @@ func NewBanner(version, latest string) *Banner {
 		AddItem(info, 0, 1, false).
 		AddItem(help, 0, 1, false).
 		AddItem(logo, logo.Width(), 0, false)
-	return &Banner{banner, info, help, logo}
+	return &Banner{Flex: banner, info: info, help: help, logo: logo}
 }
 
 func (b *Banner) Height() int {
 	return max(b.info.GetRowCount(), b.help.GetRowCount(), b.logo.GetOriginalLineCount()-1) + 1
 }
 
+// SetActionKey shows the help entry of the action whose default key is key with
+// another key.
+func (b *Banner) SetActionKey(key, newKey string) {
+	b.help.SetKey(key, newKey)
+}
+
+// SetActionEnabled greys out the help entry of the given key when the action is
+// not available.
+func (b *Banner) SetActionEnabled(key string, enabled bool) {
+	b.help.SetEnabled(key, enabled)
+}
+
+// SetActionHidden removes the help entry of the given key, or puts it back.
+func (b *Banner) SetActionHidden(key string, hidden bool) {
+	b.help.SetHidden(key, hidden)
+}
+
 func (b *Banner) SetClientInfo(client *kmipclient.Client) {
 	b.info.UpdateKmipVersion("v" + client.Version().String())
-	b.info.UpdateServerName(client.Addr())
+	b.addr = client.Addr()
+	b.updateServerName()
+}
+
+// SetProfile shows the name of the server profile next to the server address;
+// an empty name shows the address only.
+func (b *Banner) SetProfile(name string) {
+	b.profile = name
+	b.updateServerName()
+}
+
+// SetReadOnly tells next to the server name that objects can't be modified.
+func (b *Banner) SetReadOnly(readOnly bool) {
+	b.readOnly = readOnly
+	b.updateServerName()
+}
+
+func (b *Banner) updateServerName() {
+	var notes []string
+	if b.profile != "" {
+		notes = append(notes, b.profile)
+	}
+	if b.readOnly {
+		notes = append(notes, "read-only")
+	}
+	if len(notes) == 0 {
+		b.info.UpdateServerName(b.addr)
+		return
+	}
+	b.info.UpdateServerName(fmt.Sprintf("%s (%s)", b.addr, strings.Join(notes, ", ")))
 }
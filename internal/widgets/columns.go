@@ -0,0 +1,264 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+)
+
+// DefaultColumns is the table layout used when none is configured.
+var DefaultColumns = []string{"ID", "Type", "Name", "Algorithm", "Size", "State", "Age"}
+
+// SuggestedColumns are offered by the column chooser besides the default ones.
+// Any other attribute name, custom "x-" attributes included, can be used as a
+// column too.
+var SuggestedColumns = []string{
+	"Initial Date",
+	"Activation Date",
+	"Process Start Date",
+	"Protect Stop Date",
+	"Deactivation Date",
+	"Destroy Date",
+	"Last Change Date",
+	"Cryptographic Usage Mask",
+	"Object Group",
+	"Contact Information",
+	"Public Key Link",
+	"Private Key Link",
+	"Certificate Link",
+	"Replacement Object Link",
+	"Replaced Object Link",
+}
+
+// linkColumns maps the "<type> Link" column names to the link type whose linked
+// object identifier they show.
+var linkColumns = map[string]kmip.LinkType{
+	"certificate link":            kmip.LinkTypeCertificateLink,
+	"public key link":             kmip.LinkTypePublicKeyLink,
+	"private key link":            kmip.LinkTypePrivateKeyLink,
+	"derivation base object link": kmip.LinkTypeDerivationBaseObjectLink,
+	"derived key link":            kmip.LinkTypeDerivedKeyLink,
+	"replacement object link":     kmip.LinkTypeReplacementObjectLink,
+	"replaced object link":        kmip.LinkTypeReplacedObjectLink,
+	"parent link":                 kmip.LinkTypeParentLink,
+	"child link":                  kmip.LinkTypeChildLink,
+	"previous link":               kmip.LinkTypePreviousLink,
+	"next link":                   kmip.LinkTypeNextLink,
+}
+
+// column is one column of the object table. value extracts the cell text of a
+// loaded row along with the key the column sorts on: a string, an int64 or a
+// time.Time, or nil when the row has no value.
+type column struct {
+	name  string
+	isID  bool // shows the object id: placeholders keep it filled while loading
+	isAge bool // time-relative: the text is recomputed on every draw (see GetCell)
+	value func(v *payloads.GetAttributesResponsePayload, f rowFields) (string, any)
+}
+
+// resolveColumns turns configured column names into columns, falling back to
+// DefaultColumns when names is empty.
+func resolveColumns(names []string) []column {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	cols := make([]column, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			cols = append(cols, resolveColumn(name))
+		}
+	}
+	return cols
+}
+
+// ColumnTexts renders the given columns (see resolveColumn) of an object as
+// shown in the table, DefaultColumns when names is empty. Ages are relative to
+// now.
+func ColumnTexts(v *payloads.GetAttributesResponsePayload, names []string) []string {
+	cols := resolveColumns(names)
+	fields := parseRowFields(v)
+	texts := make([]string, len(cols))
+	for i, col := range cols {
+		texts[i], _ = col.value(v, fields)
+	}
+	return texts
+}
+
+// ColumnNames returns the names of the given columns, DefaultColumns when names
+// is empty, as ColumnTexts renders them.
+func ColumnNames(names []string) []string {
+	cols := resolveColumns(names)
+	res := make([]string, len(cols))
+	for i, col := range cols {
+		res[i] = col.name
+	}
+	return res
+}
+
+// resolveColumn resolves a column name (case-insensitively for the built-in
+// ones): one of DefaultColumns, a "<type> Link" column showing the id of the
+// linked object, or else any attribute name.
+func resolveColumn(name string) column {
+	switch strings.ToLower(name) {
+	case "id":
+		return column{name: name, isID: true, value: func(v *payloads.GetAttributesResponsePayload, _ rowFields) (string, any) {
+			return v.UniqueIdentifier, v.UniqueIdentifier
+		}}
+	case "type":
+		return column{name: name, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			txt := ttlv.EnumStr(f.otype)
+			return txt, txt
+		}}
+	case "name":
+		return column{name: name, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			return f.name, strings.ToLower(f.name)
+		}}
+	case "algorithm":
+		return column{name: name, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			return f.alg, f.alg
+		}}
+	case "size":
+		return column{name: name, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			size, err := strconv.ParseInt(f.size, 10, 64)
+			if err != nil {
+				return f.size, nil
+			}
+			return f.size, size
+		}}
+	case "state":
+		// States sort in lifecycle order rather than alphabetically.
+		return column{name: name, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			return ttlv.EnumStr(f.state), int64(f.state)
+		}}
+	case "age":
+		// Younger objects sort first; unknown dates sort as the oldest.
+		return column{name: name, isAge: true, value: func(_ *payloads.GetAttributesResponsePayload, f rowFields) (string, any) {
+			if f.initialDate.IsZero() {
+				return "", int64(math.MaxInt64)
+			}
+			return formatAge(time.Since(f.initialDate)), -f.initialDate.Unix()
+		}}
+	}
+	if lt, ok := linkColumns[strings.ToLower(name)]; ok {
+		return column{name: name, value: func(v *payloads.GetAttributesResponsePayload, _ rowFields) (string, any) {
+			var ids []string
+			for _, attr := range v.Attribute {
+				if l, ok := attr.AttributeValue.(kmip.Link); ok && attr.AttributeName == kmip.AttributeNameLink && l.LinkType == lt {
+					ids = append(ids, l.LinkedObjectIdentifier)
+				}
+			}
+			txt := strings.Join(ids, ", ")
+			return txt, txt
+		}}
+	}
+	attrName := kmip.AttributeName(name)
+	return column{name: name, value: func(v *payloads.GetAttributesResponsePayload, _ rowFields) (string, any) {
+		var (
+			texts []string
+			key   any
+		)
+		for _, attr := range v.Attribute {
+			if attr.AttributeName != attrName {
+				continue
+			}
+			txt, k := formatAttributeValue(attr.AttributeValue)
+			if key == nil {
+				key = k
+			}
+			texts = append(texts, txt)
+		}
+		return strings.Join(texts, ", "), key
+	}}
+}
+
+// formatAttributeValue renders an attribute value on a single line, with its
+// sort key. Values without a dedicated rendering use their TTLV text form, as in
+// the attributes panel.
+func formatAttributeValue(value any) (string, any) {
+	switch v := value.(type) {
+	case string:
+		return v, strings.ToLower(v)
+	case time.Time:
+		return v.Local().Format(time.DateTime), v
+	case int32:
+		return strconv.FormatInt(int64(v), 10), int64(v)
+	case int64:
+		return strconv.FormatInt(v, 10), v
+	case bool:
+		return strconv.FormatBool(v), strconv.FormatBool(v)
+	case kmip.Name:
+		return v.NameValue, strings.ToLower(v.NameValue)
+	case kmip.Link:
+		return v.LinkedObjectIdentifier, v.LinkedObjectIdentifier
+	}
+	enc := ttlv.NewTextEncoder()
+	enc.TagAny(kmip.TagAttributeValue, value)
+	txt := string(enc.Bytes())
+	// Drop the "AttributeValue (<type>): " header and fold nested lines.
+	if _, after, ok := strings.Cut(txt, "): "); ok {
+		txt = after
+	}
+	txt = strings.Join(strings.Fields(txt), " ")
+	return txt, strings.ToLower(txt)
+}
+
+// AttributeText renders an attribute value on a single line, as the table
+// shows it.
+func AttributeText(value any) string {
+	txt, _ := formatAttributeValue(value)
+	return txt
+}
+
+// compareKeys orders two sort keys produced by column values. Rows without a
+// value (nil key) come first, like empty text would; keys of different kinds
+// (only possible for attributes of unexpected types) compare as equal.
+func compareKeys(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b)
+		}
+	case int64:
+		if b, ok := b.(int64); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,272 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/rivo/tview"
+)
+
+// Severity ranks what the dashboard reports about an object, most urgent first.
+type Severity int
+
+const (
+	// SeverityOverdue is an object still active past its Deactivation Date or
+	// the expiry of its certificate.
+	SeverityOverdue Severity = iota
+	// SeverityExpiring is an object whose Deactivation Date or certificate
+	// expiry comes within the window of the dashboard.
+	SeverityExpiring
+	// SeverityStale is an active key older than the rotation age of the
+	// dashboard, from its Initial Date.
+	SeverityStale
+)
+
+var severities = []struct {
+	label string
+	color tcell.Color
+}{
+	SeverityOverdue:  {"Overdue", tcell.ColorRed},
+	SeverityExpiring: {"Expiring", tcell.ColorYellow},
+	SeverityStale:    {"Due for rotation", tcell.ColorDeepSkyBlue},
+}
+
+// ScannedObject is an object checked by the [Dashboard].
+type ScannedObject struct {
+	ID string
+	// Attributes are those of the object, nil when not loaded; Err tells why
+	// they couldn't be.
+	Attributes *payloads.GetAttributesResponsePayload
+	Err        error
+	// NotAfter is the end of validity of a certificate, zero for other objects
+	// or when unknown.
+	NotAfter time.Time
+}
+
+// finding is what the dashboard reports about an object.
+type finding struct {
+	obj      *ScannedObject
+	severity Severity
+	// date is the date at issue: the one passed or coming, or the Initial Date
+	// of a stale key.
+	date   time.Time
+	reason string
+}
+
+// assess tells what the dashboard reports about obj, if anything, at its most
+// severe: an expiry passed on an object still active, then one coming within
+// window, then an active key older than rotationAge (zero for none). The
+// expiry is the earliest of the Deactivation Date and the end of validity of a
+// certificate.
+func assess(obj *ScannedObject, now time.Time, window, rotationAge time.Duration) (finding, bool) {
+	var (
+		state                 kmip.State
+		objectType            kmip.ObjectType
+		deactivation, initial time.Time
+	)
+	for _, attr := range obj.Attributes.Attribute {
+		switch v := attr.AttributeValue.(type) {
+		case kmip.State:
+			state = v
+		case kmip.ObjectType:
+			objectType = v
+		case time.Time:
+			switch attr.AttributeName {
+			case kmip.AttributeNameDeactivationDate:
+				deactivation = v
+			case kmip.AttributeNameInitialDate:
+				initial = v
+			}
+		}
+	}
+	if state != kmip.StateActive && state != kmip.StatePreActive {
+		return finding{}, false
+	}
+
+	expiry, what := deactivation, "Deactivation Date"
+	if !obj.NotAfter.IsZero() && (expiry.IsZero() || obj.NotAfter.Before(expiry)) {
+		expiry, what = obj.NotAfter, "Certificate expiry"
+	}
+	switch {
+	case expiry.IsZero():
+	case !expiry.After(now):
+		if state == kmip.StateActive {
+			return finding{obj, SeverityOverdue, expiry, fmt.Sprintf("%s passed %s, still active", what, relativeTime(expiry, now))}, true
+		}
+	case !expiry.After(now.Add(window)):
+		return finding{obj, SeverityExpiring, expiry, fmt.Sprintf("%s %s", what, relativeTime(expiry, now))}, true
+	}
+
+	isKey := objectType == kmip.ObjectTypeSymmetricKey || objectType == kmip.ObjectTypePrivateKey || objectType == kmip.ObjectTypePublicKey
+	if rotationAge > 0 && isKey && state == kmip.StateActive && !initial.IsZero() && now.Sub(initial) >= rotationAge {
+		return finding{obj, SeverityStale, initial, fmt.Sprintf("Created %s, not rotated", relativeTime(initial, now))}, true
+	}
+	return finding{}, false
+}
+
+// assessAll returns the findings about objs, grouped by severity, the most
+// urgent date first within a group.
+func assessAll(objs []*ScannedObject, now time.Time, window, rotationAge time.Duration) []finding {
+	var findings []finding
+	for _, obj := range objs {
+		if obj.Err != nil {
+			continue
+		}
+		if f, ok := assess(obj, now, window, rotationAge); ok {
+			findings = append(findings, f)
+		}
+	}
+	slices.SortStableFunc(findings, func(a, b finding) int {
+		if a.severity != b.severity {
+			return int(a.severity - b.severity)
+		}
+		return a.date.Compare(b.date)
+	})
+	return findings
+}
+
+// Dashboard lists, grouped by severity, the objects still active past their
+// Deactivation Date or certificate expiry, those expiring within a window, and
+// the keys older than a rotation age. Selecting an object calls the selected
+// callback with its id.
+type Dashboard struct {
+	*tview.Flex
+	table   *tview.Table
+	summary *tview.TextView
+
+	window, rotationAge time.Duration
+	// ids are the ids of the objects listed, by row; empty for the rows
+	// heading a group.
+	ids        []string
+	onSelected func(id string)
+}
+
+func NewDashboard(window, rotationAge time.Duration) *Dashboard {
+	db := &Dashboard{window: window, rotationAge: rotationAge}
+	db.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0).
+		SetSelectedFunc(func(row, _ int) {
+			if row < len(db.ids) && db.ids[row] != "" && db.onSelected != nil {
+				db.onSelected(db.ids[row])
+			}
+		})
+	db.summary = tview.NewTextView().SetDynamicColors(true)
+	db.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(db.table, 0, 1, true).
+		AddItem(db.summary, 1, 0, false)
+	db.SetBorder(true).SetTitle("Expiring & Stale Objects")
+	return db
+}
+
+// OnSelected is called with the id of the object selected.
+func (db *Dashboard) OnSelected(cb func(id string)) *Dashboard {
+	db.onSelected = cb
+	return db
+}
+
+// SetPolicy sets how soon an expiry must come to be reported, and the age past
+// which an active key is due for rotation (zero for none). It applies from the
+// next call to SetObjects.
+func (db *Dashboard) SetPolicy(window, rotationAge time.Duration) {
+	db.window, db.rotationAge = window, rotationAge
+}
+
+// SetLoading shows the progress of the scan of the objects.
+func (db *Dashboard) SetLoading(done, total int) {
+	db.table.Clear()
+	db.ids = nil
+	text := "[gray]Scanning the active objects…[-]"
+	if total > 0 {
+		text = fmt.Sprintf("[gray]Scanning the active objects… %d/%d[-]", done, total)
+	}
+	db.summary.SetText(text)
+}
+
+// SetError shows why the objects couldn't be listed.
+func (db *Dashboard) SetError(err error) {
+	db.table.Clear()
+	db.ids = nil
+	db.summary.SetText("[red]Failed to list the objects:[-] " + tview.Escape(err.Error()))
+}
+
+// SetObjects lists what there is to report about objs.
+func (db *Dashboard) SetObjects(objs []*ScannedObject) {
+	now := time.Now()
+	findings := assessAll(objs, now, db.window, db.rotationAge)
+	db.table.Clear()
+	db.ids = []string{""}
+	for col, title := range []string{"ID", "Name", "Type", "State", "Date", "Issue"} {
+		db.table.SetCell(0, col, tview.NewTableCell(title).SetSelectable(false).
+			SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold))
+	}
+	counts := make([]int, len(severities))
+	for _, f := range findings {
+		counts[f.severity]++
+	}
+	for i, f := range findings {
+		if i == 0 || findings[i-1].severity != f.severity {
+			s := severities[f.severity]
+			row := len(db.ids)
+			db.table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf("%s (%d)", s.label, counts[f.severity])).
+				SetSelectable(false).SetTextColor(s.color).SetAttributes(tcell.AttrBold))
+			db.ids = append(db.ids, "")
+		}
+		row := len(db.ids)
+		texts := ColumnTexts(f.obj.Attributes, []string{"Name", "Type", "State"})
+		db.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(f.obj.ID)).SetExpansion(1))
+		for col, text := range texts {
+			db.table.SetCell(row, col+1, tview.NewTableCell(tview.Escape(text)))
+		}
+		db.table.SetCell(row, 4, tview.NewTableCell(f.date.Local().Format(time.DateTime))).
+			SetCell(row, 5, tview.NewTableCell(f.reason).SetTextColor(severities[f.severity].color))
+		db.ids = append(db.ids, f.obj.ID)
+	}
+	db.summary.SetText(db.summaryText(objs, counts))
+	db.table.ScrollToBeginning().Select(min(2, len(db.ids)-1), 0)
+}
+
+// summaryText counts the findings and the objects checked, with the policy
+// they were checked against.
+func (db *Dashboard) summaryText(objs []*ScannedObject, counts []int) string {
+	failed := 0
+	for _, obj := range objs {
+		if obj.Err != nil {
+			failed++
+		}
+	}
+	text := fmt.Sprintf("%d active objects checked: [red]%d overdue[-], [yellow]%d expiring within %s[-]",
+		len(objs)-failed, counts[SeverityOverdue], counts[SeverityExpiring], formatDuration(db.window))
+	if db.rotationAge > 0 {
+		text += fmt.Sprintf(", [deepskyblue]%d keys older than %s[-]", counts[SeverityStale], formatDuration(db.rotationAge))
+	}
+	if failed > 0 {
+		text += fmt.Sprintf(". [red]%d could not be read[-]", failed)
+	}
+	return text
+}
+
+// formatDuration shows a duration in days when it is a whole number of them,
+// e.g. "30d".
+func formatDuration(d time.Duration) string {
+	if d > 0 && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
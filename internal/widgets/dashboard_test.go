@@ -0,0 +1,102 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestAssessAll(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	object := func(id string, ot kmip.ObjectType, state kmip.State, dates map[kmip.AttributeName]time.Time) *ScannedObject {
+		attrs := []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameObjectType, AttributeValue: ot},
+			{AttributeName: kmip.AttributeNameState, AttributeValue: state},
+		}
+		for name, date := range dates {
+			attrs = append(attrs, kmip.Attribute{AttributeName: name, AttributeValue: date})
+		}
+		return &ScannedObject{ID: id, Attributes: &payloads.GetAttributesResponsePayload{UniqueIdentifier: id, Attribute: attrs}}
+	}
+	cert := object("cert", kmip.ObjectTypeCertificate, kmip.StateActive, map[kmip.AttributeName]time.Time{
+		kmip.AttributeNameDeactivationDate: now.Add(200 * day),
+	})
+	// The certificate expires before its Deactivation Date.
+	cert.NotAfter = now.Add(10 * day)
+	objs := []*ScannedObject{
+		object("fresh", kmip.ObjectTypeSymmetricKey, kmip.StateActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameInitialDate:      now.Add(-10 * day),
+			kmip.AttributeNameDeactivationDate: now.Add(100 * day),
+		}),
+		object("old", kmip.ObjectTypeSymmetricKey, kmip.StateActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameInitialDate: now.Add(-400 * day),
+		}),
+		// Secrets aren't rotated like keys.
+		object("old-secret", kmip.ObjectTypeSecretData, kmip.StateActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameInitialDate: now.Add(-400 * day),
+		}),
+		object("soon", kmip.ObjectTypeSymmetricKey, kmip.StatePreActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameDeactivationDate: now.Add(20 * day),
+		}),
+		cert,
+		// Past its Deactivation Date and old: reported as overdue only.
+		object("late", kmip.ObjectTypePrivateKey, kmip.StateActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameInitialDate:      now.Add(-400 * day),
+			kmip.AttributeNameDeactivationDate: now.Add(-2 * day),
+		}),
+		object("later", kmip.ObjectTypeSymmetricKey, kmip.StateActive, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameDeactivationDate: now.Add(-1 * day),
+		}),
+		// Deactivated as planned.
+		object("done", kmip.ObjectTypeSymmetricKey, kmip.StateDeactivated, map[kmip.AttributeName]time.Time{
+			kmip.AttributeNameDeactivationDate: now.Add(-5 * day),
+		}),
+		{ID: "broken", Err: errors.New("boom")},
+	}
+
+	findings := assessAll(objs, now, 30*day, 365*day)
+	want := []struct {
+		id       string
+		severity Severity
+	}{
+		{"late", SeverityOverdue},
+		{"later", SeverityOverdue},
+		{"cert", SeverityExpiring},
+		{"soon", SeverityExpiring},
+		{"old", SeverityStale},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("%d findings, want %d: %v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		if findings[i].obj.ID != w.id || findings[i].severity != w.severity {
+			t.Fatalf("finding %d = %s (%d), want %s (%d)", i, findings[i].obj.ID, findings[i].severity, w.id, w.severity)
+		}
+	}
+	if !findings[2].date.Equal(cert.NotAfter) || findings[2].reason != "Certificate expiry in 10d" {
+		t.Fatalf("certificate finding = %v, %q", findings[2].date, findings[2].reason)
+	}
+
+	// Without a rotation age, old keys aren't reported.
+	if findings := assessAll(objs, now, 30*day, 0); len(findings) != 4 {
+		t.Fatalf("%d findings without rotation age, want 4", len(findings))
+	}
+}
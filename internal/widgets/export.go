@@ -0,0 +1,119 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ovh/kmip-go/payloads"
+)
+
+// ExportFormats are the formats WriteExport supports.
+var ExportFormats = []string{"JSON", "CSV"}
+
+// WriteExport writes objects with all their attributes in one of
+// ExportFormats:
+//   - JSON: a list of objects, each with its "id" and the list of its
+//     "attributes", with their "name", "index" (if any) and "value".
+//   - CSV: one line per object, with an "ID" column followed by a column per
+//     attribute name found in any object. The values of a multi-valued
+//     attribute are separated by "; ".
+//
+// Values are rendered on a single line, as in the table, except dates which
+// are in RFC 3339 format, in UTC.
+func WriteExport(w io.Writer, format string, objects []*payloads.GetAttributesResponsePayload) error {
+	switch format {
+	case "JSON":
+		return writeJSONExport(w, objects)
+	case "CSV":
+		return writeCSVExport(w, objects)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+type exportedAttribute struct {
+	Name  string `json:"name"`
+	Index *int32 `json:"index,omitempty"`
+	Value string `json:"value"`
+}
+
+type exportedObject struct {
+	ID         string              `json:"id"`
+	Attributes []exportedAttribute `json:"attributes"`
+}
+
+func writeJSONExport(w io.Writer, objects []*payloads.GetAttributesResponsePayload) error {
+	out := make([]exportedObject, len(objects))
+	for i, obj := range objects {
+		out[i] = exportedObject{ID: obj.UniqueIdentifier, Attributes: make([]exportedAttribute, len(obj.Attribute))}
+		for j, attr := range obj.Attribute {
+			out[i].Attributes[j] = exportedAttribute{
+				Name:  string(attr.AttributeName),
+				Index: attr.AttributeIndex,
+				Value: exportValue(attr.AttributeValue),
+			}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeCSVExport(w io.Writer, objects []*payloads.GetAttributesResponsePayload) error {
+	// Attribute columns, in the order they are first met.
+	var names []string
+	for _, obj := range objects {
+		for _, attr := range obj.Attribute {
+			if name := string(attr.AttributeName); !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"ID"}, names...)); err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		values := make([][]string, len(names))
+		for _, attr := range obj.Attribute {
+			i := slices.Index(names, string(attr.AttributeName))
+			values[i] = append(values[i], exportValue(attr.AttributeValue))
+		}
+		record := make([]string, len(names)+1)
+		record[0] = obj.UniqueIdentifier
+		for i, v := range values {
+			record[i+1] = strings.Join(v, "; ")
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportValue renders an attribute value for an export.
+func exportValue(value any) string {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return AttributeText(value)
+}
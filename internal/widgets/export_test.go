@@ -0,0 +1,47 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestWriteCSVExport(t *testing.T) {
+	date := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	objects := []*payloads.GetAttributesResponsePayload{
+		{UniqueIdentifier: "a", Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "first"}},
+			{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "other, name"}},
+		}},
+		{UniqueIdentifier: "b", Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameInitialDate, AttributeValue: date},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, "CSV", objects); err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+	want := "ID,Name,Initial Date\n" +
+		"a,\"first; other, name\",\n" +
+		"b,,2025-01-31T12:00:00Z\n"
+	if buf.String() != want {
+		t.Fatalf("CSV export =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
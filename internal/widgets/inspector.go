@@ -0,0 +1,152 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phsym/kmip-explorer/internal/inspect"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Inspector is the protocol inspector pane: the latest exchanges with the
+// server, newest last, and the request and response messages of the selected
+// one. <f> cycles through the encodings of the messages, <tab> moves between
+// the list and the messages, and <esc> or <shift+i> closes the pane.
+type Inspector struct {
+	*tview.Flex
+	list      *tview.Table
+	messages  *tview.TextView
+	recorder  *inspect.Recorder
+	exchanges []*inspect.Exchange
+	format    int
+	setFocus  func(tview.Primitive)
+	onClose   func()
+}
+
+// NewInspector builds the pane showing the exchanges of recorder. setFocus
+// gives the focus to a part of the pane.
+func NewInspector(recorder *inspect.Recorder, setFocus func(tview.Primitive)) *Inspector {
+	in := &Inspector{recorder: recorder, setFocus: setFocus}
+	in.list = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0).
+		SetSelectionChangedFunc(func(row, _ int) { in.showMessages(row) })
+	in.list.SetBorder(true).SetTitle("Exchanges")
+	in.messages = tview.NewTextView().SetDynamicColors(true)
+	in.messages.SetBorder(true)
+	in.Flex = tview.NewFlex().
+		AddItem(in.list, 0, 2, true).
+		AddItem(in.messages, 0, 3, false)
+	in.Flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Rune() == 'I':
+			if in.onClose != nil {
+				in.onClose()
+			}
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if in.list.HasFocus() {
+				in.setFocus(in.messages)
+			} else {
+				in.setFocus(in.list)
+			}
+		case event.Rune() == 'f':
+			in.format = (in.format + 1) % len(inspect.Formats)
+			row, _ := in.list.GetSelection()
+			in.showMessages(row)
+		default:
+			return event
+		}
+		return nil
+	})
+	in.Refresh()
+	return in
+}
+
+// OnClose sets the function called when the user closes the pane.
+func (in *Inspector) OnClose(cb func()) *Inspector {
+	in.onClose = cb
+	return in
+}
+
+// Refresh lists the exchanges recorded so far. The selection stays on the same
+// exchange, or follows the newest one if it was selected.
+func (in *Inspector) Refresh() {
+	row, _ := in.list.GetSelection()
+	selected, follow := 0, row >= len(in.exchanges)
+	if row >= 1 && row <= len(in.exchanges) {
+		selected = in.exchanges[row-1].ID
+	}
+	in.exchanges = in.recorder.Exchanges()
+
+	in.list.Clear()
+	for col, title := range []string{"#", "Time", "Operation", "Duration", "Result"} {
+		in.list.SetCell(0, col, tview.NewTableCell(title).SetSelectable(false).
+			SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold))
+	}
+	for i, e := range in.exchanges {
+		result := tview.NewTableCell("OK").SetTextColor(tcell.ColorGreen)
+		if failure := e.Failure(); failure != "" {
+			result = tview.NewTableCell(tview.Escape(failure)).SetTextColor(tcell.ColorRed)
+		}
+		in.list.SetCell(i+1, 0, tview.NewTableCell(strconv.Itoa(e.ID))).
+			SetCell(i+1, 1, tview.NewTableCell(e.Time.Local().Format("15:04:05.000"))).
+			SetCell(i+1, 2, tview.NewTableCell(tview.Escape(e.Operations()))).
+			SetCell(i+1, 3, tview.NewTableCell(e.Duration.Round(time.Millisecond).String()).SetAlign(tview.AlignRight)).
+			SetCell(i+1, 4, result.SetExpansion(1))
+	}
+	if follow || !in.Select(selected) {
+		in.list.Select(len(in.exchanges), 0)
+	}
+}
+
+// Select selects the exchange of the given ID, and reports whether it is still
+// kept.
+func (in *Inspector) Select(id int) bool {
+	for i, e := range in.exchanges {
+		if e.ID == id {
+			in.list.Select(i+1, 0)
+			return true
+		}
+	}
+	return false
+}
+
+func (in *Inspector) showMessages(row int) {
+	format := inspect.Formats[in.format]
+	in.messages.SetTitle(fmt.Sprintf("Messages (%s, <f> to change)", format))
+	if row < 1 || row > len(in.exchanges) {
+		in.messages.SetText("")
+		return
+	}
+	e := in.exchanges[row-1]
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow::b]Time:[-::-] %s\n", e.Time.Local().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "[yellow::b]Duration:[-::-] %s\n", e.Duration)
+	if ccv := e.CorrelationValue(); ccv != "" {
+		fmt.Fprintf(&b, "[yellow::b]Correlation value:[-::-] %s\n", tview.Escape(ccv))
+	}
+	if failure := e.Failure(); failure != "" {
+		fmt.Fprintf(&b, "[red::b]Failure:[-::-] %s\n", tview.Escape(failure))
+	}
+	fmt.Fprintf(&b, "\n[::b]--- Request ---[::-]\n%s\n", tview.Escape(inspect.Render(e.Request, format)))
+	if e.Response != nil {
+		fmt.Fprintf(&b, "\n[::b]--- Response ---[::-]\n%s\n", tview.Escape(inspect.Render(e.Response, format)))
+	}
+	in.messages.SetText(b.String()).ScrollToBeginning()
+}
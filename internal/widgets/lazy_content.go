@@ -15,9 +15,11 @@
 package widgets
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,23 +30,27 @@ import (
 	"github.com/rivo/tview"
 )
 
-// mobColumns is the number of columns rendered for each managed object:
-// ID, Type, Name, Algorithm, Size, State, Age.
-const mobColumns = 7
+// prefetchRows is how close to the last loaded row the viewport may get before
+// the next Locate page is requested, so scrolling rarely has to wait on it.
+const prefetchRows = 20
 
-// ageCol is the index of the "Age" column. Its text is recomputed on every draw
-// from the row's cached InitialDate (see GetCell) so it doesn't freeze at the
-// value it had when the row was first loaded.
-const ageCol = mobColumns - 1
+// Pager fetches the page of object ids starting at offset, for tables listing
+// more objects than one Locate response carries. total is the number of objects
+// matching on the server, or -1 when the server doesn't report it. It is called
+// from a background goroutine.
+type Pager func(offset int) (ids []string, total int, err error)
 
 // loadedRow holds everything cached for a fully loaded row, all written and
 // cleared as a unit: the raw details (used by the attributes panel), the
-// precomputed table cells, and the InitialDate used to recompute the
-// time-relative Age column on each draw (zero = unknown).
+// precomputed table cells and the keys they sort on (one per column), the
+// InitialDate used to recompute the time-relative Age column on each draw
+// (zero = unknown), and the lowercased text the search filter matches against.
 type loadedRow struct {
 	payload *payloads.GetAttributesResponsePayload
-	cells   [mobColumns]*tview.TableCell
+	cells   []*tview.TableCell
+	keys    []any
 	date    time.Time
+	search  string
 }
 
 // lazyContent implements tview.TableContent. It holds the ordered list of object
@@ -58,13 +64,22 @@ type lazyContent struct {
 	tview.TableContentReadOnly
 
 	mu           sync.Mutex
-	ids          []string                                // row r (1-based) -> ids[r-1]; header is row 0
-	loaded       map[string]*loadedRow                   // fully loaded rows: details + precomputed cells + InitialDate
-	placeholders map[string][mobColumns]*tview.TableCell // cached "…"/"!" cells for not-yet-loaded rows, parallel to loaded
-	inflight     map[string]struct{}                     // currently being fetched (dedup)
-	failed       map[string]error                        // load error per id (absent = not failed); cleared on refresh
-	queue        []string                                // ids to load for the current viewport, top-to-bottom
-	gen          uint64                                  // bumped on setIDs; stale results are discarded
+	all          []string                      // every id of the current row set, in Locate order
+	ids          []string                      // visible rows (all, narrowed by filter): row r (1-based) -> ids[r-1]; header is row 0
+	filter       string                        // lowercased search query; empty shows every row
+	sortCol      int                           // column the rows are sorted on, -1 for Locate order
+	sortDesc     bool                          // sort in descending order
+	backlog      []string                      // unloaded ids the filter or sort still need details for, fetched after the viewport
+	loaded       map[string]*loadedRow         // fully loaded rows: details + precomputed cells + InitialDate
+	placeholders map[string][]*tview.TableCell // cached "…"/"!" cells for not-yet-loaded rows, parallel to loaded
+	inflight     map[string]struct{}           // currently being fetched (dedup)
+	failed       map[string]error              // load error per id (absent = not failed); cleared on refresh
+	queue        []string                      // ids to load for the current viewport, top-to-bottom
+	gen          uint64                        // bumped on setIDs; stale results are discarded
+	columns      []column                      // the displayed columns, in order
+	layout       uint64                        // bumped on setColumns; loads built for older columns are discarded
+	marked       map[string]struct{}           // rows marked for a bulk operation
+	revealed     map[string]struct{}           // ids in all only through reveal, not part of the listing; dropped by setIDs
 
 	// frame collects the ids drawn during the current frame, in top-to-bottom
 	// order. endFrame turns it into the work queue, so the loader only fetches
@@ -77,24 +92,56 @@ type lazyContent struct {
 	loader        func(id string) (*payloads.GetAttributesResponsePayload, error)
 	requestRedraw func() // coalesced redraw, set by MobTable
 
-	header [mobColumns]*tview.TableCell
+	// Paging state, set by setPage. The next page is requested when the
+	// viewport gets within prefetchRows of the end of the loaded rows.
+	pager    Pager
+	fetched  int   // ids received from the pager so far: the next page's offset
+	total    int   // server-side total, -1 when unknown
+	more     bool  // another page may exist
+	paging   bool  // a page fetch is in flight
+	pageErr  error // last page fetch failure; paging stops until the next setPage
+	wantMore bool  // the frame being drawn reached the prefetch zone
+
+	header      []*tview.TableCell
+	loadingMore []*tview.TableCell
 }
 
 func newLazyContent(loader func(id string) (*payloads.GetAttributesResponsePayload, error)) *lazyContent {
 	c := &lazyContent{
 		loaded:       map[string]*loadedRow{},
-		placeholders: map[string][mobColumns]*tview.TableCell{},
+		placeholders: map[string][]*tview.TableCell{},
 		inflight:     map[string]struct{}{},
 		failed:       map[string]error{},
 		frameSet:     map[string]struct{}{},
+		marked:       map[string]struct{}{},
+		revealed:     map[string]struct{}{},
 		wake:         make(chan struct{}, 1),
 		loader:       loader,
+		sortCol:      -1,
 	}
+	c.columns = resolveColumns(nil)
+	c.buildFixedCellsLocked()
+	return c
+}
+
+// buildFixedCellsLocked (re)builds the header and "loading more…" cells for the
+// current columns, marking the sorted column's header with its direction.
+// Caller holds c.mu (or has exclusive access during construction).
+func (c *lazyContent) buildFixedCellsLocked() {
 	hdrStyle := tcell.StyleDefault.Bold(true)
-	for i, txt := range []string{"ID", "Type", "Name", "Algorithm", "Size", "State", "Age"} {
+	c.header = make([]*tview.TableCell, len(c.columns))
+	c.loadingMore = make([]*tview.TableCell, len(c.columns))
+	for i, col := range c.columns {
+		txt := col.name
+		switch {
+		case i == c.sortCol && c.sortDesc:
+			txt += " ▼"
+		case i == c.sortCol:
+			txt += " ▲"
+		}
 		c.header[i] = newStyledCell(txt, hdrStyle)
+		c.loadingMore[i] = newStyledCell("", tcell.StyleDefault.Foreground(tcell.ColorDarkGray)).SetSelectable(false)
 	}
-	return c
 }
 
 // --- tview.TableContent ---
@@ -102,21 +149,34 @@ func newLazyContent(loader func(id string) (*payloads.GetAttributesResponsePaylo
 func (c *lazyContent) GetRowCount() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.more || c.pageErr != nil {
+		return len(c.ids) + 2 // trailing "loading more…" (or failure) row
+	}
 	return len(c.ids) + 1
 }
 
-func (c *lazyContent) GetColumnCount() int { return mobColumns }
+func (c *lazyContent) GetColumnCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.columns)
+}
 
 func (c *lazyContent) GetCell(row, column int) *tview.TableCell {
-	if column < 0 || column >= mobColumns {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if column < 0 || column >= len(c.columns) {
 		return nil
 	}
 	if row == 0 {
 		return c.header[column]
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
 	i := row - 1
+	if c.framing && c.more && i >= len(c.ids)-prefetchRows {
+		c.wantMore = true
+	}
+	if i == len(c.ids) && (c.more || c.pageErr != nil) {
+		return c.loadingMoreCellLocked(column)
+	}
 	if i < 0 || i >= len(c.ids) {
 		return nil
 	}
@@ -124,10 +184,10 @@ func (c *lazyContent) GetCell(row, column int) *tview.TableCell {
 	if lr, ok := c.loaded[id]; ok {
 		// Age is time-relative, so refresh it from the cached InitialDate on every
 		// draw rather than serving the value frozen at load time.
-		if column == ageCol && !lr.date.IsZero() {
-			lr.cells[ageCol].SetText(formatAge(time.Since(lr.date)))
+		if c.columns[column].isAge && !lr.date.IsZero() {
+			lr.cells[column].SetText(formatAge(time.Since(lr.date)))
 		}
-		return lr.cells[column]
+		return c.markLocked(id, lr.cells[column])
 	}
 	_, failed := c.failed[id]
 	if !failed {
@@ -140,29 +200,153 @@ func (c *lazyContent) GetCell(row, column int) *tview.TableCell {
 	// "!"), remove, and setIDs.
 	ph, ok := c.placeholders[id]
 	if !ok {
-		ph = buildPlaceholderCells(id, failed)
+		ph = buildPlaceholderCells(id, failed, c.columns)
 		c.placeholders[id] = ph
 	}
-	return ph[column]
+	return c.markLocked(id, ph[column])
 }
 
 // --- model mutation (all called from the UI goroutine) ---
 
-// setIDs replaces the row set and invalidates everything else. Bumping gen makes
-// any in-flight load from the previous set discard its result on completion.
+// setIDs replaces the row set and invalidates everything else except the
+// filter, which carries over to the new rows. Bumping gen makes any in-flight
+// load from the previous set discard its result on completion.
 func (c *lazyContent) setIDs(ids []string) {
 	c.mu.Lock()
-	c.ids = ids
+	c.all = ids
+	c.revealed = map[string]struct{}{}
 	c.gen++
+	c.pager = nil
+	c.fetched = len(ids)
+	c.total = -1
+	c.more = false
+	c.paging = false
+	c.pageErr = nil
+	c.wantMore = false
 	c.loaded = map[string]*loadedRow{}
-	c.placeholders = map[string][mobColumns]*tview.TableCell{}
+	c.placeholders = map[string][]*tview.TableCell{}
 	c.inflight = map[string]struct{}{}
 	c.failed = map[string]error{}
 	c.queue = nil
 	c.frame = nil
 	c.frameSet = map[string]struct{}{}
 	c.framing = false
+	if len(c.marked) > 0 {
+		// Marks follow the objects still listed.
+		keep := make(map[string]struct{}, len(c.marked))
+		for _, id := range ids {
+			if _, ok := c.marked[id]; ok {
+				keep[id] = struct{}{}
+			}
+		}
+		c.marked = keep
+	}
+	c.rebuildViewLocked()
+	c.refillBacklogLocked()
+	hasWork := len(c.backlog) > 0
 	c.mu.Unlock()
+	if hasWork {
+		c.signal()
+	}
+}
+
+// setFilter narrows the visible rows to those whose ID, Name, Algorithm or
+// State contains query (case-insensitive). Rows whose details aren't loaded yet
+// can only be matched on their ID, so they are queued for a background fetch
+// and show up as soon as their details prove a match.
+func (c *lazyContent) setFilter(query string) {
+	c.mu.Lock()
+	c.filter = strings.ToLower(strings.TrimSpace(query))
+	c.rebuildViewLocked()
+	c.refillBacklogLocked()
+	hasWork := len(c.backlog) > 0
+	c.mu.Unlock()
+	if hasWork {
+		c.signal()
+	}
+}
+
+// reveal adds id at the top of the row set when it isn't part of it, e.g. an
+// object of another type than those listed, until the next setIDs. The filter
+// still applies to it, but it is left out of mark-all and of exports.
+func (c *lazyContent) reveal(id string) {
+	c.mu.Lock()
+	if slices.Contains(c.all, id) {
+		c.mu.Unlock()
+		return
+	}
+	c.all = slices.Insert(c.all, 0, id)
+	c.revealed[id] = struct{}{}
+	c.rebuildViewLocked()
+	c.refillBacklogLocked()
+	hasWork := len(c.backlog) > 0
+	c.mu.Unlock()
+	if hasWork {
+		c.signal()
+	}
+}
+
+// indexOf returns the visible row index (0-based, excluding the header) of id,
+// or -1 when it isn't visible.
+func (c *lazyContent) indexOf(id string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Index(c.ids, id)
+}
+
+// rebuildViewLocked recomputes the visible rows from the full row set, the
+// current filter and the sort order. Caller holds c.mu.
+func (c *lazyContent) rebuildViewLocked() {
+	var ids []string
+	if c.filter == "" {
+		ids = slices.Clone(c.all)
+	} else {
+		ids = make([]string, 0, len(c.all))
+		for _, id := range c.all {
+			if c.matchesLocked(id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if c.sortCol >= 0 {
+		c.sortLocked(ids)
+	}
+	c.ids = ids
+}
+
+// viewIsDynamicLocked reports whether the visible rows depend on loaded details,
+// and must therefore be recomputed whenever a row loads. Caller holds c.mu.
+func (c *lazyContent) viewIsDynamicLocked() bool {
+	return c.filter != "" || c.sortCol >= 0
+}
+
+// matchesLocked reports whether id passes the filter. Unloaded (or failed) rows
+// only expose their ID, so they match on it alone until their details arrive.
+// Caller holds c.mu.
+func (c *lazyContent) matchesLocked(id string) bool {
+	if lr, ok := c.loaded[id]; ok {
+		return strings.Contains(lr.search, c.filter)
+	}
+	return strings.Contains(strings.ToLower(id), c.filter)
+}
+
+// refillBacklogLocked lists the rows the filter or the sort can't place yet:
+// unloaded and not failed (and, when only filtering, not already matching on
+// their ID alone). The loader works through them once the viewport queue is
+// drained. Caller holds c.mu.
+func (c *lazyContent) refillBacklogLocked() {
+	c.backlog = nil
+	if !c.viewIsDynamicLocked() {
+		return
+	}
+	for _, id := range c.all {
+		if !c.needsLoadLocked(id) {
+			continue
+		}
+		if c.sortCol >= 0 || !c.matchesLocked(id) {
+			c.backlog = append(c.backlog, id)
+		}
+	}
 }
 
 // put overwrites the cached details for an id already present in the row set.
@@ -171,21 +355,28 @@ func (c *lazyContent) put(o *payloads.GetAttributesResponsePayload) {
 		return
 	}
 	// Build the cells before locking, matching loadOne, so buildRowCells never
-	// runs while c.mu is held (see storeLocked).
-	cells, date := buildRowCells(o)
+	// runs while c.mu is held (see storeLocked). put and setColumns both run on
+	// the UI goroutine, so the columns can't change in between.
+	cells, keys, fields := buildRowCells(o, c.columnsSnapshot())
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if !slices.Contains(c.ids, o.UniqueIdentifier) {
+	if !slices.Contains(c.all, o.UniqueIdentifier) {
 		return
 	}
-	c.storeLocked(o, cells, date)
+	c.storeLocked(o, cells, keys, fields)
 	delete(c.failed, o.UniqueIdentifier)
 	delete(c.inflight, o.UniqueIdentifier)
+	if c.viewIsDynamicLocked() {
+		c.rebuildViewLocked()
+	}
 }
 
 func (c *lazyContent) remove(id string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if i := slices.Index(c.all, id); i >= 0 {
+		c.all = slices.Delete(c.all, i, i+1)
+	}
 	if i := slices.Index(c.ids, id); i >= 0 {
 		c.ids = slices.Delete(c.ids, i, i+1)
 	}
@@ -193,10 +384,13 @@ func (c *lazyContent) remove(id string) {
 	// longer exists. A load already in flight is handled by loadOne's in-flight
 	// check (remove clears the marker below, so its result is dropped).
 	c.queue = slices.DeleteFunc(c.queue, func(q string) bool { return q == id })
+	c.backlog = slices.DeleteFunc(c.backlog, func(q string) bool { return q == id })
 	delete(c.loaded, id)
 	delete(c.placeholders, id)
 	delete(c.failed, id)
 	delete(c.inflight, id)
+	delete(c.marked, id)
+	delete(c.revealed, id)
 }
 
 // payloadForRow returns the cached details for a data row, or a stub carrying just
@@ -228,6 +422,430 @@ func (c *lazyContent) loadErr(i int) error {
 	return c.failed[c.ids[i]]
 }
 
+// --- sorting ---
+
+// setSort orders the rows on column, ascending or descending, or restores the
+// Locate order when column is -1. Rows whose details aren't loaded yet can't be
+// placed, so they are listed last (in Locate order) and fetched in the
+// background, moving into place as they load. The header of the sorted column
+// shows the direction.
+func (c *lazyContent) setSort(column int, desc bool) {
+	c.mu.Lock()
+	if column < -1 || column >= len(c.columns) {
+		column = -1
+	}
+	c.sortCol, c.sortDesc = column, desc
+	c.buildFixedCellsLocked()
+	c.rebuildViewLocked()
+	c.refillBacklogLocked()
+	hasWork := len(c.backlog) > 0
+	c.mu.Unlock()
+	if hasWork {
+		c.signal()
+	}
+}
+
+// sorting returns the current sort column (-1 for Locate order) and direction.
+func (c *lazyContent) sorting() (column int, desc bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sortCol, c.sortDesc
+}
+
+// sortLocked sorts ids in place on the current sort column. The sort is stable,
+// so ties (and the unloaded rows gathered at the end) keep their Locate order.
+// Caller holds c.mu.
+func (c *lazyContent) sortLocked(ids []string) {
+	slices.SortStableFunc(ids, func(a, b string) int {
+		ra, aok := c.loaded[a]
+		rb, bok := c.loaded[b]
+		switch {
+		case !aok && !bok:
+			return 0
+		case !aok:
+			return 1
+		case !bok:
+			return -1
+		}
+		cmp := compareKeys(ra.keys[c.sortCol], rb.keys[c.sortCol])
+		if c.sortDesc {
+			return -cmp
+		}
+		return cmp
+	})
+}
+
+// --- columns ---
+
+// columnsSnapshot returns the current columns. The slice is never modified in
+// place (setColumns replaces it), so it can be used off-lock.
+func (c *lazyContent) columnsSnapshot() []column {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.columns
+}
+
+// columnNames returns the names of the displayed columns, in order.
+func (c *lazyContent) columnNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// setColumns changes the displayed columns (DefaultColumns when names is
+// empty). The cells of the rows already loaded are rebuilt from their cached
+// details, so nothing is fetched again; loads in flight for the old layout are
+// dropped and retried when their row is next drawn. The sort follows its column
+// if it is still displayed.
+func (c *lazyContent) setColumns(names []string) {
+	cols := resolveColumns(names)
+	c.mu.Lock()
+	sortName := ""
+	if c.sortCol >= 0 {
+		sortName = c.columns[c.sortCol].name
+	}
+	c.columns = cols
+	c.layout++
+	c.sortCol = slices.IndexFunc(cols, func(col column) bool { return sortName != "" && strings.EqualFold(col.name, sortName) })
+	c.buildFixedCellsLocked()
+	c.placeholders = map[string][]*tview.TableCell{}
+	rows := make(map[string]*payloads.GetAttributesResponsePayload, len(c.loaded))
+	for id, lr := range c.loaded {
+		rows[id] = lr.payload
+	}
+	c.mu.Unlock()
+
+	// Rebuild off-lock, like every other buildRowCells call (see storeLocked).
+	type rebuilt struct {
+		cells []*tview.TableCell
+		keys  []any
+	}
+	out := make(map[string]rebuilt, len(rows))
+	for id, v := range rows {
+		cells, keys, _ := buildRowCells(v, cols)
+		out[id] = rebuilt{cells, keys}
+	}
+
+	c.mu.Lock()
+	for id, r := range out {
+		// Skip rows replaced (put) or dropped (remove/setIDs) meanwhile.
+		if lr, ok := c.loaded[id]; ok && lr.payload == rows[id] {
+			lr.cells, lr.keys = r.cells, r.keys
+		}
+	}
+	c.rebuildViewLocked()
+	c.mu.Unlock()
+}
+
+// --- paging ---
+
+// setPage replaces the row set with the first page of a paged listing. Further
+// pages are fetched through pager as the viewport nears the end of the loaded
+// rows. total is the server-side total, -1 when unknown.
+func (c *lazyContent) setPage(ids []string, total int, pager Pager) {
+	c.setIDs(ids)
+	c.mu.Lock()
+	c.pager = pager
+	c.total = total
+	c.more = hasMorePages(len(ids), len(ids), total)
+	c.mu.Unlock()
+}
+
+// hasMorePages reports whether another page may follow, given how many ids have
+// been fetched in total, how many new ones the last page brought, and the
+// server-side total (-1 when unknown, in which case only an empty page ends the
+// listing).
+func hasMorePages(fetched, added, total int) bool {
+	if added == 0 {
+		return false
+	}
+	if total >= 0 {
+		return fetched < total
+	}
+	return true
+}
+
+// counts returns the number of visible rows, and the server-side total when more
+// pages are yet to be fetched (-1 otherwise, or when the server didn't tell).
+func (c *lazyContent) counts() (visible, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.more {
+		return len(c.ids), -1
+	}
+	return len(c.ids), c.total
+}
+
+// hasMore reports whether more pages remain to be fetched.
+func (c *lazyContent) hasMore() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.more
+}
+
+// loadingMoreCellLocked renders the trailing row that stands for the pages not
+// fetched yet, or for the failure that stopped paging. Caller holds c.mu.
+func (c *lazyContent) loadingMoreCellLocked(column int) *tview.TableCell {
+	cell := c.loadingMore[column]
+	if column != 0 {
+		return cell
+	}
+	if c.pageErr != nil {
+		return cell.SetText("! failed to load more: " + c.pageErr.Error()).SetTextColor(tcell.ColorIndianRed)
+	}
+	return cell.SetText("loading more…").SetTextColor(tcell.ColorDarkGray)
+}
+
+// fetchNextPageLocked requests the next page in the background unless one is
+// already in flight or the listing is complete. Caller holds c.mu.
+func (c *lazyContent) fetchNextPageLocked() {
+	if c.paging || !c.more || c.pager == nil {
+		return
+	}
+	c.paging = true
+	go c.fetchPage(c.pager, c.fetched, c.gen)
+}
+
+// fetchPage fetches one page and appends its ids, unless setIDs/setPage replaced
+// the row set meanwhile. Ids already listed are skipped: servers that ignore the
+// offset would otherwise repeat the first page forever, so a page bringing
+// nothing new ends the listing.
+func (c *lazyContent) fetchPage(pager Pager, offset int, gen uint64) {
+	ids, total, err := pager(offset)
+	c.mu.Lock()
+	if gen != c.gen {
+		c.mu.Unlock()
+		return
+	}
+	c.paging = false
+	if err != nil {
+		c.pageErr = err
+		c.more = false
+	} else {
+		known := make(map[string]struct{}, len(c.all))
+		for _, id := range c.all {
+			known[id] = struct{}{}
+		}
+		added := 0
+		for _, id := range ids {
+			if _, dup := known[id]; dup {
+				// A revealed id the listing brings is part of it.
+				delete(c.revealed, id)
+				continue
+			}
+			known[id] = struct{}{}
+			c.all = append(c.all, id)
+			added++
+		}
+		c.fetched += len(ids)
+		if total >= 0 {
+			c.total = total
+		}
+		c.more = hasMorePages(c.fetched, added, c.total)
+		c.rebuildViewLocked()
+		c.refillBacklogLocked()
+	}
+	hasWork := len(c.backlog) > 0
+	c.mu.Unlock()
+	if hasWork {
+		c.signal()
+	}
+	if c.requestRedraw != nil {
+		c.requestRedraw()
+	}
+}
+
+// --- marks ---
+
+// markedRowColor is the background of the rows marked for a bulk operation.
+const markedRowColor = tcell.ColorDarkSlateGray
+
+// markLocked returns the cell as shown on row id: when the row is marked, a
+// copy with the marked background, leaving the cached cell untouched. Caller
+// holds c.mu.
+func (c *lazyContent) markLocked(id string, cell *tview.TableCell) *tview.TableCell {
+	if _, ok := c.marked[id]; !ok {
+		return cell
+	}
+	marked := *cell
+	marked.Style = cell.Style.Background(markedRowColor)
+	marked.SelectedStyle = cell.SelectedStyle.Foreground(markedRowColor)
+	return &marked
+}
+
+// toggleMark marks visible row i, or unmarks it if it was.
+func (c *lazyContent) toggleMark(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i < 0 || i >= len(c.ids) {
+		return
+	}
+	if _, ok := c.marked[c.ids[i]]; ok {
+		delete(c.marked, c.ids[i])
+		return
+	}
+	c.marked[c.ids[i]] = struct{}{}
+}
+
+// setMarks replaces the marks with ids, plus the visible rows from i to j
+// (in either order) when i is not negative.
+func (c *lazyContent) setMarks(ids []string, i, j int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marked = make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		c.marked[id] = struct{}{}
+	}
+	if i < 0 {
+		return
+	}
+	i, j = min(i, j), max(i, j)
+	for k := max(i, 0); k <= j && k < len(c.ids); k++ {
+		c.marked[c.ids[k]] = struct{}{}
+	}
+}
+
+// toggleMarkAll marks every visible row of the listing, or unmarks them all if
+// they already are. Revealed rows are left as they are.
+func (c *lazyContent) toggleMarkAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := slices.DeleteFunc(slices.Clone(c.ids), func(id string) bool {
+		_, ok := c.revealed[id]
+		return ok
+	})
+	all := true
+	for _, id := range ids {
+		if _, ok := c.marked[id]; !ok {
+			all = false
+			break
+		}
+	}
+	for _, id := range ids {
+		if all {
+			delete(c.marked, id)
+		} else {
+			c.marked[id] = struct{}{}
+		}
+	}
+}
+
+// markedIDs returns the ids of the marked rows which are visible, in display
+// order: rows hidden by the filter are left out.
+func (c *lazyContent) markedIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ids []string
+	for _, id := range c.ids {
+		if _, ok := c.marked[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// --- export ---
+
+// export returns the details of every row of the listing, in the displayed
+// order and narrowed by the filter, for the listing as it is when called:
+// revealed rows are left out. The
+// pages not fetched yet and the details not loaded yet are fetched first,
+// reporting progress with the number of rows fetched out of those known so far.
+// It runs off the UI goroutine, and leaves the table untouched: paging and
+// loading go on for the rows on screen meanwhile.
+func (c *lazyContent) export(ctx context.Context, progress func(done, total int)) ([]*payloads.GetAttributesResponsePayload, error) {
+	c.mu.Lock()
+	all := slices.DeleteFunc(slices.Clone(c.all), func(id string) bool {
+		_, ok := c.revealed[id]
+		return ok
+	})
+	pager, fetched, total, more := c.pager, c.fetched, c.total, c.more
+	filter, sortCol, sortDesc, cols := c.filter, c.sortCol, c.sortDesc, c.columns
+	known := make(map[string]*payloads.GetAttributesResponsePayload, len(all))
+	for _, id := range all {
+		known[id] = nil
+		if lr, ok := c.loaded[id]; ok {
+			known[id] = lr.payload
+		}
+	}
+	c.mu.Unlock()
+
+	// Remaining pages, skipping repeated ids like fetchPage.
+	for more && pager != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress(0, len(all))
+		ids, t, err := pager(fetched)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, id := range ids {
+			if _, dup := known[id]; dup {
+				continue
+			}
+			known[id] = nil
+			all = append(all, id)
+			added++
+		}
+		fetched += len(ids)
+		if t >= 0 {
+			total = t
+		}
+		more = hasMorePages(fetched, added, total)
+	}
+
+	type row struct {
+		v    *payloads.GetAttributesResponsePayload
+		keys []any
+	}
+	rows := make([]row, 0, len(all))
+	for i, id := range all {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress(i, len(all))
+		v := known[id]
+		if v == nil {
+			var err error
+			if v, err = c.loader(id); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		f := parseRowFields(v)
+		if filter != "" && !strings.Contains(f.searchText(id), filter) {
+			continue
+		}
+		r := row{v: v}
+		if sortCol >= 0 {
+			_, key := cols[sortCol].value(v, f)
+			r.keys = []any{key}
+		}
+		rows = append(rows, r)
+	}
+	progress(len(all), len(all))
+
+	if sortCol >= 0 {
+		slices.SortStableFunc(rows, func(a, b row) int {
+			cmp := compareKeys(a.keys[0], b.keys[0])
+			if sortDesc {
+				return -cmp
+			}
+			return cmp
+		})
+	}
+	objects := make([]*payloads.GetAttributesResponsePayload, len(rows))
+	for i, r := range rows {
+		objects[i] = r.v
+	}
+	return objects, nil
+}
+
 // --- loading ---
 
 // beginFrame starts collecting the ids drawn this frame. MobTable.Draw calls it
@@ -259,6 +877,10 @@ func (c *lazyContent) recordFrameLocked(id string) {
 func (c *lazyContent) endFrame() {
 	c.mu.Lock()
 	c.framing = false
+	if c.wantMore {
+		c.wantMore = false
+		c.fetchNextPageLocked()
+	}
 	c.queue = make([]string, 0, len(c.frame))
 	for _, id := range c.frame {
 		if c.needsLoadLocked(id) {
@@ -268,10 +890,16 @@ func (c *lazyContent) endFrame() {
 	hasWork := len(c.queue) > 0
 	c.mu.Unlock()
 	if hasWork {
-		select {
-		case c.wake <- struct{}{}:
-		default:
-		}
+		c.signal()
+	}
+}
+
+// signal wakes the loader without blocking; a wake-up already pending covers
+// this one too.
+func (c *lazyContent) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
 	}
 }
 
@@ -293,11 +921,15 @@ func (c *lazyContent) needsLoadLocked(id string) bool {
 }
 
 // popLocked takes the next id from the front of the queue (the topmost on-screen
-// row). Caller holds c.mu.
+// row), falling back to the filter backlog once the viewport is served. Caller
+// holds c.mu.
 func (c *lazyContent) popLocked() (id string, gen uint64, ok bool) {
-	for len(c.queue) > 0 {
-		id = c.queue[0]
-		c.queue = c.queue[1:]
+	for len(c.queue) > 0 || len(c.backlog) > 0 {
+		if len(c.queue) > 0 {
+			id, c.queue = c.queue[0], c.queue[1:]
+		} else {
+			id, c.backlog = c.backlog[0], c.backlog[1:]
+		}
 		if !c.needsLoadLocked(id) {
 			continue
 		}
@@ -310,8 +942,8 @@ func (c *lazyContent) popLocked() (id string, gen uint64, ok bool) {
 // storeLocked caches an object's details together with its precomputed cells. The
 // cells are built by the caller so that buildRowCells (which could in theory panic
 // on a malformed payload) never runs while c.mu is held. Caller holds c.mu.
-func (c *lazyContent) storeLocked(v *payloads.GetAttributesResponsePayload, cells [mobColumns]*tview.TableCell, initialDate time.Time) {
-	c.loaded[v.UniqueIdentifier] = &loadedRow{payload: v, cells: cells, date: initialDate}
+func (c *lazyContent) storeLocked(v *payloads.GetAttributesResponsePayload, cells []*tview.TableCell, keys []any, fields rowFields) {
+	c.loaded[v.UniqueIdentifier] = &loadedRow{payload: v, cells: cells, keys: keys, date: fields.initialDate, search: fields.searchText(v.UniqueIdentifier)}
 	delete(c.placeholders, v.UniqueIdentifier) // now served from c.loaded
 }
 
@@ -342,33 +974,45 @@ func (c *lazyContent) startLoader() {
 // marks a row in-flight, so once we observe the marker cleared, nothing re-set it
 // underneath us.
 //
+// The cells are built for the columns displayed when the load started; if
+// setColumns changed them meanwhile, the result is dropped and the row is simply
+// fetched again the next time it is drawn.
+//
 // A panic (e.g. an unexpected attribute type) is contained: the row is marked
 // failed and the worker keeps serving the rest of the queue instead of taking the
 // whole app down. buildRowCells runs off-lock so the recover can never fire while
 // holding c.mu (which would deadlock completeLoad's re-lock).
 func (c *lazyContent) loadOne(id string, gen uint64) {
+	c.mu.Lock()
+	cols, layout := c.columns, c.layout
+	c.mu.Unlock()
 	defer func() {
 		if r := recover(); r != nil {
-			c.completeLoad(id, gen, nil, [mobColumns]*tview.TableCell{}, time.Time{}, fmt.Errorf("loading attributes panicked: %v", r))
+			c.completeLoad(id, gen, layout, nil, loadedCells{}, fmt.Errorf("loading attributes panicked: %v", r))
 		}
 	}()
 	v, err := c.loader(id)
-	var (
-		cells [mobColumns]*tview.TableCell
-		date  time.Time
-	)
+	var lc loadedCells
 	if err == nil {
-		cells, date = buildRowCells(v)
+		lc.cells, lc.keys, lc.fields = buildRowCells(v, cols)
 	}
-	c.completeLoad(id, gen, v, cells, date, err)
+	c.completeLoad(id, gen, layout, v, lc, err)
+}
+
+// loadedCells carries what buildRowCells produced for a load to completeLoad.
+type loadedCells struct {
+	cells  []*tview.TableCell
+	keys   []any
+	fields rowFields
 }
 
 // completeLoad finalizes one load attempt: it clears the in-flight marker and,
 // unless the load was superseded, applies the outcome — caching the details on
 // success or recording the error on failure — then requests a redraw. The normal
 // completion path and the panic-recovery path both funnel through here so they
-// clean up identically.
-func (c *lazyContent) completeLoad(id string, gen uint64, v *payloads.GetAttributesResponsePayload, cells [mobColumns]*tview.TableCell, date time.Time, err error) {
+// clean up identically. With a filter or a sort active the visible rows are
+// recomputed, since the outcome may reveal, hide or move the row.
+func (c *lazyContent) completeLoad(id string, gen, layout uint64, v *payloads.GetAttributesResponsePayload, lc loadedCells, err error) {
 	c.mu.Lock()
 	_, current := c.inflight[id]
 	delete(c.inflight, id)
@@ -379,8 +1023,14 @@ func (c *lazyContent) completeLoad(id string, gen uint64, v *payloads.GetAttribu
 	case err != nil:
 		c.failed[id] = err
 		delete(c.placeholders, id) // rebuild as the failed ("!") variant
+	case layout != c.layout:
+		// Built for columns no longer displayed: leave the row unloaded so the
+		// redraw below queues it again.
 	default:
-		c.storeLocked(v, cells, date)
+		c.storeLocked(v, lc.cells, lc.keys, lc.fields)
+	}
+	if !superseded && c.viewIsDynamicLocked() {
+		c.rebuildViewLocked()
 	}
 	c.mu.Unlock()
 	if !superseded && c.requestRedraw != nil {
@@ -397,27 +1047,29 @@ func newStyledCell(txt string, style tcell.Style) *tview.TableCell {
 	return tview.NewTableCell(txt).SetStyle(style).SetSelectedStyle(style.Reverse(true)).SetExpansion(1)
 }
 
-func placeholderCell(id string, column int, marker string, color tcell.Color) *tview.TableCell {
+func placeholderCell(id string, isID bool, marker string, color tcell.Color) *tview.TableCell {
 	txt := marker
 	style := tcell.StyleDefault.Foreground(color)
-	if column == 0 {
+	if isID {
 		txt = id
 		style = tcell.StyleDefault
 	}
 	return newStyledCell(txt, style)
 }
 
-// buildPlaceholderCells builds the seven cells shown for a row whose details
-// aren't available yet: "…" while the load is pending, "!" once it has failed.
-// The id column always shows the id so the row stays identifiable in either state.
-func buildPlaceholderCells(id string, failed bool) [mobColumns]*tview.TableCell {
+// buildPlaceholderCells builds the cells shown for a row whose details aren't
+// available yet: "…" while the load is pending, "!" once it has failed. The id
+// column (or the first one, if the id isn't displayed) always shows the id so
+// the row stays identifiable in either state.
+func buildPlaceholderCells(id string, failed bool, cols []column) []*tview.TableCell {
 	marker, color := "…", tcell.ColorDarkGray
 	if failed {
 		marker, color = "!", tcell.ColorIndianRed
 	}
-	var cells [mobColumns]*tview.TableCell
-	for col := range cells {
-		cells[col] = placeholderCell(id, col, marker, color)
+	idCol := max(slices.IndexFunc(cols, func(col column) bool { return col.isID }), 0)
+	cells := make([]*tview.TableCell, len(cols))
+	for i := range cells {
+		cells[i] = placeholderCell(id, i == idCol, marker, color)
 	}
 	return cells
 }
@@ -436,58 +1088,76 @@ func formatAge(d time.Duration) string {
 	}
 }
 
-// buildRowCells extracts the displayed attributes once and builds all seven cells.
-// It also returns the row's InitialDate (zero if absent) so the caller can refresh
-// the time-relative Age column on later draws instead of freezing it here.
-func buildRowCells(v *payloads.GetAttributesResponsePayload) ([mobColumns]*tview.TableCell, time.Time) {
-	var (
-		otype       kmip.ObjectType
-		size        string
-		state       kmip.State
-		name        string
-		alg         string
-		age         string
-		initialDate time.Time
-	)
-	// Type assertions use the comma-ok form throughout: a server returning an
-	// unexpected Go type for an attribute leaves that column blank rather than
-	// panicking the background loader (which would crash the whole app).
+// rowFields holds the attributes the table knows how to show, extracted once
+// from a GetAttributes payload.
+type rowFields struct {
+	otype       kmip.ObjectType
+	size        string
+	state       kmip.State
+	name        string
+	alg         string
+	initialDate time.Time
+}
+
+// parseRowFields extracts the displayed attributes from v. Type assertions use
+// the comma-ok form throughout: a server returning an unexpected Go type for an
+// attribute leaves that field blank rather than panicking the background loader
+// (which would crash the whole app).
+func parseRowFields(v *payloads.GetAttributesResponsePayload) rowFields {
+	var f rowFields
 	for _, attr := range v.Attribute {
 		switch attr.AttributeName {
 		case kmip.AttributeNameCryptographicLength:
 			if l, ok := attr.AttributeValue.(int32); ok {
-				size = strconv.Itoa(int(l))
+				f.size = strconv.Itoa(int(l))
 			}
 		case kmip.AttributeNameObjectType:
 			if t, ok := attr.AttributeValue.(kmip.ObjectType); ok {
-				otype = t
+				f.otype = t
 			}
 		case kmip.AttributeNameState:
 			if s, ok := attr.AttributeValue.(kmip.State); ok {
-				state = s
+				f.state = s
 			}
 		case kmip.AttributeNameName:
 			if attr.AttributeIndex != nil && *attr.AttributeIndex != 0 {
 				continue
 			}
 			if n, ok := attr.AttributeValue.(kmip.Name); ok {
-				name = n.NameValue
+				f.name = n.NameValue
 			}
 		case kmip.AttributeNameCryptographicAlgorithm:
 			if a, ok := attr.AttributeValue.(kmip.CryptographicAlgorithm); ok {
-				alg = ttlv.EnumStr(a)
+				f.alg = ttlv.EnumStr(a)
 			}
 		case kmip.AttributeNameInitialDate:
-			t, ok := attr.AttributeValue.(time.Time)
-			if !ok {
-				continue
+			if t, ok := attr.AttributeValue.(time.Time); ok {
+				f.initialDate = t
 			}
-			initialDate = t
-			age = formatAge(time.Since(t))
 		}
 	}
+	return f
+}
+
+// searchText is the lowercased text the search filter matches against: the
+// ID, Name, Algorithm and State, newline-separated so a query can't match
+// across two fields.
+func (f rowFields) searchText(id string) string {
+	var state string
+	if f.state != 0 {
+		state = ttlv.EnumStr(f.state)
+	}
+	return strings.ToLower(strings.Join([]string{id, f.name, f.alg, state}, "\n"))
+}
+
+// buildRowCells extracts the displayed attributes once and builds the cells of
+// the given columns, along with the key each one sorts on. It also returns the
+// extracted fields, whose InitialDate (zero if absent) lets the caller refresh the
+// time-relative Age column on later draws instead of freezing it here.
+func buildRowCells(v *payloads.GetAttributesResponsePayload, cols []column) ([]*tview.TableCell, []any, rowFields) {
+	f := parseRowFields(v)
 	style := tcell.StyleDefault
-	switch state {
+	switch f.state {
 	case kmip.StateActive:
 		style = style.Foreground(tcell.ColorBlue)
 	case kmip.StateDeactivated:
@@ -499,18 +1169,12 @@ func buildRowCells(v *payloads.GetAttributesResponsePayload) ([mobColumns]*tview
 	case kmip.StateDestroyedCompromised:
 		style = style.Foreground(tcell.ColorIndianRed).StrikeThrough(true)
 	}
-	values := [mobColumns]string{
-		v.UniqueIdentifier,
-		ttlv.EnumStr(otype),
-		name,
-		alg,
-		size,
-		ttlv.EnumStr(state),
-		age,
-	}
-	var cells [mobColumns]*tview.TableCell
-	for i, txt := range values {
+	cells := make([]*tview.TableCell, len(cols))
+	keys := make([]any, len(cols))
+	for i, col := range cols {
+		var txt string
+		txt, keys[i] = col.value(v, f)
 		cells[i] = newStyledCell(txt, style)
 	}
-	return cells, initialDate
+	return cells, keys, f
 }
@@ -15,7 +15,11 @@
 package widgets
 
 import (
+	"context"
 	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -79,7 +83,7 @@ func newTestContent(loader func(string) (*payloads.GetAttributesResponsePayload,
 func drawRows(c *lazyContent, rows ...int) {
 	c.beginFrame()
 	for _, r := range rows {
-		for col := range mobColumns {
+		for col := range c.GetColumnCount() {
 			c.GetCell(r, col)
 		}
 	}
@@ -123,8 +127,8 @@ func TestLazyContentLazyLoadAndCache(t *testing.T) {
 	l := newTestLoader()
 	c := newTestContent(l.load)
 
-	if got := c.GetColumnCount(); got != mobColumns {
-		t.Fatalf("GetColumnCount = %d, want %d", got, mobColumns)
+	if got := c.GetColumnCount(); got != len(DefaultColumns) {
+		t.Fatalf("GetColumnCount = %d, want %d", got, len(DefaultColumns))
 	}
 	c.setIDs([]string{"a", "b", "c"})
 	if got := c.GetRowCount(); got != 4 {
@@ -250,6 +254,49 @@ func TestLazyContentRemoveAndPut(t *testing.T) {
 	}
 }
 
+func TestLazyContentReveal(t *testing.T) {
+	l := newTestLoader()
+	c := newTestContent(l.load)
+	c.setIDs([]string{"a", "b"})
+
+	c.reveal("b")
+	if got := c.GetRowCount(); got != 3 {
+		t.Fatalf("GetRowCount after revealing a listed id = %d, want 3", got)
+	}
+	c.reveal("z")
+	if i := c.indexOf("z"); i != 0 {
+		t.Fatalf("indexOf(z) after reveal = %d, want 0", i)
+	}
+	// The filter still applies to the revealed row.
+	c.setFilter("a")
+	c.reveal("y")
+	if i := c.indexOf("y"); i != -1 {
+		t.Fatalf("indexOf(y) with a filter hiding it = %d, want -1", i)
+	}
+	c.setFilter("")
+	if i := c.indexOf("y"); i != 0 {
+		t.Fatalf("indexOf(y) without filter = %d, want 0", i)
+	}
+
+	// Revealed rows aren't part of the listing: mark-all and exports leave
+	// them out, and the next listing drops them.
+	c.toggleMarkAll()
+	if got := c.markedIDs(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("markedIDs after mark all = %v, want [a b]", got)
+	}
+	objects, err := c.export(context.Background(), func(int, int) {})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(objects) != 2 || objects[0].UniqueIdentifier != "a" || objects[1].UniqueIdentifier != "b" {
+		t.Fatalf("exported %d objects, want a and b", len(objects))
+	}
+	c.setIDs([]string{"a", "b"})
+	if i := c.indexOf("y"); i != -1 {
+		t.Fatalf("indexOf(y) after setIDs = %d, want -1", i)
+	}
+}
+
 func TestLazyContentOnlyVisibleRowsLoad(t *testing.T) {
 	l := newTestLoader()
 	c := newTestContent(l.load)
@@ -358,7 +405,7 @@ func TestBuildRowCellsToleratesBadTypes(t *testing.T) {
 			{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "ok"}},
 		},
 	}
-	cells, _ := buildRowCells(v) // must not panic
+	cells, _, _ := buildRowCells(v, resolveColumns(nil)) // must not panic
 	if cells[nameCol].Text != "ok" {
 		t.Fatalf("name cell = %q, want 'ok'", cells[nameCol].Text)
 	}
@@ -368,6 +415,7 @@ func TestBuildRowCellsToleratesBadTypes(t *testing.T) {
 }
 
 func TestLazyContentAgeRecomputedOnDraw(t *testing.T) {
+	const ageCol = 6 // last of the default columns
 	l := newTestLoader()
 	c := newTestContent(l.load)
 	c.setIDs([]string{"a"})
@@ -485,3 +533,363 @@ func TestLazyContentPlaceholderRebuildsOnFailure(t *testing.T) {
 		t.Fatalf("placeholder after failure = %q, want '!'", failed.Text)
 	}
 }
+
+func TestLazyContentFilterMatchesLoadedDetails(t *testing.T) {
+	l := newTestLoader()
+	c := newTestContent(l.load)
+	c.setIDs([]string{"a", "b", "c"})
+
+	// Only "b" is loaded; its Name is "name-b". Filtering on "NAME-B" must match
+	// it case-insensitively and fetch the others in the background.
+	c.put(namedPayload("b"))
+	c.setFilter("NAME-B")
+	waitUntil(t, func() bool { return c.isLoaded("a") && c.isLoaded("c") })
+
+	if got := c.GetRowCount(); got != 2 { // header + b
+		t.Fatalf("GetRowCount with filter = %d, want 2", got)
+	}
+	if cell := c.GetCell(1, 0); cell.Text != "b" {
+		t.Fatalf("filtered row 1 = %q, want 'b'", cell.Text)
+	}
+
+	// Clearing the filter restores every row in their original order.
+	c.setFilter("")
+	if got := c.GetRowCount(); got != 4 {
+		t.Fatalf("GetRowCount after clearing filter = %d, want 4", got)
+	}
+	if cell := c.GetCell(3, 0); cell.Text != "c" {
+		t.Fatalf("row 3 after clearing filter = %q, want 'c'", cell.Text)
+	}
+}
+
+func TestLazyContentFilterRevealsRowsAsTheyLoad(t *testing.T) {
+	l := newTestLoader()
+	l.gate = make(chan struct{})
+	c := newTestContent(l.load)
+	c.setIDs([]string{"a", "b"})
+
+	// Nothing is loaded and no id contains "name-", so nothing is visible yet,
+	// but both rows are queued for loading.
+	c.setFilter("name-")
+	if got := c.GetRowCount(); got != 1 {
+		t.Fatalf("GetRowCount before any load = %d, want 1 (header only)", got)
+	}
+
+	release(t, l.gate) // a
+	waitUntil(t, func() bool { return c.GetRowCount() == 2 })
+	release(t, l.gate) // b
+	waitUntil(t, func() bool { return c.GetRowCount() == 3 })
+	if idx := c.indexOf("b"); idx != 1 {
+		t.Fatalf("indexOf(b) = %d, want 1 (Locate order preserved)", idx)
+	}
+}
+
+func TestLazyContentFilterMatchesUnloadedIDs(t *testing.T) {
+	l := newTestLoader()
+	l.gate = make(chan struct{}) // keep every load blocked
+	c := newTestContent(l.load)
+	c.setIDs([]string{"key-1", "key-2", "other"})
+
+	// Unloaded rows whose id matches are shown straight away.
+	c.setFilter("key")
+	if got := c.GetRowCount(); got != 3 {
+		t.Fatalf("GetRowCount = %d, want 3 (header + key-1 + key-2)", got)
+	}
+
+	// Removing a visible row drops it from the filtered view too.
+	c.remove("key-1")
+	if cell := c.GetCell(1, 0); cell.Text != "key-2" {
+		t.Fatalf("row 1 after remove = %q, want 'key-2'", cell.Text)
+	}
+	release(t, l.gate) // let the backlog fetch of "other" finish
+}
+
+// testPager serves ids "0".."n-1" in pages of size, recording requested offsets.
+type testPager struct {
+	mu      sync.Mutex
+	n, size int
+	offsets []int
+	err     error
+}
+
+func (p *testPager) page(offset int) ([]string, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.offsets = append(p.offsets, offset)
+	if p.err != nil {
+		return nil, 0, p.err
+	}
+	var ids []string
+	for i := offset; i < min(offset+p.size, p.n); i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	return ids, p.n, nil
+}
+
+func (p *testPager) calls() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.offsets)
+}
+
+func TestLazyContentPagesNearTheBottom(t *testing.T) {
+	l := newTestLoader()
+	p := &testPager{n: 2*prefetchRows + 10, size: 2 * prefetchRows}
+	c := newTestContent(l.load)
+	first, total, _ := p.page(0)
+	c.setPage(first, total, p.page)
+
+	// A trailing "loading more…" row stands for the pages still to fetch.
+	if got := c.GetRowCount(); got != len(first)+2 {
+		t.Fatalf("GetRowCount = %d, want %d (header + page + loading row)", got, len(first)+2)
+	}
+	if _, total := c.counts(); total != p.n {
+		t.Fatalf("counts total = %d, want %d", total, p.n)
+	}
+
+	// Drawing the top rows stays clear of the prefetch zone: no new page.
+	drawRows(c, 1, 2)
+	time.Sleep(30 * time.Millisecond)
+	if got := p.calls(); len(got) != 1 {
+		t.Fatalf("pager calls after drawing the top = %v, want only the first page", got)
+	}
+
+	// Drawing the last rows fetches the next page at the right offset, which
+	// completes the listing and removes the loading row.
+	drawRows(c, len(first), len(first)+1)
+	waitUntil(t, func() bool { return !c.hasMore() })
+	if got := p.calls(); !slices.Equal(got, []int{0, len(first)}) {
+		t.Fatalf("pager offsets = %v, want [0 %d]", got, len(first))
+	}
+	if got := c.GetRowCount(); got != p.n+1 {
+		t.Fatalf("GetRowCount after the last page = %d, want %d", got, p.n+1)
+	}
+}
+
+func TestLazyContentPagingStopsOnRepeatedPage(t *testing.T) {
+	l := newTestLoader()
+	// A server ignoring the offset keeps returning the same page, with no total.
+	repeat := func(int) ([]string, int, error) { return []string{"a", "b"}, -1, nil }
+	c := newTestContent(l.load)
+	c.setPage([]string{"a", "b"}, -1, repeat)
+	if !c.hasMore() {
+		t.Fatal("hasMore = false before the second page, want true (total unknown)")
+	}
+
+	drawRows(c, 1, 2, 3)
+	waitUntil(t, func() bool { return !c.hasMore() })
+	if got := c.GetRowCount(); got != 3 {
+		t.Fatalf("GetRowCount = %d, want 3 (duplicates dropped)", got)
+	}
+}
+
+func TestLazyContentPagingFailureIsShown(t *testing.T) {
+	l := newTestLoader()
+	p := &testPager{n: 100, size: 10, err: errors.New("boom")}
+	c := newTestContent(l.load)
+	c.setPage([]string{"x"}, 100, p.page)
+
+	drawRows(c, 1, 2)
+	waitUntil(t, func() bool { return strings.Contains(c.GetCell(2, 0).Text, "boom") })
+	if c.hasMore() {
+		t.Fatal("hasMore = true after a failed page, want paging stopped")
+	}
+	if p := c.payloadForRow(1); p != nil {
+		t.Fatalf("payloadForRow on the failure row = %v, want nil", p)
+	}
+}
+
+func sizedPayload(id string, size int32) *payloads.GetAttributesResponsePayload {
+	return &payloads.GetAttributesResponsePayload{
+		UniqueIdentifier: id,
+		Attribute: []kmip.Attribute{{
+			AttributeName:  kmip.AttributeNameCryptographicLength,
+			AttributeValue: size,
+		}},
+	}
+}
+
+func TestLazyContentSortPutsUnloadedRowsLast(t *testing.T) {
+	const sizeCol = 4
+	l := newTestLoader()
+	l.gate = make(chan struct{}) // background loads stay pending
+	c := newTestContent(l.load)
+	c.setIDs([]string{"a", "b", "c", "d"})
+	c.put(sizedPayload("a", 256))
+	c.put(sizedPayload("c", 128))
+	c.put(sizedPayload("d", 2048))
+
+	rowIDs := func() []string {
+		var ids []string
+		for r := 1; r < c.GetRowCount(); r++ {
+			ids = append(ids, c.GetCell(r, 0).Text)
+		}
+		return ids
+	}
+
+	// Sizes sort numerically, the unloaded "b" goes last.
+	c.setSort(sizeCol, false)
+	if got := rowIDs(); !slices.Equal(got, []string{"c", "a", "d", "b"}) {
+		t.Fatalf("ascending size order = %v, want [c a d b]", got)
+	}
+	if h := c.GetCell(0, sizeCol).Text; h != "Size ▲" {
+		t.Fatalf("sorted header = %q, want 'Size ▲'", h)
+	}
+
+	// Descending keeps unloaded rows last too.
+	c.setSort(sizeCol, true)
+	if got := rowIDs(); !slices.Equal(got, []string{"d", "a", "c", "b"}) {
+		t.Fatalf("descending size order = %v, want [d a c b]", got)
+	}
+
+	// The missing row is fetched in the background and moves into place.
+	release(t, l.gate)
+	waitUntil(t, func() bool { return c.isLoaded("b") })
+	if got := rowIDs(); len(got) != 4 || got[3] != "b" {
+		t.Fatalf("order after loading b = %v, want b (no size) last", got)
+	}
+
+	// Going back to -1 restores the Locate order and the plain header.
+	c.setSort(-1, false)
+	if got := rowIDs(); !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("unsorted order = %v, want [a b c d]", got)
+	}
+	if h := c.GetCell(0, sizeCol).Text; h != "Size" {
+		t.Fatalf("unsorted header = %q, want 'Size'", h)
+	}
+}
+
+func TestLazyContentSetColumnsRebuildsLoadedRows(t *testing.T) {
+	l := newTestLoader()
+	c := newTestContent(l.load)
+	c.setIDs([]string{"a", "b"})
+	for _, v := range []*payloads.GetAttributesResponsePayload{
+		{UniqueIdentifier: "a", Attribute: []kmip.Attribute{
+			{AttributeName: "x-Owner", AttributeValue: "zed"},
+			{AttributeName: kmip.AttributeNameLink, AttributeValue: kmip.Link{LinkType: kmip.LinkTypePublicKeyLink, LinkedObjectIdentifier: "pub-a"}},
+		}},
+		{UniqueIdentifier: "b", Attribute: []kmip.Attribute{
+			{AttributeName: "x-Owner", AttributeValue: "Alice"},
+		}},
+	} {
+		c.put(v)
+	}
+	c.setSort(0, false)
+
+	c.setColumns([]string{"x-Owner", "Public Key Link", "id"})
+	if got := c.GetColumnCount(); got != 3 {
+		t.Fatalf("GetColumnCount = %d, want 3", got)
+	}
+	if col, _ := c.sorting(); col != 2 {
+		t.Fatalf("sort column = %d, want 2 (the id column, moved)", col)
+	}
+	if h := c.GetCell(0, 2).Text; h != "id ▲" {
+		t.Fatalf("sorted header = %q, want 'id ▲'", h)
+	}
+	if got := c.GetCell(1, 1).Text; got != "pub-a" {
+		t.Fatalf("link cell = %q, want 'pub-a'", got)
+	}
+
+	// Custom attributes sort case-insensitively on their value.
+	c.setSort(0, false)
+	if a, b := c.GetCell(1, 0).Text, c.GetCell(2, 0).Text; a != "Alice" || b != "zed" {
+		t.Fatalf("owner order = [%s %s], want [Alice zed]", a, b)
+	}
+
+	// Dropping the sorted column unsorts; an empty list restores the defaults.
+	c.setColumns(nil)
+	if got := c.GetColumnCount(); got != len(DefaultColumns) {
+		t.Fatalf("GetColumnCount after reset = %d, want %d", got, len(DefaultColumns))
+	}
+	if col, _ := c.sorting(); col != -1 {
+		t.Fatalf("sort column after reset = %d, want -1", col)
+	}
+}
+
+func TestLazyContentExportFetchesEveryMatchingRow(t *testing.T) {
+	l := newTestLoader()
+	p := &testPager{n: 25, size: 10}
+	c := newTestContent(l.load)
+	first, total, _ := p.page(0)
+	c.setPage(first, total, p.page)
+	c.put(namedPayload("1"))
+	c.setFilter("name-1")
+	c.setSort(nameCol, true)
+
+	var last [2]int
+	objects, err := c.export(context.Background(), func(done, total int) { last = [2]int{done, total} })
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	// The pages not fetched yet are listed, the rows are narrowed by the filter
+	// and sorted like the table.
+	var ids []string
+	for _, o := range objects {
+		ids = append(ids, o.UniqueIdentifier)
+	}
+	want := []string{"19", "18", "17", "16", "15", "14", "13", "12", "11", "10", "1"}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("exported ids = %v, want %v", ids, want)
+	}
+	if last != [2]int{25, 25} {
+		t.Fatalf("last progress = %v, want [25 25]", last)
+	}
+	// Rows already loaded aren't fetched again.
+	if n := l.callCount("1"); n != 0 {
+		t.Fatalf("loader calls for the loaded row = %d, want 0", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.export(ctx, func(int, int) {}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("export with a canceled context: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestLazyContentMarks(t *testing.T) {
+	l := newTestLoader()
+	c := newTestContent(l.load)
+	c.setIDs([]string{"key-1", "key-2", "key-3", "other"})
+
+	c.toggleMark(0)
+	c.toggleMark(2)
+	if got := c.markedIDs(); !slices.Equal(got, []string{"key-1", "key-3"}) {
+		t.Fatalf("markedIDs = %v, want [key-1 key-3]", got)
+	}
+	c.toggleMark(0)
+	if got := c.markedIDs(); !slices.Equal(got, []string{"key-3"}) {
+		t.Fatalf("markedIDs after unmarking = %v, want [key-3]", got)
+	}
+
+	// A range adds the rows between both ends, in either order, to the marks.
+	c.setMarks([]string{"other"}, 2, 1)
+	if got := c.markedIDs(); !slices.Equal(got, []string{"key-2", "key-3", "other"}) {
+		t.Fatalf("markedIDs after range = %v, want [key-2 key-3 other]", got)
+	}
+
+	// Marked rows hidden by the filter are left out, and marking all only
+	// concerns the visible rows.
+	c.setFilter("key")
+	if got := c.markedIDs(); !slices.Equal(got, []string{"key-2", "key-3"}) {
+		t.Fatalf("markedIDs with filter = %v, want [key-2 key-3]", got)
+	}
+	c.toggleMarkAll()
+	if got := c.markedIDs(); !slices.Equal(got, []string{"key-1", "key-2", "key-3"}) {
+		t.Fatalf("markedIDs after mark all = %v, want [key-1 key-2 key-3]", got)
+	}
+	c.toggleMarkAll()
+	if got := c.markedIDs(); len(got) != 0 {
+		t.Fatalf("markedIDs after unmark all = %v, want none", got)
+	}
+	c.setFilter("")
+	if got := c.markedIDs(); !slices.Equal(got, []string{"other"}) {
+		t.Fatalf("markedIDs without filter = %v, want [other]", got)
+	}
+
+	// Marks on objects gone from the listing are dropped.
+	c.setIDs([]string{"key-1", "key-2"})
+	c.setIDs([]string{"key-1", "key-2", "other"})
+	if got := c.markedIDs(); len(got) != 0 {
+		t.Fatalf("markedIDs after reload = %v, want none", got)
+	}
+}
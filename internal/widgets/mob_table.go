@@ -15,7 +15,9 @@
 package widgets
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"sync/atomic"
 
 	"github.com/ovh/kmip-go/payloads"
@@ -33,6 +35,12 @@ type MobTable struct {
 	onSelected      func(*payloads.GetAttributesResponsePayload)
 	onContentUpdate func()
 	title           string
+	filter          string
+	selectedID      string // id of the selected row, kept selected when the visible rows change
+	// rangeAnchor is the id of the row where the range being marked started,
+	// empty when not marking a range; rangeBase are the rows marked before.
+	rangeAnchor string
+	rangeBase   []string
 }
 
 // NewMobTable builds the managed-objects table. loader fetches a single object's
@@ -52,6 +60,11 @@ func NewMobTable(loader func(id string) (*payloads.GetAttributesResponsePayload,
 	mtb.Table.SetFixed(1, 0)
 	mtb.Table.Select(0, 0)
 	mtb.Table.SetSelectionChangedFunc(func(row, column int) {
+		mtb.selectedID = ""
+		if sel := mtb.GetSelection(); sel != nil {
+			mtb.selectedID = sel.UniqueIdentifier
+		}
+		mtb.extendRange()
 		mtb.updateTitle()
 		if mtb.onSelection != nil {
 			mtb.onSelection(mtb.GetSelection())
@@ -78,8 +91,9 @@ func (mtb *MobTable) Draw(screen tcell.Screen) {
 }
 
 // requestRedraw coalesces detail-load completions into at most one queued redraw.
-// The queued closure runs on the UI thread: it refreshes the attributes panel for
-// the current selection, and the implicit Draw re-reads the now-cached cells.
+// The queued closure runs on the UI thread: it refreshes the title and the
+// attributes panel for the current selection (a filtered view may have gained or
+// lost rows), and the implicit Draw re-reads the now-cached cells.
 func (mtb *MobTable) requestRedraw() {
 	if mtb.app == nil {
 		return
@@ -89,9 +103,7 @@ func (mtb *MobTable) requestRedraw() {
 	}
 	mtb.app.QueueUpdateDraw(func() {
 		mtb.redrawPending.Store(false)
-		if mtb.onContentUpdate != nil {
-			mtb.onContentUpdate()
-		}
+		mtb.contentUpdated()
 	})
 }
 
@@ -100,14 +112,55 @@ func (mtb *MobTable) SetTitle(title string) {
 	mtb.updateTitle()
 }
 
+// updateTitle shows the selected row and the number of rows. While more pages
+// are still to be fetched the count is followed by the server-side total when
+// known ("[3/200 of 12000]"), or by a "+" when not.
 func (mtb *MobTable) updateTitle() {
 	row, _ := mtb.Table.GetSelection()
-	total := mtb.Table.GetRowCount() - 1
-	title := fmt.Sprintf("%s [%d/%d]", mtb.title, row, total)
+	visible, total := mtb.content.counts()
+	title := mtb.title
+	if mtb.filter != "" {
+		title += " </" + tview.Escape(mtb.filter) + ">"
+	}
+	switch {
+	case total >= 0:
+		title = fmt.Sprintf("%s [%d/%d of %d]", title, row, visible, total)
+	case mtb.content.hasMore():
+		title = fmt.Sprintf("%s [%d/%d+]", title, row, visible)
+	default:
+		title = fmt.Sprintf("%s [%d/%d]", title, row, visible)
+	}
+	if marked := len(mtb.content.markedIDs()); marked > 0 || mtb.rangeAnchor != "" {
+		title += fmt.Sprintf(" (%d marked)", marked)
+	}
+	if mtb.rangeAnchor != "" {
+		title += " -- RANGE --"
+	}
 	mtb.Table.SetTitle(title)
 }
 
+// reselect keeps the previously selected object selected after the visible rows
+// changed underneath it (e.g. the filter revealed or hid rows above it).
+func (mtb *MobTable) reselect() {
+	if mtb.selectedID == "" {
+		return
+	}
+	row, _ := mtb.Table.GetSelection()
+	i := mtb.content.indexOf(mtb.selectedID)
+	switch {
+	case i < 0:
+		// The object is gone from view: follow whatever now sits on that row.
+		mtb.selectedID = ""
+		if sel := mtb.GetSelection(); sel != nil {
+			mtb.selectedID = sel.UniqueIdentifier
+		}
+	case i+1 != row:
+		mtb.Table.Select(i+1, 0)
+	}
+}
+
 func (mtb *MobTable) contentUpdated() {
+	mtb.reselect()
 	mtb.updateTitle()
 	if mtb.onContentUpdate != nil {
 		mtb.onContentUpdate()
@@ -130,6 +183,38 @@ func (mtb *MobTable) SetIDs(ids []string) {
 	mtb.contentUpdated()
 }
 
+// SetPage renders the first page of a paged listing, like SetIDs, and fetches the
+// following pages through next as the user scrolls near the bottom of the table.
+// total is the number of objects on the server, or -1 when unknown.
+func (mtb *MobTable) SetPage(ids []string, total int, next Pager) {
+	mtb.content.setPage(ids, total, next)
+	mtb.contentUpdated()
+}
+
+// SetFilter narrows the table to the objects whose ID, Name, Algorithm or State
+// contains query (case-insensitive); an empty query shows every object again.
+// Details of rows that can't be matched yet are fetched in the background, and
+// rows appear as soon as their details match.
+func (mtb *MobTable) SetFilter(query string) {
+	mtb.filter = strings.TrimSpace(query)
+	mtb.content.setFilter(query)
+	mtb.contentUpdated()
+}
+
+// Reveal selects the row of the object id, listing it at the top of the table
+// if it isn't part of the listing, and reports whether it is shown: the filter
+// may hide it.
+func (mtb *MobTable) Reveal(id string) bool {
+	mtb.content.reveal(id)
+	mtb.contentUpdated()
+	i := mtb.content.indexOf(id)
+	if i < 0 {
+		return false
+	}
+	mtb.Table.Select(i+1, 0)
+	return true
+}
+
 func (mtb *MobTable) RemoveObject(id string) {
 	mtb.content.remove(id)
 	mtb.contentUpdated()
@@ -143,14 +228,151 @@ func (mtb *MobTable) UpdateObject(object *payloads.GetAttributesResponsePayload)
 func (mtb *MobTable) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
 	return mtb.Table.WrapInputHandler(func(ek *tcell.EventKey, f func(p tview.Primitive)) {
 		if ek.Key() == tcell.KeyESC {
+			if mtb.rangeAnchor != "" || len(mtb.Marked()) > 0 {
+				mtb.ClearMarks()
+				return
+			}
 			mtb.Table.Select(0, 0)
 			mtb.Table.ScrollToBeginning()
 			return
 		}
+		if ek.Rune() == 'm' {
+			mtb.ToggleMark()
+			return
+		}
+		if ek.Rune() == 'V' {
+			mtb.ToggleRange()
+			return
+		}
+		if ek.Key() == tcell.KeyCtrlA {
+			mtb.ToggleMarkAll()
+			return
+		}
+		if ek.Rune() == 's' {
+			mtb.NextSortColumn()
+			return
+		}
+		if ek.Rune() == 'S' {
+			mtb.ReverseSort()
+			return
+		}
 		mtb.Table.InputHandler()(ek, f)
 	})
 }
 
+// ToggleMark marks the selected row for a bulk operation, or unmarks it, then
+// selects the next row.
+func (mtb *MobTable) ToggleMark() {
+	mtb.rangeAnchor, mtb.rangeBase = "", nil
+	row, _ := mtb.Table.GetSelection()
+	if row <= 0 {
+		return
+	}
+	mtb.content.toggleMark(row - 1)
+	if visible, _ := mtb.content.counts(); row < visible {
+		mtb.Table.Select(row+1, 0)
+	}
+	mtb.updateTitle()
+}
+
+// ToggleRange starts marking the rows from the selected one to the one the
+// selection moves to, or stops at the selected row.
+func (mtb *MobTable) ToggleRange() {
+	if mtb.rangeAnchor != "" {
+		mtb.rangeAnchor, mtb.rangeBase = "", nil
+		mtb.updateTitle()
+		return
+	}
+	sel := mtb.GetSelection()
+	if sel == nil {
+		return
+	}
+	mtb.rangeAnchor, mtb.rangeBase = sel.UniqueIdentifier, mtb.content.markedIDs()
+	mtb.extendRange()
+	mtb.updateTitle()
+}
+
+// extendRange marks the rows between the start of the range being marked and
+// the selected row, besides those marked before it started.
+func (mtb *MobTable) extendRange() {
+	if mtb.rangeAnchor == "" {
+		return
+	}
+	anchor := mtb.content.indexOf(mtb.rangeAnchor)
+	row, _ := mtb.Table.GetSelection()
+	if anchor < 0 || row <= 0 {
+		// The start of the range is gone from view.
+		mtb.rangeAnchor, mtb.rangeBase = "", nil
+		return
+	}
+	mtb.content.setMarks(mtb.rangeBase, anchor, row-1)
+}
+
+// ToggleMarkAll marks every row listed and shown by the filter, or unmarks them
+// all if they already are.
+func (mtb *MobTable) ToggleMarkAll() {
+	mtb.rangeAnchor, mtb.rangeBase = "", nil
+	mtb.content.toggleMarkAll()
+	mtb.updateTitle()
+}
+
+// ClearMarks unmarks every row.
+func (mtb *MobTable) ClearMarks() {
+	mtb.rangeAnchor, mtb.rangeBase = "", nil
+	mtb.content.setMarks(nil, -1, -1)
+	mtb.updateTitle()
+}
+
+// Marked returns the ids of the marked rows shown by the filter, in display
+// order.
+func (mtb *MobTable) Marked() []string {
+	return mtb.content.markedIDs()
+}
+
+// NextSortColumn sorts the table on the next column, left to right, going back
+// to the Locate order after the last one. The direction is kept.
+func (mtb *MobTable) NextSortColumn() {
+	col, desc := mtb.content.sorting()
+	col++
+	if col >= mtb.content.GetColumnCount() {
+		col = -1
+	}
+	mtb.content.setSort(col, desc)
+	mtb.contentUpdated()
+}
+
+// ReverseSort flips the sort direction, sorting on the first column if the table
+// wasn't sorted yet.
+func (mtb *MobTable) ReverseSort() {
+	col, desc := mtb.content.sorting()
+	if col < 0 {
+		col, desc = 0, false
+	}
+	mtb.content.setSort(col, !desc)
+	mtb.contentUpdated()
+}
+
+// SetColumns changes the displayed columns, by name (see DefaultColumns and
+// SuggestedColumns; any other name is shown as the attribute of that name). An
+// empty list restores the defaults.
+func (mtb *MobTable) SetColumns(names []string) {
+	mtb.content.setColumns(names)
+	mtb.contentUpdated()
+}
+
+// Columns returns the names of the displayed columns, in order.
+func (mtb *MobTable) Columns() []string {
+	return mtb.content.columnNames()
+}
+
+// Export fetches the details of every object of the listing which the filter
+// shows, in the displayed order, including the pages and rows not loaded yet.
+// progress is called with the number of objects fetched out of those known so
+// far. Export blocks: it must not be called from the UI goroutine.
+func (mtb *MobTable) Export(ctx context.Context, progress func(done, total int)) ([]*payloads.GetAttributesResponsePayload, error) {
+	return mtb.content.export(ctx, progress)
+}
+
 // GetSelection returns the selected object's details, or a stub carrying just the
 // id when details haven't loaded yet (nil only when no data row is selected).
 func (tb *MobTable) GetSelection() *payloads.GetAttributesResponsePayload {
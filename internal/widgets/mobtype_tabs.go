@@ -20,6 +20,9 @@ import (
 	"github.com/ovh/kmip-go"
 )
 
+// dashboardTab is the tab of the [Dashboard], after those of the object types.
+const dashboardTab = "Expiring"
+
 type MobTypeTabs struct {
 	*components.Tabs
 	onChange func(kmip.ObjectType, string)
@@ -27,7 +30,7 @@ type MobTypeTabs struct {
 
 func NewMobTypeTabs() *MobTypeTabs {
 	mtt := &MobTypeTabs{}
-	mtt.Tabs = components.NewTabs("All", "Symmetric Keys", "Private Keys", "Public Keys", "Secrets", "Certificates", "Opaque", "Templates").
+	mtt.Tabs = components.NewTabs("All", "Symmetric Keys", "Private Keys", "Public Keys", "Secrets", "Certificates", "Opaque", "Templates", dashboardTab).
 		SetChangedFunc(mtt.changed)
 
 	return mtt
@@ -39,6 +42,23 @@ func (mtt *MobTypeTabs) Current() string {
 	return name
 }
 
+// Dashboard reports whether the tab of the dashboard is selected rather than
+// one of an object type.
+func (mtt *MobTypeTabs) Dashboard() bool {
+	_, selected := mtt.Tabs.GetSelected()
+	return selected == dashboardTab
+}
+
+// SelectType selects the tab of the object type ot, zero for all objects.
+func (mtt *MobTypeTabs) SelectType(ot kmip.ObjectType) {
+	for i, choice := range mtt.Choices() {
+		if t, _ := mtt.intoType(choice); t == ot && choice != dashboardTab {
+			mtt.Select(i)
+			return
+		}
+	}
+}
+
 func (mtt *MobTypeTabs) changed(_ int, selected string) {
 	if mtt.onChange == nil {
 		return
@@ -70,6 +90,8 @@ func (mtt *MobTypeTabs) intoType(selected string) (kmip.ObjectType, string) {
 	case "Templates":
 		//nolint:staticcheck // We still want to display templates
 		return kmip.ObjectTypeTemplate, "Templates"
+	case dashboardTab:
+		return 0, "Expiring & Stale Objects"
 	default:
 		return 0, "All Objects"
 	}
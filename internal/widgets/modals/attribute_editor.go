@@ -0,0 +1,526 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phsym/kmip-explorer/internal/components"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/rivo/tview"
+)
+
+// AttributeChange is an attribute edit validated by the user, ready to be sent
+// to the server.
+type AttributeChange struct {
+	// Description summarizes the change, e.g. to ask for confirmation.
+	Description string
+	// Delete is set for deletions, which can't be undone.
+	Delete bool
+	// Exec sends the change for the object with the given id.
+	Exec func(c *kmipclient.Client, id string) error
+}
+
+// attributeEntry is one attribute instance of the edited object. Multi-valued
+// attributes (e.g. Name, Object Group) have one entry per value, told apart by
+// their index.
+type attributeEntry struct {
+	name  kmip.AttributeName
+	index int32
+	value any
+}
+
+// AttributeEditor lists the attributes of an object and lets the user add,
+// modify and delete them.
+type AttributeEditor struct {
+	*tview.Flex
+	pages    *tview.Pages
+	list     *tview.Table
+	form     *components.Form
+	schedule *components.Form
+	dates    []*components.DateField
+	id       string
+	entries  []attributeEntry
+	editing  *attributeEntry // a copy of the entry being modified, nil when adding one
+	hint     *tview.TextView
+	caps     Capabilities
+	onCancel func()
+	onDone   func(AttributeChange)
+}
+
+func NewAttributeEditor() *AttributeEditor {
+	md := &AttributeEditor{}
+	md.list = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	md.list.SetInputCapture(md.handleKey)
+	md.hint = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	listFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(md.list, 0, 1, true).
+		AddItem(md.hint, 1, 0, false)
+	listFlex.SetBorder(true)
+
+	md.form = components.NewForm()
+	md.form.AddInputField("Name", "", 0, nil, nil).
+		AddInputField("Value", "", 0, nil, nil).
+		AddButton("OK", md.submit).
+		AddButton("Cancel", md.closeForm).
+		SetCancelFunc(md.closeForm).
+		SetButtonsAlign(tview.AlignCenter)
+	md.form.SetBorder(true)
+	formFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(md.form, 9, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	md.dates = newScheduleFields()
+	md.schedule = components.NewForm()
+	for _, f := range md.dates {
+		md.schedule.AddFormItem(f)
+	}
+	md.schedule.AddButton("OK", md.submitSchedule).
+		AddButton("Cancel", md.closeForm).
+		SetCancelFunc(md.closeForm).
+		SetButtonsAlign(tview.AlignCenter)
+	md.schedule.SetBorder(true)
+	scheduleFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(md.schedule, 5+scheduleHeight, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	md.pages = tview.NewPages().
+		AddPage("list", listFlex, true, true).
+		AddPage("form", formFlex, true, false).
+		AddPage("schedule", scheduleFlex, true, false)
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.pages, 0, 4, true).
+			AddItem(nil, 0, 1, false),
+			0, 4, true).
+		AddItem(nil, 0, 1, false)
+	md.SetCapabilities(Capabilities{})
+	return md
+}
+
+// SetCapabilities offers only the edits the server supports.
+func (md *AttributeEditor) SetCapabilities(caps Capabilities) *AttributeEditor {
+	md.caps = caps
+	var hint []string
+	if caps.Supports(kmip.OperationAddAttribute) {
+		hint = append(hint, "[::b]<a>[::-] add")
+	}
+	if caps.Supports(kmip.OperationModifyAttribute) {
+		hint = append(hint, "[::b]<enter>[::-] modify")
+	}
+	if caps.Supports(kmip.OperationDeleteAttribute) {
+		hint = append(hint, "[::b]<ctrl+d>[::-] delete")
+	}
+	if caps.SupportsAll(kmip.OperationAddAttribute, kmip.OperationModifyAttribute) {
+		hint = append(hint, "[::b]<d>[::-] schedule")
+	}
+	md.hint.SetText(strings.Join(append(hint, "[::b]<esc>[::-] close"), "  "))
+	return md
+}
+
+func (md *AttributeEditor) OnCancel(cb func()) *AttributeEditor {
+	md.onCancel = cb
+	return md
+}
+
+// OnDone sets the callback receiving each change the user validates. The editor
+// stays open: refresh it with SetObject once the change is applied.
+func (md *AttributeEditor) OnDone(cb func(AttributeChange)) *AttributeEditor {
+	md.onDone = cb
+	return md
+}
+
+// ObjectID returns the identifier of the edited object.
+func (md *AttributeEditor) ObjectID() string {
+	return md.id
+}
+
+// SetObject shows the attributes of obj, keeping the selected row when obj is
+// the object already being edited.
+func (md *AttributeEditor) SetObject(obj *payloads.GetAttributesResponsePayload) *AttributeEditor {
+	row := 1
+	if obj.UniqueIdentifier == md.id {
+		row, _ = md.list.GetSelection()
+	} else {
+		md.closeForm()
+	}
+	md.id = obj.UniqueIdentifier
+	md.entries = md.entries[:0]
+	counts := map[kmip.AttributeName]int32{}
+	for _, attr := range obj.Attribute {
+		idx := counts[attr.AttributeName]
+		if attr.AttributeIndex != nil {
+			idx = *attr.AttributeIndex
+		}
+		counts[attr.AttributeName] = idx + 1
+		md.entries = append(md.entries, attributeEntry{name: attr.AttributeName, index: idx, value: attr.AttributeValue})
+	}
+
+	hdrStyle := tcell.StyleDefault.Bold(true)
+	md.list.Clear().
+		SetCell(0, 0, tview.NewTableCell("Attribute").SetStyle(hdrStyle).SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("Index").SetStyle(hdrStyle).SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("Value").SetStyle(hdrStyle).SetSelectable(false))
+	for i, e := range md.entries {
+		md.list.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(string(e.name))).SetTextColor(tcell.ColorGreen)).
+			SetCell(i+1, 1, tview.NewTableCell(strconv.Itoa(int(e.index))).SetAlign(tview.AlignRight)).
+			SetCell(i+1, 2, tview.NewTableCell(tview.Escape(attributeText(e.value))).SetExpansion(1))
+	}
+	md.list.Select(min(max(row, 1), max(len(md.entries), 1)), 0)
+	return md
+}
+
+func (md *AttributeEditor) selected() *attributeEntry {
+	row, _ := md.list.GetSelection()
+	if row < 1 || row > len(md.entries) {
+		return nil
+	}
+	return &md.entries[row-1]
+}
+
+func (md *AttributeEditor) handleKey(ek *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case ek.Key() == tcell.KeyESC:
+		md.cancel()
+	case ek.Rune() == 'a':
+		if md.caps.Supports(kmip.OperationAddAttribute) {
+			md.openForm(nil)
+		}
+	case ek.Key() == tcell.KeyEnter || ek.Rune() == 'e':
+		if e := md.selected(); e != nil && md.caps.Supports(kmip.OperationModifyAttribute) {
+			md.openForm(e)
+		}
+	case ek.Key() == tcell.KeyCtrlD:
+		if e := md.selected(); e != nil && md.caps.Supports(kmip.OperationDeleteAttribute) {
+			md.delete(*e)
+		}
+	case ek.Rune() == 'd':
+		if md.caps.SupportsAll(kmip.OperationAddAttribute, kmip.OperationModifyAttribute) {
+			md.openSchedule()
+		}
+	default:
+		return ek
+	}
+	return nil
+}
+
+// openForm shows the form to modify e, or to add an attribute when e is nil.
+func (md *AttributeEditor) openForm(e *attributeEntry) {
+	md.editing = nil
+	if e != nil {
+		cp := *e
+		md.editing = &cp
+	}
+	name := md.form.GetFormItemByLabel("Name").(*tview.InputField)
+	value := md.form.GetFormItemByLabel("Value").(*tview.InputField)
+	if e == nil {
+		md.form.SetTitle("Add attribute")
+		name.SetText("").SetDisabled(false)
+		value.SetText("")
+		md.form.SetFocus(0)
+	} else {
+		md.form.SetTitle(tview.Escape(fmt.Sprintf("Modify %s #%d", e.name, e.index)))
+		name.SetText(string(e.name)).SetDisabled(true)
+		value.SetText(attributeText(e.value))
+		md.form.SetFocus(1)
+	}
+	md.pages.ShowPage("form")
+}
+
+func (md *AttributeEditor) closeForm() {
+	md.editing = nil
+	md.pages.HidePage("form")
+	md.pages.HidePage("schedule")
+}
+
+// scheduled returns the entry of the date name, nil if the object has none.
+func (md *AttributeEditor) scheduled(name kmip.AttributeName) *attributeEntry {
+	for i, e := range md.entries {
+		if e.name == name {
+			return &md.entries[i]
+		}
+	}
+	return nil
+}
+
+// asTime returns the value of a date attribute, zero if it isn't one.
+func asTime(value any) time.Time {
+	t, _ := value.(time.Time)
+	return t
+}
+
+// openSchedule shows the form to change the dates of the lifecycle of the
+// object at once.
+func (md *AttributeEditor) openSchedule() {
+	for i, name := range ScheduleDates {
+		md.dates[i].SetText("")
+		if e := md.scheduled(name); e != nil {
+			md.dates[i].SetText(attributeText(e.value))
+		}
+	}
+	md.schedule.SetTitle("Schedule")
+	md.schedule.SetFocus(0)
+	md.pages.ShowPage("schedule")
+}
+
+// submitSchedule adds the dates set in the schedule form, modifies the changed
+// ones and deletes the ones cleared, in a single change. The dates are changed
+// one after the other: when one fails, the error tells those already changed.
+func (md *AttributeEditor) submitSchedule() {
+	now := time.Now()
+	var (
+		ops     []func(c *kmipclient.Client, id string) error
+		changed []string
+		deleted bool
+	)
+	for i, name := range ScheduleDates {
+		date, err := md.dates[i].Date(now)
+		if err != nil {
+			// Keep the form open so the date can be fixed.
+			md.schedule.SetTitle("[red]" + tview.Escape(fmt.Sprintf("%s: %s", name, err)))
+			md.schedule.SetFocus(i)
+			return
+		}
+		e := md.scheduled(name)
+		switch {
+		case e == nil && date.IsZero():
+			continue
+		case e == nil:
+			ops = append(ops, func(c *kmipclient.Client, id string) error {
+				_, err := c.AddAttribute(id, name, date).Exec()
+				return err
+			})
+		case date.IsZero():
+			index := e.index
+			deleted = true
+			ops = append(ops, func(c *kmipclient.Client, id string) error {
+				_, err := c.DeleteAttribute(id, name).WithIndex(index).Exec()
+				return err
+			})
+		case !date.Equal(asTime(e.value)):
+			index := e.index
+			ops = append(ops, func(c *kmipclient.Client, id string) error {
+				_, err := c.ModifyAttribute(id, name, date).WithIndex(index).Exec()
+				return err
+			})
+		default:
+			continue
+		}
+		changed = append(changed, string(name))
+	}
+	md.closeForm()
+	if len(ops) == 0 {
+		return
+	}
+	md.emit(AttributeChange{
+		Description: "Schedule " + strings.Join(changed, ", "),
+		Delete:      deleted,
+		Exec: func(c *kmipclient.Client, id string) error {
+			for i, op := range ops {
+				err := op(c, id)
+				switch {
+				case err == nil:
+				case i == 0:
+					return fmt.Errorf("%s: %w", changed[i], err)
+				default:
+					return fmt.Errorf("%s: %w (%s changed)", changed[i], err, strings.Join(changed[:i], ", "))
+				}
+			}
+			return nil
+		},
+	})
+}
+
+func (md *AttributeEditor) submit() {
+	name := kmip.AttributeName(strings.TrimSpace(md.form.GetFormItemByLabel("Name").(*tview.InputField).GetText()))
+	text := md.form.GetFormItemByLabel("Value").(*tview.InputField).GetText()
+	if name == "" {
+		md.form.SetFocus(0)
+		return
+	}
+
+	var current any
+	if md.editing != nil {
+		current = md.editing.value
+	}
+	value, err := parseAttributeValue(name, text, current)
+	if err != nil {
+		// Keep the form open so the value can be fixed.
+		md.form.SetTitle("[red]" + tview.Escape(err.Error()))
+		md.form.SetFocus(1)
+		return
+	}
+
+	if md.editing == nil {
+		md.emit(AttributeChange{
+			Description: fmt.Sprintf("Add %s", name),
+			Exec: func(c *kmipclient.Client, id string) error {
+				_, err := c.AddAttribute(id, name, value).Exec()
+				return err
+			},
+		})
+	} else {
+		index := md.editing.index
+		md.emit(AttributeChange{
+			Description: fmt.Sprintf("Modify %s #%d", name, index),
+			Exec: func(c *kmipclient.Client, id string) error {
+				_, err := c.ModifyAttribute(id, name, value).WithIndex(index).Exec()
+				return err
+			},
+		})
+	}
+	md.closeForm()
+}
+
+func (md *AttributeEditor) delete(e attributeEntry) {
+	md.emit(AttributeChange{
+		Description: fmt.Sprintf("Delete %s #%d (%s)", e.name, e.index, attributeText(e.value)),
+		Delete:      true,
+		Exec: func(c *kmipclient.Client, id string) error {
+			_, err := c.DeleteAttribute(id, e.name).WithIndex(e.index).Exec()
+			return err
+		},
+	})
+}
+
+func (md *AttributeEditor) emit(change AttributeChange) {
+	if md.onDone != nil {
+		md.onDone(change)
+	}
+}
+
+func (md *AttributeEditor) cancel() {
+	md.closeForm()
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
+
+// attributeText renders an attribute value on one line. Editable values use the
+// syntax parseAttributeValue reads back; others use their TTLV text form.
+func attributeText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case kmip.Name:
+		return v.NameValue
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	enc := ttlv.NewTextEncoder()
+	enc.TagAny(kmip.TagAttributeValue, value)
+	txt := string(enc.Bytes())
+	if _, after, ok := strings.Cut(txt, "): "); ok {
+		txt = after
+	}
+	return strings.Join(strings.Fields(txt), " ")
+}
+
+// attributeKinds are zero values of the types of the standard attributes which
+// can be added, or unsupportedValue for those which can't be typed in. The
+// attributes missing, custom "x-" ones included, are text strings.
+var attributeKinds = map[kmip.AttributeName]any{
+	kmip.AttributeNameName:                     kmip.Name{},
+	kmip.AttributeNameActivationDate:           time.Time{},
+	kmip.AttributeNameDeactivationDate:         time.Time{},
+	kmip.AttributeNameProcessStartDate:         time.Time{},
+	kmip.AttributeNameProtectStopDate:          time.Time{},
+	kmip.AttributeNameCompromiseOccurrenceDate: time.Time{},
+	kmip.AttributeNameArchiveDate:              time.Time{},
+	kmip.AttributeNameOriginalCreationDate:     time.Time{},
+	kmip.AttributeNameCryptographicLength:      int32(0),
+	kmip.AttributeNameFresh:                    false,
+	kmip.AttributeNameCryptographicAlgorithm:   unsupportedValue{},
+	kmip.AttributeNameCryptographicUsageMask:   unsupportedValue{},
+	kmip.AttributeNameLink:                     unsupportedValue{},
+	kmip.AttributeNameRevocationReason:         unsupportedValue{},
+	kmip.AttributeNameObjectType:               unsupportedValue{},
+	kmip.AttributeNameState:                    unsupportedValue{},
+}
+
+// unsupportedValue stands for the value of an attribute which can't be typed
+// in.
+type unsupportedValue struct{}
+
+// parseAttributeValue converts the text typed for attribute name into a value
+// of the attribute's type: the type of current when modifying a value, or the
+// type the attribute is known to have when adding one (see attributeKinds).
+// A modified Name keeps its Name Type.
+func parseAttributeValue(name kmip.AttributeName, text string, current any) (any, error) {
+	if current == nil {
+		var ok bool
+		if current, ok = attributeKinds[name]; !ok {
+			current = ""
+		}
+	}
+	switch v := current.(type) {
+	case string:
+		return text, nil
+	case kmip.Name:
+		if text == "" {
+			return nil, errors.New("Name cannot be empty")
+		}
+		nameType := v.NameType
+		if nameType == 0 {
+			nameType = kmip.NameTypeUninterpretedTextString
+		}
+		return kmip.Name{NameValue: text, NameType: nameType}, nil
+	case int32:
+		i, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", name)
+		}
+		return int32(i), nil
+	case int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", name)
+		}
+		return i, nil
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", name)
+		}
+		return b, nil
+	case time.Time:
+		t, err := components.ParseDate(text, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a date, e.g. +30d or 2025-01-31T12:00:00Z", name)
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("Editing %s values is not supported", name)
+}
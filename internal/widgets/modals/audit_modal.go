@@ -0,0 +1,137 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/phsym/kmip-explorer/internal/audit"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// AuditLog browses the entries of the audit log, newest first, with the
+// details of the selected one.
+type AuditLog struct {
+	*tview.Flex
+	table    *tview.Table
+	details  *tview.TextView
+	entries  []audit.Entry
+	onCancel func()
+}
+
+func NewAuditLog() *AuditLog {
+	md := &AuditLog{}
+	md.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0).
+		SetSelectionChangedFunc(func(row, _ int) { md.showDetails(row) }).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape {
+				md.cancel()
+			}
+		})
+	md.details = tview.NewTextView().SetDynamicColors(true)
+	md.details.SetBorder(true).SetTitle("Details")
+
+	frame := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(md.table, 0, 2, true).
+		AddItem(md.details, 0, 1, false)
+	frame.SetBorder(true).SetTitle("Audit log")
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(frame, 0, 6, true).
+			AddItem(nil, 0, 1, false),
+			0, 6, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+func (md *AuditLog) OnCancel(cb func()) *AuditLog {
+	md.onCancel = cb
+	return md
+}
+
+// Load reads the audit log at path, showing its entries newest first. The
+// entries read before an unreadable line are shown along with the error.
+func (md *AuditLog) Load(path string) {
+	entries, err := audit.Read(path)
+	slices.Reverse(entries)
+	md.entries = entries
+	md.table.Clear()
+	for col, title := range []string{"Time", "Operation", "Objects", "Result"} {
+		md.table.SetCell(0, col, tview.NewTableCell(title).SetSelectable(false).
+			SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold))
+	}
+	for i, e := range entries {
+		result := tview.NewTableCell(e.Result)
+		if e.Result != audit.ResultSuccess {
+			result.SetTextColor(tcell.ColorRed)
+		}
+		md.table.SetCell(i+1, 0, tview.NewTableCell(e.Time.Local().Format(time.DateTime))).
+			SetCell(i+1, 1, tview.NewTableCell(tview.Escape(e.Operation))).
+			SetCell(i+1, 2, tview.NewTableCell(tview.Escape(strings.Join(e.IDs, ", "))).SetExpansion(1)).
+			SetCell(i+1, 3, result)
+	}
+	switch {
+	case err != nil:
+		md.details.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+	case len(entries) == 0:
+		md.details.SetText(fmt.Sprintf("No entries yet in %s", tview.Escape(path)))
+	}
+	md.table.ScrollToBeginning()
+	if len(entries) > 0 {
+		md.table.Select(1, 0)
+		if err == nil {
+			md.showDetails(1)
+		}
+	}
+}
+
+func (md *AuditLog) showDetails(row int) {
+	if row < 1 || row > len(md.entries) {
+		return
+	}
+	e := md.entries[row-1]
+	var b strings.Builder
+	line := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "[yellow::b]%s:[-::-] %s\n", name, tview.Escape(value))
+		}
+	}
+	line("Time", e.Time.Local().Format(time.RFC3339))
+	line("Server", e.Server)
+	line("Client", e.Subject)
+	line("Operation", e.Operation)
+	line("Objects", strings.Join(e.IDs, ", "))
+	for _, name := range slices.Sorted(maps.Keys(e.Params)) {
+		line(name, e.Params[name])
+	}
+	line("Result", e.Result)
+	line("Error", e.Error)
+	md.details.SetText(b.String()).ScrollToBeginning()
+}
+
+func (md *AuditLog) cancel() {
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"slices"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+// Capabilities tells which operations and object types the server supports,
+// as reported by the Query operation. The zero value supports everything: it
+// stands for servers whose capabilities are unknown.
+type Capabilities struct {
+	// Operations are the supported operations; nil if unknown.
+	Operations []kmip.Operation
+	// ObjectTypes are the supported object types; nil if unknown.
+	ObjectTypes []kmip.ObjectType
+}
+
+// Supports reports whether the server supports any of the given operations.
+func (c Capabilities) Supports(ops ...kmip.Operation) bool {
+	if c.Operations == nil {
+		return true
+	}
+	for _, op := range ops {
+		if slices.Contains(c.Operations, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsAll reports whether the server supports every one of the given
+// operations.
+func (c Capabilities) SupportsAll(ops ...kmip.Operation) bool {
+	for _, op := range ops {
+		if !c.Supports(op) {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsType reports whether the server supports the given object type.
+func (c Capabilities) SupportsType(t kmip.ObjectType) bool {
+	return c.ObjectTypes == nil || slices.Contains(c.ObjectTypes, t)
+}
+
+// QueryCapabilities reads the capabilities from the response to a Query asking
+// for the operations and object types. Lists the server left out stay unknown.
+func QueryCapabilities(resp *payloads.QueryResponsePayload) Capabilities {
+	var caps Capabilities
+	if len(resp.Operation) > 0 {
+		caps.Operations = resp.Operation
+	}
+	if len(resp.ObjectType) > 0 {
+		caps.ObjectTypes = resp.ObjectType
+	}
+	return caps
+}
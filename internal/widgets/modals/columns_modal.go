@@ -0,0 +1,195 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Columns lets the user pick the object table columns and their order. Every
+// candidate is listed with a checkbox, the displayed columns first, in order.
+type Columns struct {
+	*tview.Flex
+	list     *tview.Table
+	input    *tview.InputField
+	adding   bool
+	names    []string
+	checked  map[string]bool
+	onCancel func()
+	onDone   func([]string)
+}
+
+func NewColumns() *Columns {
+	md := &Columns{checked: map[string]bool{}}
+	md.list = tview.NewTable().SetSelectable(true, false)
+	md.list.SetInputCapture(md.handleKey)
+	md.input = tview.NewInputField().SetLabel("Attribute: ").
+		SetFieldBackgroundColor(tcell.ColorNone)
+	hint := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter).
+		SetText("[::b]<space>[::-] toggle  [::b]<shift+up/down>[::-] move  [::b]<a>[::-] add attribute  [::b]<enter>[::-] apply  [::b]<esc>[::-] cancel")
+
+	body := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(md.list, 0, 1, true).
+		AddItem(md.input, 1, 0, false).
+		AddItem(hint, 1, 0, false)
+	body.SetBorder(true).SetTitle("Columns")
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(body, 0, 2, true).
+			AddItem(nil, 0, 1, false),
+			0, 4, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+func (md *Columns) OnCancel(cb func()) *Columns {
+	md.onCancel = cb
+	return md
+}
+
+func (md *Columns) OnDone(cb func([]string)) *Columns {
+	md.onDone = cb
+	return md
+}
+
+// SetColumns resets the list to the displayed columns, checked and in order,
+// followed by the other candidates, unchecked. Names are deduplicated
+// case-insensitively.
+func (md *Columns) SetColumns(displayed []string, candidates ...[]string) *Columns {
+	md.names = md.names[:0]
+	clear(md.checked)
+	for _, name := range displayed {
+		if md.add(name) {
+			md.checked[name] = true
+		}
+	}
+	for _, list := range candidates {
+		for _, name := range list {
+			md.add(name)
+		}
+	}
+	md.stopAdding()
+	md.render()
+	md.list.Select(0, 0).ScrollToBeginning()
+	return md
+}
+
+// add appends name to the list unless it's already there, reporting whether it
+// was added.
+func (md *Columns) add(name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || slices.ContainsFunc(md.names, func(n string) bool { return strings.EqualFold(n, name) }) {
+		return false
+	}
+	md.names = append(md.names, name)
+	return true
+}
+
+func (md *Columns) render() {
+	md.list.Clear()
+	for i, name := range md.names {
+		box := "[ ] "
+		if md.checked[name] {
+			box = "[x] "
+		}
+		md.list.SetCell(i, 0, tview.NewTableCell(tview.Escape(box+name)).SetExpansion(1))
+	}
+}
+
+func (md *Columns) handleKey(ek *tcell.EventKey) *tcell.EventKey {
+	if md.adding {
+		switch ek.Key() {
+		case tcell.KeyEnter:
+			if name := strings.TrimSpace(md.input.GetText()); name != "" {
+				md.add(name)
+				idx := slices.IndexFunc(md.names, func(n string) bool { return strings.EqualFold(n, name) })
+				md.checked[md.names[idx]] = true
+				md.render()
+				md.list.Select(idx, 0)
+			}
+			md.stopAdding()
+		case tcell.KeyESC:
+			md.stopAdding()
+		default:
+			// The list keeps the focus: forward the typing to the input field.
+			md.input.InputHandler()(ek, func(tview.Primitive) {})
+		}
+		return nil
+	}
+
+	row, _ := md.list.GetSelection()
+	switch {
+	case ek.Key() == tcell.KeyEnter:
+		md.done()
+	case ek.Key() == tcell.KeyESC:
+		md.cancel()
+	case ek.Rune() == ' ' && row < len(md.names):
+		md.checked[md.names[row]] = !md.checked[md.names[row]]
+		md.render()
+	case ek.Rune() == 'a':
+		md.adding = true
+		md.input.SetText("")
+		md.input.Focus(nil) // show the cursor
+	case (ek.Key() == tcell.KeyUp && ek.Modifiers()&tcell.ModShift != 0) || ek.Rune() == 'K':
+		md.move(row, row-1)
+	case (ek.Key() == tcell.KeyDown && ek.Modifiers()&tcell.ModShift != 0) || ek.Rune() == 'J':
+		md.move(row, row+1)
+	default:
+		return ek
+	}
+	return nil
+}
+
+func (md *Columns) stopAdding() {
+	md.adding = false
+	md.input.SetText("")
+	md.input.Blur()
+}
+
+// move swaps the entry at from with the one at to, keeping it selected.
+func (md *Columns) move(from, to int) {
+	if from < 0 || to < 0 || from >= len(md.names) || to >= len(md.names) {
+		return
+	}
+	md.names[from], md.names[to] = md.names[to], md.names[from]
+	md.render()
+	md.list.Select(to, 0)
+}
+
+func (md *Columns) done() {
+	if md.onDone == nil {
+		return
+	}
+	var cols []string
+	for _, name := range md.names {
+		if md.checked[name] {
+			cols = append(cols, name)
+		}
+	}
+	md.onDone(cols)
+}
+
+func (md *Columns) cancel() {
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
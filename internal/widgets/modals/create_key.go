@@ -15,7 +15,11 @@
 package modals
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/phsym/kmip-explorer/internal/components"
 
@@ -29,6 +33,7 @@ type CreateKey struct {
 	*tview.Flex
 	innerFlex *tview.Flex
 	form      *components.Form
+	dates     []*components.DateField
 	onCancel  func()
 	onDone    func(func(*kmipclient.Client) (kmip.OperationPayload, error))
 }
@@ -42,12 +47,14 @@ func (wg *CreateKey) removeItem(label string) {
 }
 
 func NewCreateKey() *CreateKey {
-	wg := &CreateKey{form: components.NewForm()}
+	wg := &CreateKey{form: components.NewForm(), dates: newScheduleFields()}
 	wg.form.
 		AddInputField("Name", "", 0, nil, nil).
 		// AddCheckbox("Sensitive", false, nil).
 		// AddCheckbox("Extractable", false, nil).
-		AddDropDown("Key Type", nil, 0, nil).
+		AddDropDown("Key Type", nil, 0, nil)
+	wg.addDates()
+	wg.form.
 		AddButton("OK", wg.done).
 		AddButton("Cancel", wg.cancel).
 		SetCancelFunc(wg.cancel).
@@ -55,39 +62,7 @@ func NewCreateKey() *CreateKey {
 
 	wg.form.GetButton(0).SetDisabled(true)
 
-	wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).SetOptions([]string{"AES", "RSA", "EC"}, func(option string, optionIndex int) {
-		wg.removeItem("Key Size")
-		wg.removeItem("Modulus Size")
-		wg.removeItem("Curve Type")
-		// wg.removeItem("Encryption")
-		// wg.removeItem("Key Wrapping")
-		// wg.removeItem("Signature")
-		// wg.Flex.ResizeItem(wg.innerFlex, 15, 0)
-		wg.Flex.ResizeItem(wg.innerFlex, 9, 0)
-		switch option {
-		case "AES":
-			wg.form.AddDropDown("Key Size", []string{"128", "192", "256"}, 0, nil)
-			// wg.form.AddCheckbox("Encryption", true, nil)
-			// wg.form.AddCheckbox("Key Wrapping", false, nil)
-			// wg.Flex.ResizeItem(wg.innerFlex, 19, 0)
-			wg.Flex.ResizeItem(wg.innerFlex, 11, 0)
-		case "RSA":
-			wg.form.AddDropDown("Modulus Size", []string{"2048", "3072", "4096"}, 0, nil)
-			// wg.form.AddCheckbox("Signature", true, nil)
-			// wg.form.AddCheckbox("Encryption", false, nil)
-			// wg.Flex.ResizeItem(wg.innerFlex, 19, 0)
-			wg.Flex.ResizeItem(wg.innerFlex, 11, 0)
-		case "EC":
-			wg.form.AddDropDown("Curve Type", []string{"P-256", "P-384", "P-521"}, 0, nil)
-			// wg.form.AddCheckbox("Signature", true, nil)
-			// wg.Flex.ResizeItem(wg.innerFlex, 17, 0)
-			wg.Flex.ResizeItem(wg.innerFlex, 11, 0)
-		case "":
-		default:
-			panic("Unknown option " + option)
-		}
-		wg.form.GetButton(0).SetDisabled(false)
-	})
+	wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).SetOptions(keyTypes, wg.keyTypeChanged)
 
 	wg.form.Box.SetBorder(true).SetTitle("Create object")
 
@@ -99,7 +74,7 @@ func NewCreateKey() *CreateKey {
 	wg.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(nil, 0, 1, false).
 		// AddItem(wg.innerFlex, 15, 0, true).
-		AddItem(wg.innerFlex, 9, 0, true).
+		AddItem(wg.innerFlex, 9+scheduleHeight, 0, true).
 		AddItem(nil, 0, 1, false)
 	return wg
 }
@@ -109,9 +84,88 @@ func (wg *CreateKey) SetCancelFunc(f func()) *CreateKey {
 	return wg
 }
 
+// keyTypes are the key types offered, when the server supports them.
+var keyTypes = []string{"AES", "RSA", "EC", "HMAC"}
+
+// SetCapabilities offers only the key types the server can create: symmetric
+// keys with Create, key pairs with Create Key Pair.
+func (wg *CreateKey) SetCapabilities(caps Capabilities) *CreateKey {
+	var types []string
+	for _, kty := range keyTypes {
+		switch kty {
+		case "AES", "HMAC":
+			if caps.Supports(kmip.OperationCreate) && caps.SupportsType(kmip.ObjectTypeSymmetricKey) {
+				types = append(types, kty)
+			}
+		default:
+			if caps.Supports(kmip.OperationCreateKeyPair) && caps.SupportsType(kmip.ObjectTypePrivateKey) {
+				types = append(types, kty)
+			}
+		}
+	}
+	wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).SetOptions(types, wg.keyTypeChanged)
+	wg.reset()
+	return wg
+}
+
+func (wg *CreateKey) keyTypeChanged(option string, optionIndex int) {
+	wg.removeItem("Key Size")
+	wg.removeItem("Modulus Size")
+	wg.removeItem("Curve Type")
+	wg.removeItem("Hash")
+	for _, d := range ScheduleDates {
+		wg.removeItem(string(d))
+	}
+	// wg.removeItem("Encryption")
+	// wg.removeItem("Key Wrapping")
+	// wg.removeItem("Signature")
+	// wg.Flex.ResizeItem(wg.innerFlex, 15, 0)
+	wg.Flex.ResizeItem(wg.innerFlex, 9+scheduleHeight, 0)
+	// The dates come after the parameter of the key type.
+	defer wg.addDates()
+	switch option {
+	case "AES":
+		wg.form.AddDropDown("Key Size", createParams[option].options, 0, nil)
+		// wg.form.AddCheckbox("Encryption", true, nil)
+		// wg.form.AddCheckbox("Key Wrapping", false, nil)
+		// wg.Flex.ResizeItem(wg.innerFlex, 19, 0)
+		wg.Flex.ResizeItem(wg.innerFlex, 11+scheduleHeight, 0)
+	case "RSA":
+		wg.form.AddDropDown("Modulus Size", createParams[option].options, 0, nil)
+		// wg.form.AddCheckbox("Signature", true, nil)
+		// wg.form.AddCheckbox("Encryption", false, nil)
+		// wg.Flex.ResizeItem(wg.innerFlex, 19, 0)
+		wg.Flex.ResizeItem(wg.innerFlex, 11+scheduleHeight, 0)
+	case "EC":
+		wg.form.AddDropDown("Curve Type", createParams[option].options, 0, nil)
+		// wg.form.AddCheckbox("Signature", true, nil)
+		// wg.Flex.ResizeItem(wg.innerFlex, 17, 0)
+		wg.Flex.ResizeItem(wg.innerFlex, 11+scheduleHeight, 0)
+	case "HMAC":
+		wg.form.AddDropDown("Hash", createParams[option].options, 0, nil)
+		wg.Flex.ResizeItem(wg.innerFlex, 11+scheduleHeight, 0)
+	default:
+		// No key type chosen yet, as after reset.
+		wg.form.GetButton(0).SetDisabled(true)
+		return
+	}
+	wg.form.GetButton(0).SetDisabled(false)
+}
+
+// addDates adds the fields of the dates to schedule, keeping their text.
+func (wg *CreateKey) addDates() {
+	for _, f := range wg.dates {
+		wg.form.AddFormItem(f)
+	}
+}
+
 func (wg *CreateKey) reset() {
 	wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).SetCurrentOption(-1)
 	wg.form.GetFormItemByLabel("Name").(*tview.InputField).SetText("")
+	for _, f := range wg.dates {
+		f.SetText("")
+	}
+	wg.form.SetTitle("Create object")
 	// wg.form.GetFormItemByLabel("Sensitive").(*tview.Checkbox).SetChecked(false)
 	// wg.form.GetFormItemByLabel("Extractable").(*tview.Checkbox).SetChecked(false)
 
@@ -127,70 +181,133 @@ func (wg *CreateKey) cancel() {
 }
 
 func (wg *CreateKey) done() {
-	defer wg.reset()
-	if wg.onDone == nil {
+	dates, err := scheduleAttributes(wg.dates, time.Now())
+	if err != nil {
+		// Keep the form open so the date can be fixed.
+		wg.form.SetTitle("[red]" + tview.Escape(err.Error()))
 		return
 	}
-	var f func(c *kmipclient.Client) (kmip.OperationPayload, error)
-
 	_, kty := wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).GetCurrentOption()
+	p, ok := createParams[kty]
+	if !ok {
+		wg.form.SetTitle("[red]Choose a key type")
+		return
+	}
 	name := wg.form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
-	switch kty {
+	_, param := wg.form.GetFormItemByLabel(p.label).(*tview.DropDown).GetCurrentOption()
+	f, err := CreateRequest(kty, param, name, dates)
+	if err != nil {
+		wg.form.SetTitle("[red]" + tview.Escape(err.Error()))
+		return
+	}
+	defer wg.reset()
+	if wg.onDone != nil {
+		wg.onDone(f)
+	}
+}
+
+// createParam is the parameter picked for a key type: its size, curve or hash.
+type createParam struct {
+	label   string
+	options []string
+}
+
+// createParams maps the keyTypes to their parameter.
+var createParams = map[string]createParam{
+	"AES":  {"Key Size", []string{"128", "192", "256"}},
+	"RSA":  {"Modulus Size", []string{"2048", "3072", "4096"}},
+	"EC":   {"Curve Type", []string{"P-256", "P-384", "P-521"}},
+	"HMAC": {"Hash", []string{"SHA-256", "SHA-384", "SHA-512"}},
+}
+
+// CreateKeyTypes returns the key types CreateRequest handles, and for each of
+// them the parameter values it accepts.
+func CreateKeyTypes() ([]string, map[string][]string) {
+	params := make(map[string][]string, len(createParams))
+	for kty, p := range createParams {
+		params[kty] = p.options
+	}
+	return keyTypes, params
+}
+
+// CreateRequest builds the request creating a key of the given type (see
+// CreateKeyTypes), where param is its size, curve or hash, e.g. "256", "P-384"
+// or "SHA-512". A non-empty name is given to the key, or to both keys of a pair
+// with a "-Public" or "-Private" suffix. dates, such as the Activation Date, are
+// set on the key, or on both keys of a pair (see ScheduleDates).
+func CreateRequest(keyType, param, name string, dates []kmip.Attribute) (func(*kmipclient.Client) (kmip.OperationPayload, error), error) {
+	p, ok := createParams[keyType]
+	if !ok {
+		return nil, fmt.Errorf("unknown key type %q", keyType)
+	}
+	if !slices.Contains(p.options, param) {
+		return nil, fmt.Errorf("invalid %s %q for %s keys", strings.ToLower(p.label), param, keyType)
+	}
+	switch keyType {
 	case "AES":
-		_, ksize := wg.form.GetFormItemByLabel("Key Size").(*tview.DropDown).GetCurrentOption()
-		size, err := strconv.Atoi(ksize)
-		if err != nil {
-			panic("Invalid AES key size:" + err.Error())
-		}
-		f = func(c *kmipclient.Client) (kmip.OperationPayload, error) {
+		size, _ := strconv.Atoi(param)
+		return func(c *kmipclient.Client) (kmip.OperationPayload, error) {
 			req := c.Create().AES(
 				size,
 				kmip.CryptographicUsageEncrypt|kmip.CryptographicUsageDecrypt|kmip.CryptographicUsageWrapKey|kmip.CryptographicUsageUnwrapKey,
 			)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "RSA":
-		_, ksize := wg.form.GetFormItemByLabel("Modulus Size").(*tview.DropDown).GetCurrentOption()
-		size, err := strconv.Atoi(ksize)
-		if err != nil {
-			panic("Invalid rsa modulus size: " + err.Error())
-		}
-		f = func(c *kmipclient.Client) (kmip.OperationPayload, error) {
+		size, _ := strconv.Atoi(param)
+		return func(c *kmipclient.Client) (kmip.OperationPayload, error) {
 			req := c.CreateKeyPair().RSA(size, kmip.CryptographicUsageSign|kmip.CryptographicUsageDecrypt, kmip.CryptographicUsageVerify|kmip.CryptographicUsageEncrypt)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.PublicKey().WithName(name + "-Public").
 					PrivateKey().WithName(name + "-Private")
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "EC":
-		_, crv := wg.form.GetFormItemByLabel("Curve Type").(*tview.DropDown).GetCurrentOption()
-		var curve kmip.RecommendedCurve
-		switch crv {
-		case "P-256":
-			curve = kmip.RecommendedCurveP_256
-		case "P-384":
-			curve = kmip.RecommendedCurveP_384
-		case "P-521":
-			curve = kmip.RecommendedCurveP_521
-		default:
-			panic("Invalid EC curve " + crv)
-		}
-		f = func(c *kmipclient.Client) (kmip.OperationPayload, error) {
+		curve := map[string]kmip.RecommendedCurve{
+			"P-256": kmip.RecommendedCurveP_256,
+			"P-384": kmip.RecommendedCurveP_384,
+			"P-521": kmip.RecommendedCurveP_521,
+		}[param]
+		return func(c *kmipclient.Client) (kmip.OperationPayload, error) {
 			req := c.CreateKeyPair().ECDSA(curve, kmip.CryptographicUsageSign, kmip.CryptographicUsageVerify)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.PublicKey().WithName(name + "-Public").
 					PrivateKey().WithName(name + "-Private")
 			}
 			return req.Exec()
-		}
-	default:
-		panic("Unexpected key type " + kty)
+		}, nil
+	default: // HMAC
+		// The key is as long as the digest, as recommended by RFC 2104.
+		size, _ := strconv.Atoi(strings.TrimPrefix(param, "SHA-"))
+		alg := map[string]kmip.CryptographicAlgorithm{
+			"SHA-256": kmip.CryptographicAlgorithmHMACSHA256,
+			"SHA-384": kmip.CryptographicAlgorithmHMACSHA384,
+			"SHA-512": kmip.CryptographicAlgorithmHMACSHA512,
+		}[param]
+		return func(c *kmipclient.Client) (kmip.OperationPayload, error) {
+			req := c.Create().SymmetricKey(alg, size, kmip.CryptographicUsageMACGenerate|kmip.CryptographicUsageMACVerify)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
+			if name != "" {
+				req = req.WithName(name)
+			}
+			return req.Exec()
+		}, nil
 	}
-	wg.onDone(f)
 }
 
 func (wg *CreateKey) SetDoneFunc(f func(func(*kmipclient.Client) (kmip.OperationPayload, error))) *CreateKey {
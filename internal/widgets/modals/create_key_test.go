@@ -0,0 +1,51 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/rivo/tview"
+)
+
+func TestCreateKeyNeedsAKeyType(t *testing.T) {
+	done := 0
+	wg := NewCreateKey().
+		SetDoneFunc(func(func(*kmipclient.Client) (kmip.OperationPayload, error)) { done++ }).
+		SetCapabilities(Capabilities{})
+	if !wg.form.GetButton(0).IsDisabled() {
+		t.Fatal("OK is enabled before a key type is chosen")
+	}
+	// Enter may still reach the button: the form stays open.
+	wg.done()
+	if done != 0 || !strings.Contains(wg.form.GetTitle(), "Choose a key type") {
+		t.Fatalf("done without a key type: %d calls, title %q", done, wg.form.GetTitle())
+	}
+
+	wg.form.GetFormItemByLabel("Key Type").(*tview.DropDown).SetCurrentOption(0)
+	if wg.form.GetButton(0).IsDisabled() {
+		t.Fatal("OK is disabled once a key type is chosen")
+	}
+	wg.done()
+	if done != 1 {
+		t.Fatalf("done with a key type: %d calls, want 1", done)
+	}
+	if !wg.form.GetButton(0).IsDisabled() {
+		t.Fatal("OK is enabled again once the form is reset")
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/phsym/kmip-explorer/internal/components"
+
+	"github.com/atotto/clipboard"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/rivo/tview"
+)
+
+// Data formats offered for the workbench input and output.
+const (
+	formatText   = "Text"
+	formatHex    = "Hex"
+	formatBase64 = "Base64"
+	formatTTLV   = "TTLV"
+)
+
+// enumOptions lists the named values of an enumeration, from 1 to last, for a
+// dropdown. The first option, "Default", leaves the parameter to the server.
+func enumOptions[E ~uint32](last E) ([]string, []E) {
+	labels, values := []string{"Default"}, []E{0}
+	for v := E(1); v <= last; v++ {
+		// Values the library doesn't know are rendered as hex numbers.
+		if name := ttlv.EnumStr(v); !strings.HasPrefix(name, "0x") {
+			labels = append(labels, name)
+			values = append(values, v)
+		}
+	}
+	return labels, values
+}
+
+var (
+	blockCipherModeLabels, blockCipherModes = enumOptions(kmip.BlockCipherMode(0x12))
+	paddingMethodLabels, paddingMethods     = enumOptions(kmip.PaddingMethod(0x0A))
+	hashingAlgorithmLabels, hashingAlgs     = enumOptions(kmip.HashingAlgorithm(0x11))
+	signatureAlgorithmLabels, signatureAlgs = enumOptions(kmip.DigitalSignatureAlgorithm(0x13))
+)
+
+// Form fields of the workbench. They are created once and shown depending on
+// the operation (see cryptoOperations), so their values survive switching
+// operations.
+const (
+	fieldVerifyKey    = "Public key"
+	fieldMode         = "Block cipher mode"
+	fieldPadding      = "Padding method"
+	fieldHashing      = "Hashing algorithm"
+	fieldSignatureAlg = "Signature algorithm"
+	fieldInputFormat  = "Input format"
+	fieldInput        = "Input"
+	fieldIV           = "IV/Nonce (hex)"
+	fieldAAD          = "Additional data (hex)"
+	fieldTagLength    = "Tag length (bytes)"
+	fieldTag          = "Tag (hex)"
+	fieldSignature    = "Signature (hex)"
+	fieldMAC          = "MAC (hex)"
+	fieldOutputFormat = "Output format"
+)
+
+// cryptoOperation is an operation of the workbench, with the fields it uses.
+type cryptoOperation struct {
+	name   string
+	op     kmip.Operation
+	fields []string
+}
+
+// cryptoOperations lists the operations of the workbench, in menu order.
+var cryptoOperations = []cryptoOperation{
+	{"Encrypt", kmip.OperationEncrypt, []string{fieldMode, fieldPadding, fieldHashing, fieldInputFormat, fieldInput, fieldIV, fieldAAD, fieldTagLength, fieldOutputFormat}},
+	{"Decrypt", kmip.OperationDecrypt, []string{fieldMode, fieldPadding, fieldHashing, fieldInputFormat, fieldInput, fieldIV, fieldAAD, fieldTag, fieldOutputFormat}},
+	{"Sign", kmip.OperationSign, []string{fieldSignatureAlg, fieldPadding, fieldHashing, fieldInputFormat, fieldInput, fieldOutputFormat}},
+	{"Signature Verify", kmip.OperationSignatureVerify, []string{fieldVerifyKey, fieldSignatureAlg, fieldPadding, fieldHashing, fieldInputFormat, fieldInput, fieldSignature}},
+	{"MAC", kmip.OperationMAC, []string{fieldMode, fieldHashing, fieldInputFormat, fieldInput, fieldOutputFormat}},
+	{"MAC Verify", kmip.OperationMACVerify, []string{fieldMode, fieldHashing, fieldInputFormat, fieldInput, fieldMAC}},
+}
+
+// Crypto is a workbench running cryptographic operations with the selected key
+// on the server: Encrypt, Decrypt, Sign, Signature Verify, MAC and MAC Verify.
+type Crypto struct {
+	*tview.Flex
+	form      *components.Form
+	operation *tview.DropDown
+	ops       []cryptoOperation // the operations offered, see SetCapabilities
+	inputs    map[string]*tview.InputField
+	dropDowns map[string]*tview.DropDown
+	output    *tview.TextView
+	id        string
+	result    kmip.OperationPayload
+	err       error
+	onCancel  func()
+	onDone    func(func(*kmipclient.Client) (kmip.OperationPayload, error))
+}
+
+func NewCrypto() *Crypto {
+	md := &Crypto{
+		inputs:    map[string]*tview.InputField{},
+		dropDowns: map[string]*tview.DropDown{},
+	}
+	md.output = tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	md.output.SetBorder(true).SetTitle("Output")
+
+	addDropDown := func(label string, options []string, selected func(string, int)) {
+		md.dropDowns[label] = tview.NewDropDown().SetLabel(label).SetOptions(options, selected).SetCurrentOption(0)
+	}
+	addInput := func(label string, accept func(string, rune) bool) {
+		md.inputs[label] = tview.NewInputField().SetLabel(label).SetAcceptanceFunc(accept)
+	}
+	addInput(fieldVerifyKey, nil)
+	addDropDown(fieldMode, blockCipherModeLabels, nil)
+	addDropDown(fieldPadding, paddingMethodLabels, nil)
+	addDropDown(fieldHashing, hashingAlgorithmLabels, nil)
+	addDropDown(fieldSignatureAlg, signatureAlgorithmLabels, nil)
+	addDropDown(fieldInputFormat, []string{formatText, formatHex, formatBase64}, nil)
+	addInput(fieldInput, nil)
+	addInput(fieldIV, nil)
+	addInput(fieldAAD, nil)
+	addInput(fieldTagLength, func(textToCheck string, _ rune) bool {
+		_, err := strconv.ParseUint(textToCheck, 10, 8)
+		return textToCheck == "" || err == nil
+	})
+	addInput(fieldTag, nil)
+	addInput(fieldSignature, nil)
+	addInput(fieldMAC, nil)
+	addDropDown(fieldOutputFormat, []string{formatHex, formatBase64, formatText, formatTTLV}, func(string, int) { md.render() })
+
+	md.operation = tview.NewDropDown().SetLabel("Operation")
+	md.form = components.NewForm()
+	md.form.AddButton("Run", md.done).
+		AddButton("Copy", md.copy).
+		AddButton("Close", md.cancel).
+		SetCancelFunc(md.cancel).
+		SetButtonsAlign(tview.AlignCenter)
+	md.form.SetBorder(true)
+	md.SetCapabilities(Capabilities{})
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.form, 0, 3, true).
+			AddItem(md.output, 0, 3, false).
+			AddItem(nil, 0, 1, false),
+			0, 10, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+// SetCapabilities offers only the operations the server supports.
+func (md *Crypto) SetCapabilities(caps Capabilities) *Crypto {
+	md.ops = md.ops[:0]
+	var names []string
+	for _, op := range cryptoOperations {
+		if caps.Supports(op.op) {
+			md.ops = append(md.ops, op)
+			names = append(names, op.name)
+		}
+	}
+	md.operation.SetOptions(names, func(string, int) { md.operationChanged() }).SetCurrentOption(0)
+	return md
+}
+
+func (md *Crypto) OnCancel(cb func()) *Crypto {
+	md.onCancel = cb
+	return md
+}
+
+// OnDone sets the callback receiving the request to run when the user hits
+// "Run". Hand its response back with SetResult.
+func (md *Crypto) OnDone(cb func(func(*kmipclient.Client) (kmip.OperationPayload, error))) *Crypto {
+	md.onDone = cb
+	return md
+}
+
+// SetObject prepares the workbench for the given key, clearing the previous
+// output. The operation defaults to one the key can do, and signatures are
+// verified with its linked public key. The rest of the form is kept, so a series
+// of operations can be run on several keys.
+func (md *Crypto) SetObject(obj *payloads.GetAttributesResponsePayload) *Crypto {
+	md.id = obj.UniqueIdentifier
+	md.form.SetTitle(tview.Escape("Crypto: " + md.id))
+	md.result, md.err = nil, nil
+	md.output.SetText("")
+
+	verifyKey := md.id
+	op := "Encrypt"
+	for _, attr := range obj.Attribute {
+		switch v := attr.AttributeValue.(type) {
+		case kmip.Link:
+			if v.LinkType == kmip.LinkTypePublicKeyLink {
+				verifyKey = v.LinkedObjectIdentifier
+			}
+		case kmip.ObjectType:
+			switch v {
+			case kmip.ObjectTypePrivateKey:
+				op = "Sign"
+			case kmip.ObjectTypePublicKey:
+				op = "Signature Verify"
+			}
+		case kmip.CryptographicAlgorithm:
+			switch v {
+			case kmip.CryptographicAlgorithmHMACSHA1, kmip.CryptographicAlgorithmHMACSHA224, kmip.CryptographicAlgorithmHMACSHA256,
+				kmip.CryptographicAlgorithmHMACSHA384, kmip.CryptographicAlgorithmHMACSHA512:
+				op = "MAC"
+			}
+		}
+	}
+	md.inputs[fieldVerifyKey].SetText(verifyKey)
+	md.operation.SetCurrentOption(max(slices.IndexFunc(md.ops, func(o cryptoOperation) bool { return o.name == op }), 0))
+	md.form.SetFocus(0)
+	return md
+}
+
+// SetResult shows the response of the last operation, or its error. A new
+// signature or MAC is also copied to its field, ready to be verified.
+func (md *Crypto) SetResult(result kmip.OperationPayload, err error) {
+	md.result, md.err = result, err
+	switch r := result.(type) {
+	case *payloads.SignResponsePayload:
+		md.inputs[fieldSignature].SetText(hex.EncodeToString(r.SignatureData))
+	case *payloads.MACResponsePayload:
+		md.inputs[fieldMAC].SetText(hex.EncodeToString(r.MACData))
+	}
+	md.render()
+}
+
+func (md *Crypto) dropDown(label string) (int, string) {
+	return md.dropDowns[label].GetCurrentOption()
+}
+
+// operationChanged shows the fields used by the selected operation.
+func (md *Crypto) operationChanged() {
+	idx, _ := md.operation.GetCurrentOption()
+	md.form.Clear(false).AddFormItem(md.operation)
+	if idx < 0 || idx >= len(md.ops) {
+		return
+	}
+	for _, label := range md.ops[idx].fields {
+		if in, ok := md.inputs[label]; ok {
+			md.form.AddFormItem(in)
+		} else {
+			md.form.AddFormItem(md.dropDowns[label])
+		}
+	}
+}
+
+func (md *Crypto) done() {
+	if md.onDone == nil {
+		return
+	}
+	req, err := md.request()
+	if err != nil {
+		md.SetResult(nil, err)
+		return
+	}
+	md.result, md.err = nil, nil
+	md.output.SetText("[gray]Running…")
+	md.onDone(func(c *kmipclient.Client) (kmip.OperationPayload, error) {
+		return c.Request(context.Background(), req)
+	})
+}
+
+// request builds the request payload of the selected operation from the form.
+func (md *Crypto) request() (kmip.OperationPayload, error) {
+	_, inFormat := md.dropDown(fieldInputFormat)
+	data, err := decodeData(inFormat, md.inputs[fieldInput].GetText())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid input: %w", err)
+	}
+	iv, err := decodeData(formatHex, md.inputs[fieldIV].GetText())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid IV/Nonce: %w", err)
+	}
+	aad, err := decodeData(formatHex, md.inputs[fieldAAD].GetText())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid additional data: %w", err)
+	}
+
+	params := md.cryptographicParameters()
+	switch _, op := md.operation.GetCurrentOption(); op {
+	case "Encrypt":
+		if txt := md.inputs[fieldTagLength].GetText(); txt != "" {
+			n, err := strconv.ParseInt(txt, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid tag length: %w", err)
+			}
+			tagLen := int32(n)
+			params.TagLength = &tagLen
+		}
+		return &payloads.EncryptRequestPayload{
+			UniqueIdentifier:                      md.id,
+			CryptographicParameters:               nilIfEmpty(params),
+			Data:                                  data,
+			IVCounterNonce:                        iv,
+			AuthenticatedEncryptionAdditionalData: aad,
+		}, nil
+	case "Decrypt":
+		tag, err := decodeData(formatHex, md.inputs[fieldTag].GetText())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag: %w", err)
+		}
+		return &payloads.DecryptRequestPayload{
+			UniqueIdentifier:                      md.id,
+			CryptographicParameters:               nilIfEmpty(params),
+			Data:                                  data,
+			IVCounterNonce:                        iv,
+			AuthenticatedEncryptionAdditionalData: aad,
+			AuthenticatedEncryptionTag:            tag,
+		}, nil
+	case "Sign":
+		return &payloads.SignRequestPayload{
+			UniqueIdentifier:        md.id,
+			CryptographicParameters: nilIfEmpty(params),
+			Data:                    data,
+		}, nil
+	case "MAC":
+		return &payloads.MACRequestPayload{
+			UniqueIdentifier:        md.id,
+			CryptographicParameters: nilIfEmpty(params),
+			Data:                    data,
+		}, nil
+	case "MAC Verify":
+		mac, err := decodeData(formatHex, md.inputs[fieldMAC].GetText())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid MAC: %w", err)
+		}
+		return &payloads.MACVerifyRequestPayload{
+			UniqueIdentifier:        md.id,
+			CryptographicParameters: nilIfEmpty(params),
+			Data:                    data,
+			MACData:                 mac,
+		}, nil
+	default:
+		sig, err := decodeData(formatHex, md.inputs[fieldSignature].GetText())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid signature: %w", err)
+		}
+		key := strings.TrimSpace(md.inputs[fieldVerifyKey].GetText())
+		if key == "" {
+			return nil, errors.New("The public key to verify with is missing")
+		}
+		return &payloads.SignatureVerifyRequestPayload{
+			UniqueIdentifier:        key,
+			CryptographicParameters: nilIfEmpty(params),
+			Data:                    data,
+			SignatureData:           sig,
+		}, nil
+	}
+}
+
+// cryptographicParameters returns the parameters picked in the dropdowns shown
+// for the selected operation.
+func (md *Crypto) cryptographicParameters() *kmip.CryptographicParameters {
+	params := &kmip.CryptographicParameters{}
+	for i := range md.form.GetFormItemCount() {
+		switch item := md.form.GetFormItem(i); item {
+		case md.dropDowns[fieldMode]:
+			idx, _ := md.dropDown(fieldMode)
+			params.BlockCipherMode = blockCipherModes[max(idx, 0)]
+		case md.dropDowns[fieldPadding]:
+			idx, _ := md.dropDown(fieldPadding)
+			params.PaddingMethod = paddingMethods[max(idx, 0)]
+		case md.dropDowns[fieldHashing]:
+			idx, _ := md.dropDown(fieldHashing)
+			params.HashingAlgorithm = hashingAlgs[max(idx, 0)]
+		case md.dropDowns[fieldSignatureAlg]:
+			idx, _ := md.dropDown(fieldSignatureAlg)
+			params.DigitalSignatureAlgorithm = signatureAlgs[max(idx, 0)]
+		}
+	}
+	return params
+}
+
+// nilIfEmpty returns nil for parameters left entirely to the server, so that
+// the request doesn't carry an empty structure.
+func nilIfEmpty(params *kmip.CryptographicParameters) *kmip.CryptographicParameters {
+	if *params == (kmip.CryptographicParameters{}) {
+		return nil
+	}
+	return params
+}
+
+// outputs returns the labelled byte strings of the result worth showing.
+func (md *Crypto) outputs() (labels []string, values [][]byte) {
+	switch r := md.result.(type) {
+	case *payloads.EncryptResponsePayload:
+		labels, values = append(labels, "Data"), append(values, r.Data)
+		if len(r.IVCounterNonce) > 0 {
+			labels, values = append(labels, "IV/Nonce"), append(values, r.IVCounterNonce)
+		}
+		if len(r.AuthenticatedEncryptionTag) > 0 {
+			labels, values = append(labels, "Tag"), append(values, r.AuthenticatedEncryptionTag)
+		}
+	case *payloads.DecryptResponsePayload:
+		labels, values = append(labels, "Data"), append(values, r.Data)
+	case *payloads.SignResponsePayload:
+		labels, values = append(labels, "Signature"), append(values, r.SignatureData)
+	case *payloads.MACResponsePayload:
+		labels, values = append(labels, "MAC"), append(values, r.MACData)
+	}
+	return labels, values
+}
+
+func (md *Crypto) render() {
+	if md.output == nil {
+		return
+	}
+	if md.err != nil {
+		md.output.SetText("[red]Error:[-] " + tview.Escape(md.err.Error()))
+		return
+	}
+	if md.result == nil {
+		return
+	}
+	_, format := md.dropDown(fieldOutputFormat)
+	if format == formatTTLV {
+		md.output.SetText(tview.Escape(string(ttlv.MarshalText(md.result))))
+		return
+	}
+	strBld := strings.Builder{}
+	switch r := md.result.(type) {
+	case *payloads.SignatureVerifyResponsePayload:
+		strBld.WriteString(validity("Signature", r.ValidityIndicator))
+	case *payloads.MACVerifyResponsePayload:
+		strBld.WriteString(validity("MAC", r.ValidityIndicator))
+	}
+	labels, values := md.outputs()
+	for i, label := range labels {
+		if i > 0 {
+			strBld.WriteString("\n\n")
+		}
+		strBld.WriteString("[green]" + label + ":[-]\n")
+		strBld.WriteString(tview.Escape(encodeData(format, values[i])))
+	}
+	md.output.SetText(strBld.String())
+}
+
+// validity renders a verification result, in green when valid.
+func validity(what string, v kmip.ValidityIndicator) string {
+	color := "red"
+	if v == kmip.ValidityIndicatorValid {
+		color = "green"
+	}
+	return fmt.Sprintf("[%s::b]%s %s[-::-]", color, what, ttlv.EnumStr(v))
+}
+
+// copy copies the output data (or the whole TTLV output) to the clipboard.
+func (md *Crypto) copy() {
+	if md.result == nil {
+		return
+	}
+	_, format := md.dropDown(fieldOutputFormat)
+	text := md.output.GetText(true)
+	if _, values := md.outputs(); format != formatTTLV && len(values) > 0 {
+		text = encodeData(format, values[0])
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		md.output.SetTitle("Output (copy failed: " + tview.Escape(err.Error()) + ")")
+		return
+	}
+	md.output.SetTitle("Output (copied)")
+}
+
+func (md *Crypto) cancel() {
+	md.output.SetTitle("Output")
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
+
+// decodeData decodes text entered in the given format. Whitespace is ignored in
+// hex and base64. Empty text gives nil, leaving optional fields out of requests.
+func decodeData(format, text string) ([]byte, error) {
+	if format != formatText {
+		text = strings.Join(strings.Fields(text), "")
+	}
+	if text == "" {
+		return nil, nil
+	}
+	switch format {
+	case formatHex:
+		return hex.DecodeString(text)
+	case formatBase64:
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
+
+// encodeData renders data in the given format. Text that isn't valid UTF-8 is
+// shown as hex instead, like the key material.
+func encodeData(format string, data []byte) string {
+	switch format {
+	case formatBase64:
+		return base64.StdEncoding.EncodeToString(data)
+	case formatText:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestDecodeData(t *testing.T) {
+	for _, tt := range []struct {
+		format, text string
+		want         []byte
+	}{
+		{formatText, "hi there", []byte("hi there")},
+		{formatText, " padded ", []byte(" padded ")},
+		{formatHex, "6869", []byte("hi")},
+		{formatHex, "68 69\n", []byte("hi")},
+		{formatHex, "DEADbeef", []byte{0xde, 0xad, 0xbe, 0xef}},
+		{formatBase64, "aGk=", []byte("hi")},
+		{formatBase64, "aG\tk=", []byte("hi")},
+		{formatText, "", nil},
+		{formatHex, "  ", nil},
+	} {
+		got, err := decodeData(tt.format, tt.text)
+		if err != nil || !bytes.Equal(got, tt.want) || (tt.want == nil) != (got == nil) {
+			t.Fatalf("decodeData(%s, %q) = %v, %v, want %v", tt.format, tt.text, got, err, tt.want)
+		}
+	}
+	for _, tt := range []struct{ format, text string }{
+		{formatHex, "abc"},
+		{formatHex, "zz"},
+		{formatBase64, "aGk"},
+		{formatBase64, "a*k="},
+	} {
+		if got, err := decodeData(tt.format, tt.text); err == nil {
+			t.Fatalf("decodeData(%s, %q) = %v, want an error", tt.format, tt.text, got)
+		}
+	}
+}
+
+// newCrypto returns a workbench on key "key", linked to public key "pub", with
+// the given operation selected and the inputs set.
+func newCrypto(t *testing.T, op string, inputs map[string]string) *Crypto {
+	t.Helper()
+	md := NewCrypto().SetObject(&payloads.GetAttributesResponsePayload{
+		UniqueIdentifier: "key",
+		Attribute: []kmip.Attribute{{
+			AttributeName:  kmip.AttributeNameLink,
+			AttributeValue: kmip.Link{LinkType: kmip.LinkTypePublicKeyLink, LinkedObjectIdentifier: "pub"},
+		}},
+	})
+	idx := slices.IndexFunc(md.ops, func(o cryptoOperation) bool { return o.name == op })
+	if idx < 0 {
+		t.Fatalf("operation %s not offered", op)
+	}
+	md.operation.SetCurrentOption(idx)
+	for label, text := range inputs {
+		md.inputs[label].SetText(text)
+	}
+	return md
+}
+
+func TestCryptoRequest(t *testing.T) {
+	tagLength := int32(16)
+	for _, tt := range []struct {
+		op          string
+		inputFormat int // index in the input formats: text, hex, base64
+		mode        kmip.BlockCipherMode
+		inputs      map[string]string
+		want        kmip.OperationPayload
+	}{
+		{
+			op:     "Encrypt",
+			mode:   kmip.BlockCipherModeGCM,
+			inputs: map[string]string{fieldInput: "hello", fieldIV: "00 01", fieldAAD: "ff", fieldTagLength: "16"},
+			want: &payloads.EncryptRequestPayload{
+				UniqueIdentifier:                      "key",
+				CryptographicParameters:               &kmip.CryptographicParameters{BlockCipherMode: kmip.BlockCipherModeGCM, TagLength: &tagLength},
+				Data:                                  []byte("hello"),
+				IVCounterNonce:                        []byte{0, 1},
+				AuthenticatedEncryptionAdditionalData: []byte{0xff},
+			},
+		},
+		{
+			op:          "Decrypt",
+			inputFormat: 1,
+			inputs:      map[string]string{fieldInput: "6869", fieldTag: "aa"},
+			want: &payloads.DecryptRequestPayload{
+				UniqueIdentifier:           "key",
+				Data:                       []byte("hi"),
+				AuthenticatedEncryptionTag: []byte{0xaa},
+			},
+		},
+		{
+			op:          "Sign",
+			inputFormat: 2,
+			inputs:      map[string]string{fieldInput: "aGk="},
+			want:        &payloads.SignRequestPayload{UniqueIdentifier: "key", Data: []byte("hi")},
+		},
+		{
+			op:     "Signature Verify",
+			inputs: map[string]string{fieldInput: "hi", fieldSignature: "01 02"},
+			want:   &payloads.SignatureVerifyRequestPayload{UniqueIdentifier: "pub", Data: []byte("hi"), SignatureData: []byte{1, 2}},
+		},
+		{
+			op:     "MAC",
+			inputs: map[string]string{fieldInput: "hi"},
+			want:   &payloads.MACRequestPayload{UniqueIdentifier: "key", Data: []byte("hi")},
+		},
+		{
+			op:     "MAC Verify",
+			inputs: map[string]string{fieldInput: "hi", fieldMAC: "02"},
+			want:   &payloads.MACVerifyRequestPayload{UniqueIdentifier: "key", Data: []byte("hi"), MACData: []byte{2}},
+		},
+	} {
+		md := newCrypto(t, tt.op, tt.inputs)
+		md.dropDowns[fieldInputFormat].SetCurrentOption(tt.inputFormat)
+		md.dropDowns[fieldMode].SetCurrentOption(slices.Index(blockCipherModes, tt.mode))
+		got, err := md.request()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.op, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: request = %+v, want %+v", tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestCryptoRequestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		op     string
+		inputs map[string]string
+		want   string
+	}{
+		{"Encrypt", map[string]string{fieldIV: "zz"}, "Invalid IV/Nonce"},
+		{"Encrypt", map[string]string{fieldAAD: "0"}, "Invalid additional data"},
+		{"Decrypt", map[string]string{fieldTag: "xyz"}, "Invalid tag"},
+		{"Signature Verify", map[string]string{fieldSignature: "g"}, "Invalid signature"},
+		{"Signature Verify", map[string]string{fieldVerifyKey: " "}, "public key to verify with is missing"},
+		{"MAC Verify", map[string]string{fieldMAC: "1"}, "Invalid MAC"},
+	} {
+		md := newCrypto(t, tt.op, tt.inputs)
+		if _, err := md.request(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s with %v: error = %v, want %q", tt.op, tt.inputs, err, tt.want)
+		}
+	}
+	md := newCrypto(t, "Encrypt", map[string]string{fieldInput: "zz"})
+	md.dropDowns[fieldInputFormat].SetCurrentOption(1)
+	if _, err := md.request(); err == nil || !strings.Contains(err.Error(), "Invalid input") {
+		t.Fatalf("hex input zz: error = %v, want an invalid input", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phsym/kmip-explorer/internal/components"
+
+	"github.com/rivo/tview"
+)
+
+// Export asks for the format and the file to export the object list to.
+type Export struct {
+	*tview.Flex
+	form     *components.Form
+	formats  []string
+	onCancel func()
+	onDone   func(format, path string)
+}
+
+// NewExport builds the export dialog, offering the given formats (e.g. "JSON"),
+// whose lowercased name is the extension of the file.
+func NewExport(formats []string) *Export {
+	md := &Export{formats: formats}
+	md.form = components.NewForm()
+	md.form.AddDropDown("Format", formats, 0, md.formatChanged).
+		AddInputField("File", "", 0, nil, nil).
+		AddButton("OK", md.done).
+		AddButton("Cancel", md.cancel).
+		SetCancelFunc(md.cancel).
+		SetButtonsAlign(tview.AlignCenter)
+	md.form.Box.SetBorder(true).SetTitle("Export the object list")
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.form, 0, 2, true).
+			AddItem(nil, 0, 1, false),
+			9, 0, true).
+		AddItem(nil, 0, 1, false)
+	md.reset()
+	return md
+}
+
+func (md *Export) OnCancel(cb func()) *Export {
+	md.onCancel = cb
+	return md
+}
+
+func (md *Export) OnDone(cb func(format, path string)) *Export {
+	md.onDone = cb
+	return md
+}
+
+// formatChanged follows the format with the extension of the file.
+func (md *Export) formatChanged(format string, _ int) {
+	// Not there yet while the form is built.
+	file, ok := md.form.GetFormItemByLabel("File").(*tview.InputField)
+	if !ok {
+		return
+	}
+	path := file.GetText()
+	if path == "" {
+		return
+	}
+	file.SetText(strings.TrimSuffix(path, filepath.Ext(path)) + "." + strings.ToLower(format))
+}
+
+// reset proposes a new file, named after the current time.
+func (md *Export) reset() {
+	md.form.SetFocus(0)
+	_, format := md.form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+	md.form.GetFormItemByLabel("File").(*tview.InputField).
+		SetText("kmip-export-" + time.Now().Format("20060102-150405") + "." + strings.ToLower(format))
+}
+
+func (md *Export) cancel() {
+	defer md.reset()
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
+
+func (md *Export) done() {
+	defer md.reset()
+	if md.onDone == nil {
+		return
+	}
+	_, format := md.form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+	path := strings.TrimSpace(md.form.GetFormItemByLabel("File").(*tview.InputField).GetText())
+	md.onDone(format, path)
+}
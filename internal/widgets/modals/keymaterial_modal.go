@@ -65,37 +65,38 @@ func (wg *KeyMaterial) updateContent() {
 		return
 	}
 
-	var content string
-	var err error
-	switch obj := wg.obj.(type) {
+	content, err := ObjectContent(wg.obj)
+	if err != nil {
+		content = "Error:" + err.Error()
+	}
+	wg.content.SetText(content)
+}
+
+// ObjectContent renders the material of an object: the value of a secret as
+// text (or hex if it isn't), symmetric keys in hex, certificates and asymmetric
+// keys in PEM format, and any other object as TTLV text.
+func ObjectContent(obj kmip.Object) (string, error) {
+	switch obj := obj.(type) {
 	case *kmip.SecretData:
-		var data []byte
-		data, err = obj.Data()
+		data, err := obj.Data()
 		if err != nil {
-			break
+			return "", err
 		}
 		if utf8.Valid(data) {
-			content = string(data)
-		} else {
-			content = hex.EncodeToString(data)
+			return string(data), nil
 		}
+		return hex.EncodeToString(data), nil
 	case *kmip.SymmetricKey:
-		var data []byte
-		data, err = obj.KeyMaterial()
-		content = hex.EncodeToString(data)
+		data, err := obj.KeyMaterial()
+		return hex.EncodeToString(data), err
 	case *kmip.Certificate:
-		content, err = obj.PemCertificate()
+		return obj.PemCertificate()
 	case *kmip.PrivateKey:
-		content, err = obj.Pkcs8Pem()
+		return obj.Pkcs8Pem()
 	case *kmip.PublicKey:
-		content, err = obj.PkixPem()
-	default:
-		content = string(ttlv.MarshalText(obj))
+		return obj.PkixPem()
 	}
-	if err != nil {
-		content = "Error:" + err.Error()
-	}
-	wg.content.SetText(content)
+	return string(ttlv.MarshalText(obj)), nil
 }
 
 func (wg *KeyMaterial) OnDone(cb func()) *KeyMaterial {
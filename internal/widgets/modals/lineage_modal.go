@@ -0,0 +1,248 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/rivo/tview"
+)
+
+// Generation is a key of a rekey lineage shown by [Lineage].
+type Generation struct {
+	ID string
+	// Attributes are those of the key, nil when not loaded; Err tells why they
+	// couldn't be.
+	Attributes *payloads.GetAttributesResponsePayload
+	Err        error
+}
+
+// Date returns the date attribute name of the key, zero if it has none.
+func (g *Generation) Date(name kmip.AttributeName) time.Time {
+	if g.Attributes == nil {
+		return time.Time{}
+	}
+	for _, attr := range g.Attributes.Attribute {
+		if t, ok := attr.AttributeValue.(time.Time); ok && attr.AttributeName == name {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Lineage shows the generations of a key, oldest first, as linked by Rekey with
+// Replaced and Replacement Object links, with the dates of their lifecycle.
+// Selecting a generation jumps to it.
+type Lineage struct {
+	*tview.Flex
+	frame       *tview.Flex
+	table       *tview.Table
+	summary     *tview.TextView
+	id          string
+	generations []*Generation
+	onDone      func(id string)
+	onCancel    func()
+}
+
+func NewLineage() *Lineage {
+	md := &Lineage{}
+	md.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0).
+		SetSelectedFunc(func(row, _ int) {
+			if row >= 1 && row <= len(md.generations) && md.onDone != nil {
+				md.onDone(md.generations[row-1].ID)
+			}
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape && md.onCancel != nil {
+				md.onCancel()
+			}
+		})
+	md.summary = tview.NewTextView().SetDynamicColors(true)
+
+	md.frame = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(md.table, 0, 1, true).
+		AddItem(md.summary, 2, 0, false)
+	md.frame.SetBorder(true).SetTitle("Rekey lineage")
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.frame, 0, 6, true).
+			AddItem(nil, 0, 1, false),
+			0, 4, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+// OnDone is called with the id of the generation selected.
+func (md *Lineage) OnDone(cb func(id string)) *Lineage {
+	md.onDone = cb
+	return md
+}
+
+func (md *Lineage) OnCancel(cb func()) *Lineage {
+	md.onCancel = cb
+	return md
+}
+
+// ObjectID returns the id of the key whose lineage is shown.
+func (md *Lineage) ObjectID() string {
+	return md.id
+}
+
+// SetLoading shows the key id while its lineage is being loaded.
+func (md *Lineage) SetLoading(id string) {
+	md.id = id
+	md.generations = nil
+	md.table.Clear()
+	md.summary.SetText(fmt.Sprintf("[gray]Loading the lineage of %s…[-]", tview.Escape(id)))
+	md.setTitle("")
+}
+
+// SetLineage shows the generations of the key id, oldest first.
+func (md *Lineage) SetLineage(id string, generations []*Generation) {
+	md.id = id
+	md.generations = generations
+	md.table.Clear()
+	for col, title := range []string{"#", "ID", "State", "Initial", "Activation", "Deactivation", "Destroy", "In use"} {
+		md.table.SetCell(0, col, tview.NewTableCell(title).SetSelectable(false).
+			SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold))
+	}
+	now := time.Now()
+	var name string
+	selected := 1
+	for i, g := range generations {
+		row := i + 1
+		if g.ID == id {
+			selected = row
+		}
+		md.table.SetCell(row, 0, tview.NewTableCell(fmt.Sprint(row))).
+			SetCell(row, 1, tview.NewTableCell(tview.Escape(g.ID)).SetExpansion(1))
+		if g.Err != nil {
+			md.table.SetCell(row, 2, tview.NewTableCell(tview.Escape(g.Err.Error())).SetTextColor(tcell.ColorRed))
+			continue
+		}
+		for _, attr := range g.Attributes.Attribute {
+			switch v := attr.AttributeValue.(type) {
+			case kmip.Name:
+				name = v.NameValue
+			case kmip.State:
+				md.table.SetCell(row, 2, tview.NewTableCell(ttlv.EnumStr(v)))
+			}
+		}
+		for col, attr := range []kmip.AttributeName{kmip.AttributeNameInitialDate, kmip.AttributeNameActivationDate,
+			kmip.AttributeNameDeactivationDate, kmip.AttributeNameDestroyDate} {
+			md.table.SetCell(row, col+3, dateCell(g.Date(attr), now))
+		}
+		md.table.SetCell(row, 7, tview.NewTableCell(inUse(generations, i, now)))
+	}
+	md.setTitle(name)
+	md.summary.SetText(lineageSummary(generations, now))
+	md.table.ScrollToBeginning().Select(selected, 0)
+}
+
+// setTitle names the lineage after the logical name of the key, which Rekey
+// moves from one generation to the next.
+func (md *Lineage) setTitle(name string) {
+	title := "Rekey lineage"
+	if name != "" {
+		title += " of " + tview.Escape(name)
+	}
+	md.frame.SetTitle(title)
+}
+
+// dateCell shows a date of the lifecycle, grayed out when yet to come.
+func dateCell(t, now time.Time) *tview.TableCell {
+	if t.IsZero() {
+		return tview.NewTableCell("-").SetTextColor(tcell.ColorGray)
+	}
+	cell := tview.NewTableCell(t.Local().Format(time.DateTime))
+	if t.After(now) {
+		cell.SetTextColor(tcell.ColorGray)
+	}
+	return cell
+}
+
+// inUse tells how long the generation i was used for: from its activation to
+// the activation of the next one, or to its own deactivation if earlier. The
+// time of the generation still in use is counted up to now.
+func inUse(generations []*Generation, i int, now time.Time) string {
+	start, end := generationPeriod(generations, i, now)
+	if start.IsZero() || end.Before(start) {
+		return "-"
+	}
+	return formatDays(end.Sub(start))
+}
+
+// generationPeriod returns the activation date of the generation i and the
+// date it stopped being used, see inUse.
+func generationPeriod(generations []*Generation, i int, now time.Time) (start, end time.Time) {
+	g := generations[i]
+	start = g.Date(kmip.AttributeNameActivationDate)
+	if start.IsZero() || start.After(now) {
+		return time.Time{}, time.Time{}
+	}
+	end = now
+	if i+1 < len(generations) {
+		if next := generations[i+1].Date(kmip.AttributeNameActivationDate); !next.IsZero() && next.Before(end) {
+			end = next
+		}
+	}
+	if deactivation := g.Date(kmip.AttributeNameDeactivationDate); !deactivation.IsZero() && deactivation.Before(end) {
+		end = deactivation
+	}
+	return start, end
+}
+
+// lineageSummary tells the number of generations, how often the key was rotated
+// on average, and for how long the current generation has been in use.
+func lineageSummary(generations []*Generation, now time.Time) string {
+	s := fmt.Sprintf("%d generations", len(generations))
+	if len(generations) == 1 {
+		s = "1 generation"
+	}
+	var first, last time.Time
+	rotations := 0
+	for _, g := range generations {
+		if t := g.Date(kmip.AttributeNameActivationDate); !t.IsZero() && !t.After(now) {
+			if first.IsZero() {
+				first = t
+			}
+			last = t
+			rotations++
+		}
+	}
+	if rotations > 1 {
+		s += fmt.Sprintf(", rotated every %s on average", formatDays(last.Sub(first)/time.Duration(rotations-1)))
+	}
+	if !last.IsZero() {
+		s += fmt.Sprintf(", the latest activated %s ago", formatDays(now.Sub(last)))
+	}
+	return s
+}
+
+// formatDays formats a duration as a number of days.
+func formatDays(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days < 1 {
+		return "<1d"
+	}
+	return fmt.Sprintf("%dd", days)
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/rivo/tview"
+)
+
+// LinkedObject is an object of the family shown by [Links], with the objects
+// linked to it below.
+type LinkedObject struct {
+	// Link is how the parent object links to this one; zero for the root.
+	Link kmip.LinkType
+	ID   string
+	// Attributes are those of the object, nil when not loaded; Err tells why
+	// they couldn't be.
+	Attributes *payloads.GetAttributesResponsePayload
+	Err        error
+	Children   []*LinkedObject
+}
+
+// Links shows the family of an object as a tree: its key pair, certificates,
+// the keys it replaced or was replaced by ... Selecting an object jumps to it.
+type Links struct {
+	*tview.Flex
+	tree     *tview.TreeView
+	id       string
+	onDone   func(id string)
+	onCancel func()
+}
+
+func NewLinks() *Links {
+	md := &Links{}
+	md.tree = tview.NewTreeView().
+		SetSelectedFunc(func(node *tview.TreeNode) {
+			if id, ok := node.GetReference().(string); ok && md.onDone != nil {
+				md.onDone(id)
+			}
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape && md.onCancel != nil {
+				md.onCancel()
+			}
+		})
+	md.tree.SetBorder(true).SetTitle("Linked objects")
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.tree, 0, 4, true).
+			AddItem(nil, 0, 1, false),
+			0, 4, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+// OnDone is called with the id of the object selected.
+func (md *Links) OnDone(cb func(id string)) *Links {
+	md.onDone = cb
+	return md
+}
+
+func (md *Links) OnCancel(cb func()) *Links {
+	md.onCancel = cb
+	return md
+}
+
+// ObjectID returns the id of the object whose family is shown.
+func (md *Links) ObjectID() string {
+	return md.id
+}
+
+// SetLoading shows the object id while its family is being loaded.
+func (md *Links) SetLoading(id string) {
+	md.id = id
+	root := tview.NewTreeNode(tview.Escape(id) + " [gray]loading…[-]").SetReference(id)
+	md.tree.SetRoot(root).SetCurrentNode(root)
+}
+
+// SetFamily shows the family of an object, root being the object.
+func (md *Links) SetFamily(root *LinkedObject) {
+	md.id = root.ID
+	node := md.node(root)
+	md.tree.SetRoot(node).SetCurrentNode(node)
+}
+
+func (md *Links) node(obj *LinkedObject) *tview.TreeNode {
+	var b strings.Builder
+	if obj.Link != 0 {
+		fmt.Fprintf(&b, "[yellow]%s:[-] ", ttlv.EnumStr(obj.Link))
+	}
+	switch {
+	case obj.Err != nil:
+		fmt.Fprintf(&b, "%s [red]%s[-]", tview.Escape(obj.ID), tview.Escape(obj.Err.Error()))
+	case obj.Attributes == nil:
+		b.WriteString(tview.Escape(obj.ID))
+	default:
+		name, details := describe(obj.Attributes)
+		if name != "" {
+			fmt.Fprintf(&b, "[::b]%s[::-] ", tview.Escape(name))
+		}
+		fmt.Fprintf(&b, "%s [gray](%s)[-]", tview.Escape(obj.ID), tview.Escape(details))
+	}
+	node := tview.NewTreeNode(b.String()).SetReference(obj.ID)
+	for _, child := range obj.Children {
+		node.AddChild(md.node(child))
+	}
+	return node
+}
+
+// describe returns the name of an object, and its type and state.
+func describe(attrs *payloads.GetAttributesResponsePayload) (name, details string) {
+	var parts []string
+	for _, attr := range attrs.Attribute {
+		switch v := attr.AttributeValue.(type) {
+		case kmip.Name:
+			if name == "" {
+				name = v.NameValue
+			}
+		case kmip.ObjectType:
+			parts = append([]string{ttlv.EnumStr(v)}, parts...)
+		case kmip.State:
+			parts = append(parts, ttlv.EnumStr(v))
+		}
+	}
+	return name, strings.Join(parts, ", ")
+}
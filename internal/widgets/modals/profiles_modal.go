@@ -0,0 +1,86 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"slices"
+
+	"github.com/rivo/tview"
+)
+
+// Profiles lets the user pick the server profile to connect to.
+type Profiles struct {
+	*tview.Flex
+	list     *tview.List
+	names    []string
+	onCancel func()
+	onDone   func(string)
+}
+
+func NewProfiles() *Profiles {
+	md := &Profiles{}
+	md.list = tview.NewList().ShowSecondaryText(false).
+		SetDoneFunc(md.cancel).
+		SetSelectedFunc(func(i int, _, _ string, _ rune) { md.done(i) })
+	md.list.SetBorder(true).SetTitle("Switch server")
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.list, 0, 1, true).
+			AddItem(nil, 0, 1, false),
+			0, 2, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+func (md *Profiles) OnCancel(cb func()) *Profiles {
+	md.onCancel = cb
+	return md
+}
+
+// OnDone sets the callback receiving the name of the picked profile.
+func (md *Profiles) OnDone(cb func(string)) *Profiles {
+	md.onDone = cb
+	return md
+}
+
+// SetProfiles lists the given profiles, selecting and marking the current one.
+func (md *Profiles) SetProfiles(names []string, current string) *Profiles {
+	md.names = names
+	md.list.Clear()
+	for _, name := range names {
+		text := "  " + tview.Escape(name)
+		if name == current {
+			text = "[::b]* " + tview.Escape(name) + "[::-]"
+		}
+		md.list.AddItem(text, "", 0, nil)
+	}
+	md.list.SetCurrentItem(max(slices.Index(names, current), 0))
+	return md
+}
+
+func (md *Profiles) done(i int) {
+	if md.onDone != nil && i >= 0 && i < len(md.names) {
+		md.onDone(md.names[i])
+	}
+}
+
+func (md *Profiles) cancel() {
+	if md.onCancel != nil {
+		md.onCancel()
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// progressBarWidth is the number of cells of the progress bar.
+const progressBarWidth = 40
+
+// Progress shows the progress of a long running task, which <esc> cancels, then
+// its outcome until the user closes it.
+type Progress struct {
+	*tview.Flex
+	content  *tview.TextView
+	status   string
+	done     int
+	total    int
+	running  bool
+	onCancel func()
+	onDone   func()
+}
+
+func NewProgress() *Progress {
+	md := &Progress{}
+	md.content = tview.NewTextView().SetDynamicColors(true).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape || key == tcell.KeyEnter {
+				md.close()
+			}
+		})
+	md.content.SetBorder(true)
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.content, 0, 2, true).
+			AddItem(nil, 0, 1, false),
+			0, 1, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+// OnDone sets the function called when the user closes the page once the task
+// is over.
+func (md *Progress) OnDone(cb func()) *Progress {
+	md.onDone = cb
+	return md
+}
+
+// Start shows a new task, with a status line such as "Loading ...". onCancel is
+// called if the user cancels it.
+func (md *Progress) Start(title, status string, onCancel func()) {
+	md.running = true
+	md.onCancel = onCancel
+	md.status = status
+	md.content.SetTitle(title)
+	md.SetProgress(0, 0)
+}
+
+// SetProgress shows that done steps out of total are over; a zero total shows
+// the status alone, while the number of steps isn't known yet.
+func (md *Progress) SetProgress(done, total int) {
+	md.done, md.total = done, total
+	md.update()
+}
+
+// SetStatus changes the status line of the running task.
+func (md *Progress) SetStatus(status string) {
+	md.status = status
+	md.update()
+}
+
+func (md *Progress) update() {
+	if !md.running {
+		return
+	}
+	text := tview.Escape(md.status) + "\n\n"
+	if md.total > 0 {
+		filled := min(md.done, md.total) * progressBarWidth / md.total
+		text += fmt.Sprintf("[green]%s[gray]%s[-] %d/%d\n",
+			strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), md.done, md.total)
+	}
+	text += "\n[gray]<esc> Cancel"
+	md.content.SetText(text)
+}
+
+// Finish shows the outcome of the task, which may contain color tags, until the
+// user closes the page.
+func (md *Progress) Finish(outcome string) {
+	md.running = false
+	md.onCancel = nil
+	md.content.SetText(outcome + "\n\n[gray]<enter> Close")
+	md.content.ScrollToBeginning()
+}
+
+func (md *Progress) close() {
+	if md.running {
+		if md.onCancel != nil {
+			md.onCancel()
+		}
+		return
+	}
+	if md.onDone != nil {
+		md.onDone()
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/phsym/kmip-explorer/internal/components"
 
@@ -32,6 +33,7 @@ type Register struct {
 	*tview.Flex
 	innerFlex    *tview.Flex
 	form         *components.Form
+	dates        []*components.DateField
 	onRegisterCb func(func(*kmipclient.Client) (*payloads.RegisterResponsePayload, error))
 	onCancel     func()
 }
@@ -45,10 +47,12 @@ func (wg *Register) removeItem(label string) {
 }
 
 func NewRegister() *Register {
-	wg := &Register{form: components.NewForm()}
+	wg := &Register{form: components.NewForm(), dates: newScheduleFields()}
 	wg.form.
 		AddInputField("Name", "", 0, nil, nil).
-		AddDropDown("Object Type", nil, 0, nil).
+		AddDropDown("Object Type", nil, 0, nil)
+	wg.addDates()
+	wg.form.
 		AddButton("OK", wg.done).
 		AddButton("Cancel", wg.cancel).
 		SetButtonsAlign(tview.AlignCenter).
@@ -56,35 +60,7 @@ func NewRegister() *Register {
 
 	wg.form.GetButton(0).SetDisabled(true)
 
-	wg.form.GetFormItemByLabel("Object Type").(*tview.DropDown).SetOptions([]string{"Secret", "X509 Certificate", "AES Key", "Private Key", "Public Key"}, func(option string, optionIndex int) {
-		wg.removeItem("Secret Value") //FIXME: This will reset the field even if seletion has not changed
-		wg.removeItem("Base64")
-		wg.removeItem("PEM")
-		wg.removeItem("PEM Key")
-		wg.removeItem("Key")
-		wg.removeItem("Format")
-		wg.Flex.ResizeItem(wg.innerFlex, 9, 0)
-		switch option {
-		case "Secret":
-			wg.form.AddTextArea("Secret Value", "", 0, 5, 0, nil)
-			wg.form.AddCheckbox("Base64", false, nil)
-			wg.Flex.ResizeItem(wg.innerFlex, 17, 0)
-		case "X509 Certificate":
-			wg.form.AddTextArea("PEM", "", 65, 5, 0, nil)
-			wg.Flex.ResizeItem(wg.innerFlex, 15, 0)
-		case "AES Key":
-			wg.form.AddTextArea("Key", "", 65, 5, 0, nil)
-			wg.form.AddDropDown("Format", []string{"Hex", "Base 64"}, 0, nil)
-			wg.Flex.ResizeItem(wg.innerFlex, 17, 0)
-		case "Private Key", "Public Key":
-			wg.form.AddTextArea("PEM Key", "", 65, 5, 0, nil)
-			wg.Flex.ResizeItem(wg.innerFlex, 15, 0)
-		case "":
-		default:
-			panic("Unknown option " + option)
-		}
-		wg.form.GetButton(0).SetDisabled(false) //TODO: Enable button only is a value is provided
-	})
+	wg.form.GetFormItemByLabel("Object Type").(*tview.DropDown).SetOptions(registerTypes, wg.objectTypeChanged)
 
 	wg.form.Box.SetBorder(true).SetTitle("Register object")
 
@@ -95,14 +71,85 @@ func NewRegister() *Register {
 
 	wg.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(nil, 0, 1, false).
-		AddItem(wg.innerFlex, 9, 0, true).
+		AddItem(wg.innerFlex, 9+scheduleHeight, 0, true).
 		AddItem(nil, 0, 1, false)
 	return wg
 }
 
+// registerTypes are the object types offered, when the server supports them.
+var registerTypes = []string{"Secret", "X509 Certificate", "AES Key", "Private Key", "Public Key"}
+
+// registerObjectTypes maps the registerTypes to their KMIP object type.
+var registerObjectTypes = map[string]kmip.ObjectType{
+	"Secret":           kmip.ObjectTypeSecretData,
+	"X509 Certificate": kmip.ObjectTypeCertificate,
+	"AES Key":          kmip.ObjectTypeSymmetricKey,
+	"Private Key":      kmip.ObjectTypePrivateKey,
+	"Public Key":       kmip.ObjectTypePublicKey,
+}
+
+// SetCapabilities offers only the object types the server supports.
+func (wg *Register) SetCapabilities(caps Capabilities) *Register {
+	var types []string
+	for _, t := range registerTypes {
+		if caps.SupportsType(registerObjectTypes[t]) {
+			types = append(types, t)
+		}
+	}
+	wg.form.GetFormItemByLabel("Object Type").(*tview.DropDown).SetOptions(types, wg.objectTypeChanged)
+	wg.reset()
+	return wg
+}
+
+func (wg *Register) objectTypeChanged(option string, optionIndex int) {
+	wg.removeItem("Secret Value") //FIXME: This will reset the field even if seletion has not changed
+	wg.removeItem("Base64")
+	wg.removeItem("PEM")
+	wg.removeItem("PEM Key")
+	wg.removeItem("Key")
+	wg.removeItem("Format")
+	for _, d := range ScheduleDates {
+		wg.removeItem(string(d))
+	}
+	wg.Flex.ResizeItem(wg.innerFlex, 9+scheduleHeight, 0)
+	// The dates come after the value of the object.
+	defer wg.addDates()
+	switch option {
+	case "Secret":
+		wg.form.AddTextArea("Secret Value", "", 0, 5, 0, nil)
+		wg.form.AddCheckbox("Base64", false, nil)
+		wg.Flex.ResizeItem(wg.innerFlex, 17+scheduleHeight, 0)
+	case "X509 Certificate":
+		wg.form.AddTextArea("PEM", "", 65, 5, 0, nil)
+		wg.Flex.ResizeItem(wg.innerFlex, 15+scheduleHeight, 0)
+	case "AES Key":
+		wg.form.AddTextArea("Key", "", 65, 5, 0, nil)
+		wg.form.AddDropDown("Format", []string{"Hex", "Base 64"}, 0, nil)
+		wg.Flex.ResizeItem(wg.innerFlex, 17+scheduleHeight, 0)
+	case "Private Key", "Public Key":
+		wg.form.AddTextArea("PEM Key", "", 65, 5, 0, nil)
+		wg.Flex.ResizeItem(wg.innerFlex, 15+scheduleHeight, 0)
+	case "":
+	default:
+		panic("Unknown option " + option)
+	}
+	wg.form.GetButton(0).SetDisabled(false) //TODO: Enable button only is a value is provided
+}
+
+// addDates adds the fields of the dates to schedule, keeping their text.
+func (wg *Register) addDates() {
+	for _, f := range wg.dates {
+		wg.form.AddFormItem(f)
+	}
+}
+
 func (wg *Register) reset() {
 	wg.form.GetFormItemByLabel("Object Type").(*tview.DropDown).SetCurrentOption(-1)
 	wg.form.GetFormItemByLabel("Name").(*tview.InputField).SetText("")
+	for _, f := range wg.dates {
+		f.SetText("")
+	}
+	wg.form.SetTitle("Register object")
 	wg.form.GetButton(0).SetDisabled(true)
 	wg.form.SetFocus(0)
 }
@@ -125,55 +172,101 @@ func (wg *Register) cancel() {
 }
 
 func (wg *Register) done() {
+	dates, err := scheduleAttributes(wg.dates, time.Now())
+	if err != nil {
+		// Keep the form open so the date can be fixed.
+		wg.form.SetTitle("[red]" + tview.Escape(err.Error()))
+		return
+	}
 	defer wg.reset()
 	if wg.onRegisterCb == nil {
 		return
 	}
-	var f func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error)
-
 	_, objectType := wg.form.GetFormItemByLabel("Object Type").(*tview.DropDown).GetCurrentOption()
 	name := wg.form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
 
+	var value, format string
 	switch objectType {
 	case "Secret":
-		secretValueStr := wg.form.GetFormItemByLabel("Secret Value").(*tview.TextArea).GetText()
-		b64 := wg.form.GetFormItemByLabel("Base64").(*tview.Checkbox).IsChecked()
-		f = func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
-			secretValue := []byte(secretValueStr)
-			if b64 {
+		value = wg.form.GetFormItemByLabel("Secret Value").(*tview.TextArea).GetText()
+		if wg.form.GetFormItemByLabel("Base64").(*tview.Checkbox).IsChecked() {
+			format = "Base 64"
+		}
+	case "X509 Certificate":
+		value = wg.form.GetFormItemByLabel("PEM").(*tview.TextArea).GetText()
+	case "AES Key":
+		value = wg.form.GetFormItemByLabel("Key").(*tview.TextArea).GetText()
+		_, format = wg.form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+	case "Private Key", "Public Key":
+		value = wg.form.GetFormItemByLabel("PEM Key").(*tview.TextArea).GetText()
+	}
+	f, err := RegisterRequest(objectType, name, value, format, dates)
+	if err != nil {
+		// The options come from the form, so this should not happen
+		panic(err)
+	}
+	wg.onRegisterCb(f)
+}
+
+// RegisterTypes returns the object types RegisterRequest handles.
+func RegisterTypes() []string {
+	return registerTypes
+}
+
+// RegisterRequest builds the request registering an object of the given type
+// (see RegisterTypes) from its value, with a name if not empty. Certificates and
+// asymmetric keys are given in PEM format. format tells how the value of a
+// secret ("Text", the default, or "Base 64") or of an AES key ("Hex", the
+// default, or "Base 64") is encoded; it is decoded when the request is sent.
+// dates, such as the Activation Date, are set on the object (see
+// ScheduleDates).
+func RegisterRequest(objectType, name, value, format string, dates []kmip.Attribute) (func(*kmipclient.Client) (*payloads.RegisterResponsePayload, error), error) {
+	switch objectType {
+	case "Secret":
+		if format != "" && format != "Text" && format != "Base 64" {
+			return nil, fmt.Errorf("invalid secret format %q", format)
+		}
+		return func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
+			secretValue := []byte(value)
+			if format == "Base 64" {
 				var err error
-				secretValue, err = base64.StdEncoding.DecodeString(secretValueStr)
+				secretValue, err = base64.StdEncoding.DecodeString(value)
 				if err != nil {
 					return nil, fmt.Errorf("Invalid secret value: %w", err)
 				}
 			}
 			req := client.Register().Secret(kmip.SecretDataTypePassword, secretValue)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "X509 Certificate":
-		pemValue := wg.form.GetFormItemByLabel("PEM").(*tview.TextArea).GetText()
-		f = func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
-			req := client.Register().PemCertificate([]byte(pemValue))
+		return func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
+			req := client.Register().PemCertificate([]byte(value))
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "AES Key":
-		data := wg.form.GetFormItemByLabel("Key").(*tview.TextArea).GetText()
-		_, format := wg.form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
-		f = func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
-			var decode func(string) ([]byte, error)
-			switch format {
-			case "Hex":
-				decode = hex.DecodeString
-			case "Base 64":
-				decode = base64.StdEncoding.DecodeString
-			}
-			key, err := decode(data)
+		var decode func(string) ([]byte, error)
+		switch format {
+		case "", "Hex":
+			decode = hex.DecodeString
+		case "Base 64":
+			decode = base64.StdEncoding.DecodeString
+		default:
+			return nil, fmt.Errorf("invalid key format %q", format)
+		}
+		return func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
+			key, err := decode(value)
 			if err != nil {
 				return nil, err
 			}
@@ -182,30 +275,36 @@ func (wg *Register) done() {
 				kmip.CryptographicUsageEncrypt|kmip.CryptographicUsageDecrypt|kmip.CryptographicUsageWrapKey|kmip.CryptographicUsageUnwrapKey,
 				key,
 			)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "Private Key":
-		pemValue := wg.form.GetFormItemByLabel("PEM Key").(*tview.TextArea).GetText()
-		f = func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
-			req := client.Register().PemPrivateKey([]byte(pemValue), kmip.CryptographicUsageSign)
+		return func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
+			req := client.Register().PemPrivateKey([]byte(value), kmip.CryptographicUsageSign)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	case "Public Key":
-		pemValue := wg.form.GetFormItemByLabel("PEM Key").(*tview.TextArea).GetText()
-		f = func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
-			req := client.Register().PemPublicKey([]byte(pemValue), kmip.CryptographicUsageVerify)
+		return func(client *kmipclient.Client) (*payloads.RegisterResponsePayload, error) {
+			req := client.Register().PemPublicKey([]byte(value), kmip.CryptographicUsageVerify)
+			for _, d := range dates {
+				req = req.WithAttribute(d.AttributeName, d.AttributeValue)
+			}
 			if name != "" {
 				req = req.WithName(name)
 			}
 			return req.Exec()
-		}
+		}, nil
 	}
-
-	wg.onRegisterCb(f)
+	return nil, fmt.Errorf("unknown object type %q", objectType)
 }
@@ -32,6 +32,7 @@ import (
 type Rekey struct {
 	*tview.Flex
 	form     *components.Form
+	caps     Capabilities
 	onCancel func()
 	onDone   func(func(*kmipclient.Client, string) (any, error))
 }
@@ -73,6 +74,13 @@ func (md *Rekey) OnDone(cb func(func(*kmipclient.Client, string) (any, error)))
 	return md
 }
 
+// SetCapabilities makes the rekey of keys the server can't rekey fail early,
+// without sending a request.
+func (md *Rekey) SetCapabilities(caps Capabilities) *Rekey {
+	md.caps = caps
+	return md
+}
+
 func (md *Rekey) reset() {
 	md.form.SetFocus(0)
 	md.form.GetFormItemByLabel("Offset days").(*tview.InputField).SetText("")
@@ -94,7 +102,15 @@ func (md *Rekey) done() {
 		offset = time.Duration(i) * time.Hour * 24
 	}
 
-	md.onDone(func(c *kmipclient.Client, id string) (any, error) {
+	md.onDone(RekeyRequest(offset, md.caps))
+}
+
+// RekeyRequest builds the request rekeying a symmetric key, or a key pair given
+// its private key. A non negative offset sets the activation date of the new key
+// from the initialization date of the old one. Operations the server doesn't
+// support according to caps fail without sending a request.
+func RekeyRequest(offset time.Duration, caps Capabilities) func(*kmipclient.Client, string) (any, error) {
+	return func(c *kmipclient.Client, id string) (any, error) {
 		attrs, err := c.GetAttributes(id, kmip.AttributeNameObjectType).Exec()
 		if err != nil {
 			return nil, err
@@ -110,12 +126,18 @@ func (md *Rekey) done() {
 		}
 		switch oType {
 		case kmip.ObjectTypeSymmetricKey:
+			if !caps.Supports(kmip.OperationRekey) {
+				return nil, errors.New("The server does not support rekeying symmetric keys")
+			}
 			req := c.Rekey(id)
 			if offset >= 0 {
 				req = req.WithOffset(offset)
 			}
 			return req.Exec()
 		case kmip.ObjectTypePrivateKey:
+			if !caps.Supports(kmip.OperationRekeyKeyPair) {
+				return nil, errors.New("The server does not support rekeying key pairs")
+			}
 			req := c.RekeyKeyPair(id)
 			if offset >= 0 {
 				req = req.WithOffset(offset)
@@ -126,7 +148,7 @@ func (md *Rekey) done() {
 		default:
 			return nil, fmt.Errorf("Cannot rekey an object of type %s", ttlv.EnumStr(oType))
 		}
-	})
+	}
 }
 
 func (md *Rekey) cancel() {
@@ -24,6 +24,18 @@ import (
 	"github.com/rivo/tview"
 )
 
+// RevocationReasons are the revocation reasons, in the order of their KMIP code
+// starting at 1.
+var RevocationReasons = []string{
+	"Unspecified",
+	"Key Compromise",
+	"CA Compromise",
+	"Affiliation Change",
+	"Superseded",
+	"Cessation Of Operation",
+	"Privilege Withdraw",
+}
+
 type Revoke struct {
 	*tview.Flex
 	form     *components.Form
@@ -34,15 +46,7 @@ type Revoke struct {
 func NewRevoke() *Revoke {
 	md := &Revoke{}
 	md.form = components.NewForm()
-	md.form.AddDropDown("Reason", []string{
-		"Unspecified",
-		"Key Compromise",
-		"CA Compromise",
-		"Affiliation Change",
-		"Superseded",
-		"Cessation Of Operation",
-		"Privilege Withdraw",
-	}, 0, nil).
+	md.form.AddDropDown("Reason", RevocationReasons, 0, nil).
 		AddInputField("Message", "", 0, nil, nil).
 		AddButton("OK", md.done).
 		AddButton("Cancel", md.cancel).
@@ -85,13 +89,19 @@ func (md *Revoke) done() {
 	reasonCode, _ := md.form.GetFormItemByLabel("Reason").(*tview.DropDown).GetCurrentOption()
 	msg := md.form.GetFormItemByLabel("Message").(*tview.InputField).GetText()
 
-	md.onDone(func(c *kmipclient.Client, id string) (*payloads.RevokeResponsePayload, error) {
+	//nolint: gosec // there's no reason for reasonCode+1 to overflow int32
+	md.onDone(RevokeRequest(kmip.RevocationReasonCode(reasonCode+1), msg))
+}
+
+// RevokeRequest builds the request revoking an object for the given reason,
+// with an optional message.
+func RevokeRequest(reason kmip.RevocationReasonCode, msg string) func(*kmipclient.Client, string) (*payloads.RevokeResponsePayload, error) {
+	return func(c *kmipclient.Client, id string) (*payloads.RevokeResponsePayload, error) {
 		return c.Revoke(id).
-			//nolint: gosec // there's no reason for reasonCode+1 to overflow int32
-			WithRevocationReasonCode(kmip.RevocationReasonCode(reasonCode + 1)).
+			WithRevocationReasonCode(reason).
 			WithRevocationMessage(msg).
 			Exec()
-	})
+	}
 }
 
 func (md *Revoke) cancel() {
@@ -0,0 +1,61 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phsym/kmip-explorer/internal/components"
+
+	"github.com/ovh/kmip-go"
+)
+
+// ScheduleDates are the dates of the lifecycle which can be scheduled when
+// creating or registering an object, and changed later.
+var ScheduleDates = []kmip.AttributeName{
+	kmip.AttributeNameActivationDate,
+	kmip.AttributeNameDeactivationDate,
+	kmip.AttributeNameProcessStartDate,
+	kmip.AttributeNameProtectStopDate,
+}
+
+// scheduleHeight is the height the fields of ScheduleDates take in a form.
+var scheduleHeight = 2 * len(ScheduleDates)
+
+// newScheduleFields returns a date field for each of ScheduleDates.
+func newScheduleFields() []*components.DateField {
+	fields := make([]*components.DateField, len(ScheduleDates))
+	for i, name := range ScheduleDates {
+		fields[i] = components.NewDateField(string(name))
+	}
+	return fields
+}
+
+// scheduleAttributes returns the dates typed in the fields of ScheduleDates,
+// relative to now, leaving out the empty ones.
+func scheduleAttributes(fields []*components.DateField, now time.Time) ([]kmip.Attribute, error) {
+	var attrs []kmip.Attribute
+	for i, f := range fields {
+		date, err := f.Date(now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ScheduleDates[i], err)
+		}
+		if !date.IsZero() {
+			attrs = append(attrs, kmip.Attribute{AttributeName: ScheduleDates[i], AttributeValue: date})
+		}
+	}
+	return attrs, nil
+}
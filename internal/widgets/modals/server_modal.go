@@ -0,0 +1,140 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modals
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/ttlv"
+	"github.com/rivo/tview"
+)
+
+// Server shows what the server reported about itself in response to Query:
+// supported operations and object types, vendor identification, server
+// information, application namespaces and extensions.
+type Server struct {
+	*tview.Flex
+	content *tview.TextView
+	onDone  func()
+}
+
+func NewServer() *Server {
+	md := &Server{}
+	md.content = tview.NewTextView().SetDynamicColors(true).
+		SetDoneFunc(func(_ tcell.Key) { md.done() })
+	md.content.SetBorder(true).SetTitle("Server")
+	md.SetLoading()
+
+	md.Flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(md.content, 0, 2, true).
+			AddItem(nil, 0, 1, false),
+			0, 10, true).
+		AddItem(nil, 0, 1, false)
+	return md
+}
+
+func (md *Server) OnDone(cb func()) *Server {
+	md.onDone = cb
+	return md
+}
+
+// SetLoading clears the page while waiting for the response to Query.
+func (md *Server) SetLoading() {
+	md.content.SetText("Loading ...")
+}
+
+// SetQuery shows the response to Query, or the error it failed with.
+func (md *Server) SetQuery(resp kmip.OperationPayload, err error) {
+	if err != nil {
+		md.content.SetText("[red]Query failed:[-]\n" + tview.Escape(err.Error()))
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(resp))
+	if v.Kind() != reflect.Struct {
+		md.content.SetText(tview.Escape(string(ttlv.MarshalText(resp))))
+		return
+	}
+	var sb strings.Builder
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() || v.Field(i).IsZero() {
+			continue
+		}
+		sb.WriteString("[orange::b]" + fieldTitle(field.Name) + "[-::-]\n")
+		for _, line := range queryValueLines(v.Field(i).Interface()) {
+			sb.WriteString("  " + tview.Escape(line) + "\n")
+		}
+		sb.WriteByte('\n')
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("The server returned no information")
+	}
+	md.content.SetText(sb.String()).ScrollToBeginning()
+}
+
+// queryValueLines renders a field of the Query response, one line per item.
+func queryValueLines(value any) []string {
+	var lines []string
+	switch v := value.(type) {
+	case []kmip.Operation:
+		for _, op := range v {
+			lines = append(lines, ttlv.EnumStr(op))
+		}
+	case []kmip.ObjectType:
+		for _, ot := range v {
+			lines = append(lines, ttlv.EnumStr(ot))
+		}
+	case string:
+		lines = append(lines, v)
+	case []string:
+		lines = append(lines, v...)
+	default:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice {
+			return strings.Split(strings.TrimSpace(string(ttlv.MarshalText(value))), "\n")
+		}
+		for i := range rv.Len() {
+			lines = append(lines, strings.Split(strings.TrimSpace(string(ttlv.MarshalText(rv.Index(i).Interface()))), "\n")...)
+		}
+	}
+	return lines
+}
+
+// fieldTitle spells out a payload field name, e.g. "Vendor Identification" for
+// VendorIdentification.
+func fieldTitle(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func (md *Server) done() {
+	if md.onDone != nil {
+		md.onDone()
+	}
+}
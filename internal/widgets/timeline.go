@@ -0,0 +1,225 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/rivo/tview"
+)
+
+// upcomingWindow is how soon a date of the lifecycle must come to be
+// highlighted as upcoming.
+const upcomingWindow = 30 * 24 * time.Hour
+
+// lifecycleDates are the dates laid out by the timeline, with their label.
+var lifecycleDates = []struct {
+	name  kmip.AttributeName
+	label string
+}{
+	{kmip.AttributeNameInitialDate, "Initial"},
+	{kmip.AttributeNameActivationDate, "Activation"},
+	{kmip.AttributeNameProcessStartDate, "Process Start"},
+	{kmip.AttributeNameProtectStopDate, "Protect Stop"},
+	{kmip.AttributeNameDeactivationDate, "Deactivation"},
+	{kmip.AttributeNameCompromiseOccurrenceDate, "Compromise Occurrence"},
+	{kmip.AttributeNameCompromiseDate, "Compromise"},
+	{kmip.AttributeNameDestroyDate, "Destroy"},
+}
+
+// eventStatus tells how a date of the lifecycle stands relative to now.
+type eventStatus int
+
+const (
+	eventPast eventStatus = iota
+	// eventOverdue is a past date whose transition didn't happen, e.g. a key
+	// still active past its Deactivation Date.
+	eventOverdue
+	// eventUpcoming is a date within upcomingWindow.
+	eventUpcoming
+	eventFuture
+)
+
+var eventColors = map[eventStatus]tcell.Color{
+	eventPast:     tcell.ColorWhite,
+	eventOverdue:  tcell.ColorRed,
+	eventUpcoming: tcell.ColorYellow,
+	eventFuture:   tcell.ColorGray,
+}
+
+// lifecycleEvent is a date of the lifecycle of an object.
+type lifecycleEvent struct {
+	label  string
+	date   time.Time
+	status eventStatus
+}
+
+// lifecycleEvents returns the dates of the lifecycle of an object, in
+// chronological order.
+func lifecycleEvents(garp *payloads.GetAttributesResponsePayload, now time.Time) []lifecycleEvent {
+	var state kmip.State
+	dates := map[kmip.AttributeName]time.Time{}
+	for _, attr := range garp.Attribute {
+		switch v := attr.AttributeValue.(type) {
+		case kmip.State:
+			state = v
+		case time.Time:
+			dates[attr.AttributeName] = v
+		}
+	}
+	var events []lifecycleEvent
+	for _, d := range lifecycleDates {
+		date, ok := dates[d.name]
+		if !ok {
+			continue
+		}
+		status := eventPast
+		switch {
+		case date.After(now.Add(upcomingWindow)):
+			status = eventFuture
+		case date.After(now):
+			status = eventUpcoming
+		case d.name == kmip.AttributeNameActivationDate && state == kmip.StatePreActive,
+			d.name == kmip.AttributeNameDeactivationDate && state == kmip.StateActive:
+			status = eventOverdue
+		}
+		events = append(events, lifecycleEvent{label: d.label, date: date, status: status})
+	}
+	slices.SortStableFunc(events, func(a, b lifecycleEvent) int { return a.date.Compare(b.date) })
+	return events
+}
+
+// relativeTime tells how far date is from now, e.g. "in 3d" or "2h ago".
+func relativeTime(date, now time.Time) string {
+	d := date.Sub(now)
+	format := "in %s"
+	if d < 0 {
+		d, format = -d, "%s ago"
+	}
+	var s string
+	switch {
+	case d >= 24*time.Hour:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf(format, s)
+}
+
+// Timeline lays out the dates of the lifecycle of an object: on an axis from
+// the first to the last one, then one per line, with a mark for now. Upcoming
+// dates are highlighted, and overdue ones, such as a Deactivation Date passed
+// on a key still active, are shown in red.
+type Timeline struct {
+	*tview.Box
+	events []lifecycleEvent
+	now    time.Time
+}
+
+func NewTimeline() *Timeline {
+	tl := &Timeline{Box: tview.NewBox()}
+	tl.SetBorder(true).SetTitle("Lifecycle")
+	return tl
+}
+
+// SetObject lays out the dates of garp; nil clears the timeline.
+func (tl *Timeline) SetObject(garp *payloads.GetAttributesResponsePayload) {
+	tl.now = time.Now()
+	tl.events = nil
+	if garp != nil {
+		tl.events = lifecycleEvents(garp, tl.now)
+	}
+}
+
+// Height returns the height the timeline needs: the borders, the axis, a line
+// per date and one for now; zero without dates.
+func (tl *Timeline) Height() int {
+	if len(tl.events) == 0 {
+		return 0
+	}
+	return len(tl.events) + 4
+}
+
+func (tl *Timeline) Draw(screen tcell.Screen) {
+	tl.Box.DrawForSubclass(screen, tl)
+	x, y, width, height := tl.GetInnerRect()
+	if len(tl.events) == 0 || width < 3 || height < 1 {
+		return
+	}
+	tl.drawAxis(screen, x, y, width)
+
+	nowShown := false
+	row := y + 1
+	line := func(text string, color tcell.Color) {
+		if row < y+height {
+			tview.Print(screen, text, x, row, width, tview.AlignLeft, color)
+		}
+		row++
+	}
+	for _, e := range tl.events {
+		if !nowShown && e.date.After(tl.now) {
+			line(fmt.Sprintf("▶ %s  now", tl.now.Local().Format(time.DateTime)), tcell.ColorAqua)
+			nowShown = true
+		}
+		line(fmt.Sprintf("● %s  %s, %s", e.date.Local().Format(time.DateTime), e.label, relativeTime(e.date, tl.now)), eventColors[e.status])
+	}
+	if !nowShown {
+		line(fmt.Sprintf("▶ %s  now", tl.now.Local().Format(time.DateTime)), tcell.ColorAqua)
+	}
+}
+
+// drawAxis draws the dates and now as marks on a line of the given width, at
+// positions proportional to their time.
+func (tl *Timeline) drawAxis(screen tcell.Screen, x, y, width int) {
+	first, last := tl.now, tl.now
+	for _, e := range tl.events {
+		first, last = minTime(first, e.date), maxTime(last, e.date)
+	}
+	pos := func(t time.Time) int {
+		if !last.After(first) {
+			return 0
+		}
+		return int(float64(t.Sub(first)) / float64(last.Sub(first)) * float64(width-1))
+	}
+	style := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	for i := range width {
+		screen.SetContent(x+i, y, '─', nil, style)
+	}
+	for _, e := range tl.events {
+		screen.SetContent(x+pos(e.date), y, '●', nil, tcell.StyleDefault.Foreground(eventColors[e.status]))
+	}
+	screen.SetContent(x+pos(tl.now), y, '┃', nil, tcell.StyleDefault.Foreground(tcell.ColorAqua))
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package widgets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	garp := &payloads.GetAttributesResponsePayload{UniqueIdentifier: "a", Attribute: []kmip.Attribute{
+		{AttributeName: kmip.AttributeNameState, AttributeValue: kmip.StateActive},
+		{AttributeName: kmip.AttributeNameDeactivationDate, AttributeValue: now.Add(-day)},
+		{AttributeName: kmip.AttributeNameProtectStopDate, AttributeValue: now.Add(10 * day)},
+		{AttributeName: kmip.AttributeNameDestroyDate, AttributeValue: now.Add(90 * day)},
+		{AttributeName: kmip.AttributeNameInitialDate, AttributeValue: now.Add(-100 * day)},
+		{AttributeName: kmip.AttributeNameActivationDate, AttributeValue: now.Add(-99 * day)},
+		{AttributeName: kmip.AttributeNameLastChangeDate, AttributeValue: now},
+	}}
+
+	events := lifecycleEvents(garp, now)
+	want := []struct {
+		label  string
+		status eventStatus
+	}{
+		{"Initial", eventPast},
+		{"Activation", eventPast},
+		// Still active past its deactivation date.
+		{"Deactivation", eventOverdue},
+		{"Protect Stop", eventUpcoming},
+		{"Destroy", eventFuture},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("%d events, want %d: %v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].label != w.label || events[i].status != w.status {
+			t.Fatalf("event %d = %s (%d), want %s (%d)", i, events[i].label, events[i].status, w.label, w.status)
+		}
+	}
+
+	// A key activated as planned isn't overdue.
+	garp.Attribute[0].AttributeValue = kmip.StateDeactivated
+	if events := lifecycleEvents(garp, now); events[2].status != eventPast {
+		t.Fatalf("deactivation of a deactivated key: status %d, want past", events[2].status)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		date time.Time
+		want string
+	}{
+		{now.Add(72 * time.Hour), "in 3d"},
+		{now.Add(-5 * time.Hour), "5h ago"},
+		{now.Add(20 * time.Minute), "in 20m"},
+	} {
+		if got := relativeTime(tc.date, now); got != tc.want {
+			t.Errorf("relativeTime(%v) = %q, want %q", tc.date, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// action is an action of the object list which can be bound to another key.
+type action struct {
+	name string
+	// key is the default key, as shown in the help.
+	key string
+}
+
+// actions lists the actions which can be rebound, with their default key.
+var actions = []action{
+	{"refresh", "<ctrl+r>"},
+	{"create", "<shift+c>"},
+	{"register", "<shift+r>"},
+	{"content", "<space>"},
+	{"activate", "<a>"},
+	{"revoke", "<r>"},
+	{"destroy", "<ctrl+d>"},
+	{"rekey", "<ctrl+t>"},
+	{"quit", "<q>"},
+	{"search", "</>"},
+	{"sort", "<s>"},
+	{"reverse-sort", "<shift+s>"},
+	{"columns", "<c>"},
+	{"edit", "<e>"},
+	{"crypto", "<w>"},
+	{"server", "<i>"},
+	{"profiles", "<p>"},
+	{"export", "<x>"},
+	{"mark", "<m>"},
+	{"mark-range", "<shift+v>"},
+	{"mark-all", "<ctrl+a>"},
+	{"audit", "<shift+l>"},
+	{"inspector", "<shift+i>"},
+	{"links", "<f>"},
+	{"lineage", "<shift+h>"},
+	{"back", "<[>"},
+	{"forward", "<]>"},
+}
+
+// keyStroke is a key as told apart by the key handlers: a rune, or a special
+// key such as ctrl+d.
+type keyStroke struct {
+	key tcell.Key
+	r   rune
+}
+
+func strokeOf(event *tcell.EventKey) keyStroke {
+	if event.Key() == tcell.KeyRune {
+		return keyStroke{tcell.KeyRune, event.Rune()}
+	}
+	return keyStroke{key: event.Key()}
+}
+
+func (k keyStroke) event() *tcell.EventKey {
+	return tcell.NewEventKey(k.key, k.r, tcell.ModNone)
+}
+
+// parseKey parses a key: a single character, "space", "shift+<letter>",
+// "ctrl+<letter>", or a key name such as "F5" or "Delete".
+func parseKey(spec string) (keyStroke, error) {
+	if utf8.RuneCountInString(spec) == 1 {
+		r, _ := utf8.DecodeRuneInString(spec)
+		return keyStroke{tcell.KeyRune, r}, nil
+	}
+	lower := strings.ToLower(spec)
+	if lower == "space" {
+		return keyStroke{tcell.KeyRune, ' '}, nil
+	}
+	if l, ok := strings.CutPrefix(lower, "shift+"); ok && len(l) == 1 && l[0] >= 'a' && l[0] <= 'z' {
+		return keyStroke{tcell.KeyRune, rune(l[0] - 'a' + 'A')}, nil
+	}
+	if l, ok := strings.CutPrefix(lower, "ctrl+"); ok && len(l) == 1 && l[0] >= 'a' && l[0] <= 'z' {
+		return keyStroke{key: tcell.KeyCtrlA + tcell.Key(l[0]-'a')}, nil
+	}
+	for k, name := range tcell.KeyNames {
+		if strings.EqualFold(name, spec) && k != tcell.KeyRune {
+			return keyStroke{key: k}, nil
+		}
+	}
+	return keyStroke{}, fmt.Errorf("unknown key %q", spec)
+}
+
+// KeyBindings binds actions to other keys than their default one. Build it with
+// [ParseKeyBindings] and hand it to [WithKeyBindings].
+type KeyBindings struct {
+	// remap maps the keys actions are bound to, to their default key.
+	remap map[keyStroke]keyStroke
+	// unbound are the default keys of rebound actions that no action uses.
+	unbound map[keyStroke]bool
+	// help maps the default help key of rebound actions to their new one.
+	help map[string]string
+}
+
+// ParseKeyBindings binds actions to keys, from a map of action names to keys.
+// The actions are refresh, create, register, content, activate, revoke,
+// destroy, rekey, quit, search, sort, reverse-sort, columns, edit, crypto,
+// server, profiles, export, mark, mark-range, mark-all, audit, inspector, links,
+// lineage, back and forward. A key is a single character, "space", "shift+<letter>",
+// "ctrl+<letter>", or a key name such as "F5" or "Delete". The default key of a
+// rebound action is freed; two actions can't share a key.
+func ParseKeyBindings(bindings map[string]string) (*KeyBindings, error) {
+	kb := &KeyBindings{
+		remap:   map[keyStroke]keyStroke{},
+		unbound: map[keyStroke]bool{},
+		help:    map[string]string{},
+	}
+	bound := map[keyStroke]string{}
+	for _, a := range actions {
+		def, err := parseKey(strings.Trim(a.key, "<>"))
+		if err != nil {
+			panic(err) // the default keys are valid
+		}
+		key := def
+		if spec, ok := bindings[a.name]; ok {
+			if key, err = parseKey(spec); err != nil {
+				return nil, fmt.Errorf("%s: %w", a.name, err)
+			}
+			if key != def {
+				kb.remap[key] = def
+				kb.unbound[def] = true
+				kb.help[a.key] = "<" + spec + ">"
+			}
+		}
+		if other, ok := bound[key]; ok {
+			return nil, fmt.Errorf("%s and %s are both bound to the same key", other, a.name)
+		}
+		bound[key] = a.name
+	}
+	for name := range bindings {
+		if !slices.ContainsFunc(actions, func(a action) bool { return a.name == name }) {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+	}
+	for key := range kb.unbound {
+		if _, ok := bound[key]; ok {
+			delete(kb.unbound, key)
+		}
+	}
+	return kb, nil
+}
+
+// translate turns a key bound to an action into the action's default key,
+// which the key handlers expect. The default key of a rebound action is
+// dropped.
+func (kb *KeyBindings) translate(event *tcell.EventKey) *tcell.EventKey {
+	key := strokeOf(event)
+	if def, ok := kb.remap[key]; ok {
+		return def.event()
+	}
+	if kb.unbound[key] {
+		return nil
+	}
+	return event
+}
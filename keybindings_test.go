@@ -0,0 +1,70 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeyBindingsTranslate(t *testing.T) {
+	kb, err := ParseKeyBindings(map[string]string{"destroy": "z", "quit": "ctrl+q", "activate": "q"})
+	if err != nil {
+		t.Fatalf("ParseKeyBindings: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		in   *tcell.EventKey
+		want keyStroke // zero when dropped
+	}{
+		{"rebound", tcell.NewEventKey(tcell.KeyRune, 'z', tcell.ModNone), keyStroke{key: tcell.KeyCtrlD}},
+		{"freed default", tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl), keyStroke{}},
+		{"default reused", tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), keyStroke{tcell.KeyRune, 'a'}},
+		{"freed default a", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), keyStroke{}},
+		{"ctrl", tcell.NewEventKey(tcell.KeyCtrlQ, 0, tcell.ModCtrl), keyStroke{tcell.KeyRune, 'q'}},
+		{"untouched", tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone), keyStroke{tcell.KeyRune, 'r'}},
+	} {
+		got := kb.translate(tc.in)
+		if got == nil {
+			if tc.want != (keyStroke{}) {
+				t.Fatalf("%s: dropped, want %v", tc.name, tc.want)
+			}
+			continue
+		}
+		if s := strokeOf(got); s != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, s, tc.want)
+		}
+	}
+	if got := kb.help["<ctrl+d>"]; got != "<z>" {
+		t.Fatalf("help for destroy = %q, want <z>", got)
+	}
+}
+
+func TestParseKeyBindingsErrors(t *testing.T) {
+	for _, bindings := range []map[string]string{
+		{"explode": "x"},
+		{"destroy": "ctrl+1"},
+		{"destroy": "r"}, // revoke keeps <r>
+		{"destroy": "x", "rekey": "x"},
+	} {
+		if _, err := ParseKeyBindings(bindings); err == nil {
+			t.Fatalf("ParseKeyBindings(%v) should fail", bindings)
+		}
+	}
+	if _, err := ParseKeyBindings(map[string]string{"destroy": "F5", "revoke": "shift+x", "content": "space"}); err != nil {
+		t.Fatalf("ParseKeyBindings: %v", err)
+	}
+}
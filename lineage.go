@@ -0,0 +1,94 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/phsym/kmip-explorer/internal/widgets/modals"
+)
+
+// loadLineage fetches the key id and the keys it replaced or was replaced by
+// through Rekey, following the Replaced Object links back to the first
+// generation and the Replacement Object links forward to the last one. The
+// generations are returned oldest first, at most maxFamily of them.
+func loadLineage(get func(id string) (*payloads.GetAttributesResponsePayload, error), id string) []*modals.Generation {
+	key := &modals.Generation{ID: id}
+	key.Attributes, key.Err = get(id)
+	generations := []*modals.Generation{key}
+	seen := map[string]bool{id: true}
+	for _, linkType := range []kmip.LinkType{kmip.LinkTypeReplacedObjectLink, kmip.LinkTypeReplacementObjectLink} {
+		g := key
+		for len(generations) < maxFamily {
+			next := linkedID(g.Attributes, linkType)
+			if next == "" || seen[next] {
+				break
+			}
+			seen[next] = true
+			g = &modals.Generation{ID: next}
+			g.Attributes, g.Err = get(next)
+			if linkType == kmip.LinkTypeReplacedObjectLink {
+				generations = append([]*modals.Generation{g}, generations...)
+			} else {
+				generations = append(generations, g)
+			}
+		}
+	}
+	return generations
+}
+
+// linkedID returns the id of the object linked by a link of the given type,
+// empty if none.
+func linkedID(attrs *payloads.GetAttributesResponsePayload, linkType kmip.LinkType) string {
+	if attrs == nil {
+		return ""
+	}
+	for _, attr := range attrs.Attribute {
+		if link, ok := attr.AttributeValue.(kmip.Link); ok && attr.AttributeName == kmip.AttributeNameLink && link.LinkType == linkType {
+			return link.LinkedObjectIdentifier
+		}
+	}
+	return ""
+}
+
+// rekeyedID returns the id of the key, or private key, a Rekey or RekeyKeyPair
+// created; empty for another response.
+func rekeyedID(resp any) string {
+	switch r := resp.(type) {
+	case *payloads.RekeyResponsePayload:
+		return r.UniqueIdentifier
+	case *payloads.RekeyKeyPairResponsePayload:
+		return r.PrivateKeyUniqueIdentifier
+	}
+	return ""
+}
+
+// showLineage opens the rekey lineage of the key id, loaded in the background.
+func (ex *Explorer) showLineage(id string) {
+	ex.lineageWidget.SetLoading(id)
+	ex.pages.ShowPage("lineage")
+	ex.app.SetFocus(ex.lineageWidget)
+	client := ex.client.Load()
+	go func() {
+		generations := loadLineage(func(id string) (*payloads.GetAttributesResponsePayload, error) {
+			return client.GetAttributes(id).Exec()
+		}, id)
+		ex.app.QueueUpdateDraw(func() {
+			if ex.lineageWidget.ObjectID() == id {
+				ex.lineageWidget.SetLineage(id, generations)
+			}
+		})
+	}()
+}
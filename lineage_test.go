@@ -0,0 +1,83 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+func TestLoadLineage(t *testing.T) {
+	activation := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// k1 -> k2 -> k3 -> k4, k2 also linked to its certificate; k4 is missing.
+	objects := map[string][]kmip.Attribute{
+		"k1": {link(kmip.LinkTypeReplacementObjectLink, "k2")},
+		"k2": {link(kmip.LinkTypeCertificateLink, "cert"), link(kmip.LinkTypeReplacedObjectLink, "k1"), link(kmip.LinkTypeReplacementObjectLink, "k3"),
+			{AttributeName: kmip.AttributeNameActivationDate, AttributeValue: activation}},
+		"k3": {link(kmip.LinkTypeReplacedObjectLink, "k2"), link(kmip.LinkTypeReplacementObjectLink, "k4")},
+	}
+	var calls int
+	get := getter(objects, &calls)
+
+	generations := loadLineage(get, "k2")
+	var ids []string
+	for _, g := range generations {
+		ids = append(ids, g.ID)
+	}
+	if len(ids) != 4 || ids[0] != "k1" || ids[1] != "k2" || ids[2] != "k3" || ids[3] != "k4" {
+		t.Fatalf("lineage = %v, want [k1 k2 k3 k4]", ids)
+	}
+	if generations[3].Err == nil {
+		t.Fatalf("missing generation loaded, want an error")
+	}
+	if got := generations[1].Date(kmip.AttributeNameActivationDate); !got.Equal(activation) {
+		t.Fatalf("activation date of k2 = %v, want %v", got, activation)
+	}
+
+	// A key never rekeyed is its own lineage.
+	objects["alone"] = nil
+	if generations := loadLineage(get, "alone"); len(generations) != 1 {
+		t.Fatalf("lineage of a key never rekeyed has %d generations, want 1", len(generations))
+	}
+}
+
+func TestLoadLineageCycle(t *testing.T) {
+	// Links looping back must not be followed forever.
+	get := func(id string) (*payloads.GetAttributesResponsePayload, error) {
+		next := map[string]string{"a": "b", "b": "a"}[id]
+		return &payloads.GetAttributesResponsePayload{UniqueIdentifier: id, Attribute: []kmip.Attribute{{
+			AttributeName:  kmip.AttributeNameLink,
+			AttributeValue: kmip.Link{LinkType: kmip.LinkTypeReplacementObjectLink, LinkedObjectIdentifier: next},
+		}}}, nil
+	}
+	if generations := loadLineage(get, "a"); len(generations) != 2 {
+		t.Fatalf("lineage of a cycle has %d generations, want 2", len(generations))
+	}
+}
+
+func TestRekeyedID(t *testing.T) {
+	if id := rekeyedID(&payloads.RekeyResponsePayload{UniqueIdentifier: "new"}); id != "new" {
+		t.Fatalf("rekeyedID(Rekey) = %q, want new", id)
+	}
+	if id := rekeyedID(&payloads.RekeyKeyPairResponsePayload{PrivateKeyUniqueIdentifier: "priv", PublicKeyUniqueIdentifier: "pub"}); id != "priv" {
+		t.Fatalf("rekeyedID(RekeyKeyPair) = %q, want priv", id)
+	}
+	if id := rekeyedID(&payloads.ActivateResponsePayload{UniqueIdentifier: "x"}); id != "" {
+		t.Fatalf("rekeyedID(Activate) = %q, want empty", id)
+	}
+}
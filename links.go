@@ -0,0 +1,126 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/phsym/kmip-explorer/internal/widgets/modals"
+)
+
+// maxFamily bounds the number of objects loadFamily fetches, for the servers
+// where long rekey chains or certificate hierarchies link many objects.
+const maxFamily = 50
+
+// loadFamily fetches the object id and the objects linked to it, directly or
+// through others: its key pair, its certificates, the keys it replaced or was
+// replaced by, and so on. Each object appears once, below the first object
+// found linking to it, nearest first. Past maxFamily objects, the remaining
+// ones are listed without their attributes.
+func loadFamily(get func(id string) (*payloads.GetAttributesResponsePayload, error), id string) *modals.LinkedObject {
+	root := &modals.LinkedObject{ID: id}
+	seen := map[string]bool{id: true}
+	queue := []*modals.LinkedObject{root}
+	for fetched := 0; len(queue) > 0 && fetched < maxFamily; fetched++ {
+		obj := queue[0]
+		queue = queue[1:]
+		obj.Attributes, obj.Err = get(obj.ID)
+		if obj.Err != nil {
+			continue
+		}
+		for _, attr := range obj.Attributes.Attribute {
+			link, ok := attr.AttributeValue.(kmip.Link)
+			if !ok || attr.AttributeName != kmip.AttributeNameLink || seen[link.LinkedObjectIdentifier] {
+				continue
+			}
+			seen[link.LinkedObjectIdentifier] = true
+			child := &modals.LinkedObject{Link: link.LinkType, ID: link.LinkedObjectIdentifier}
+			obj.Children = append(obj.Children, child)
+			queue = append(queue, child)
+		}
+	}
+	return root
+}
+
+// jump selects the object id, linked to the selected one, remembering the
+// latter to come back to it with goBack.
+func (ex *Explorer) jump(id string) {
+	if cur := ex.selectedID(); cur != "" && cur != id {
+		ex.back = append(ex.back, cur)
+		ex.forward = nil
+	}
+	ex.goTo(id)
+}
+
+// goBack selects the object the last jump was made from.
+func (ex *Explorer) goBack() {
+	if len(ex.back) == 0 {
+		return
+	}
+	id := ex.back[len(ex.back)-1]
+	ex.back = ex.back[:len(ex.back)-1]
+	if cur := ex.selectedID(); cur != "" {
+		ex.forward = append(ex.forward, cur)
+	}
+	ex.goTo(id)
+}
+
+// goForward selects the object goBack left.
+func (ex *Explorer) goForward() {
+	if len(ex.forward) == 0 {
+		return
+	}
+	id := ex.forward[len(ex.forward)-1]
+	ex.forward = ex.forward[:len(ex.forward)-1]
+	if cur := ex.selectedID(); cur != "" {
+		ex.back = append(ex.back, cur)
+	}
+	ex.goTo(id)
+}
+
+func (ex *Explorer) selectedID() string {
+	if obj := ex.table.GetSelection(); obj != nil {
+		return obj.UniqueIdentifier
+	}
+	return ""
+}
+
+// goTo selects the object id in the table, listing it at the top when the tab
+// or the query leave it out, and clearing the filter when it hides it.
+func (ex *Explorer) goTo(id string) {
+	if !ex.table.Reveal(id) {
+		ex.search.SetText("")
+		ex.table.Reveal(id)
+	}
+	ex.app.SetFocus(ex.table)
+}
+
+// showLinks opens the family tree of the object id, loaded in the background.
+func (ex *Explorer) showLinks(id string) {
+	ex.linksWidget.SetLoading(id)
+	ex.pages.ShowPage("links")
+	ex.app.SetFocus(ex.linksWidget)
+	client := ex.client.Load()
+	go func() {
+		family := loadFamily(func(id string) (*payloads.GetAttributesResponsePayload, error) {
+			return client.GetAttributes(id).Exec()
+		}, id)
+		ex.app.QueueUpdateDraw(func() {
+			if ex.linksWidget.ObjectID() == id {
+				ex.linksWidget.SetFamily(family)
+			}
+		})
+	}()
+}
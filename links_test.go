@@ -0,0 +1,94 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+// link is a Link attribute of the given type to the object id.
+func link(typ kmip.LinkType, id string) kmip.Attribute {
+	return kmip.Attribute{AttributeName: kmip.AttributeNameLink, AttributeValue: kmip.Link{LinkType: typ, LinkedObjectIdentifier: id}}
+}
+
+// getter returns a GetAttributes of a server holding objects, by id, counting
+// its calls in calls.
+func getter(objects map[string][]kmip.Attribute, calls *int) func(id string) (*payloads.GetAttributesResponsePayload, error) {
+	return func(id string) (*payloads.GetAttributesResponsePayload, error) {
+		*calls++
+		attrs, ok := objects[id]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return &payloads.GetAttributesResponsePayload{UniqueIdentifier: id, Attribute: attrs}, nil
+	}
+}
+
+func TestLoadFamily(t *testing.T) {
+	// priv <-> pub, pub -> cert, priv replaced old, old links to a missing object.
+	objects := map[string][]kmip.Attribute{
+		"priv": {link(kmip.LinkTypePublicKeyLink, "pub"), link(kmip.LinkTypeReplacedObjectLink, "old")},
+		"pub":  {link(kmip.LinkTypePrivateKeyLink, "priv"), link(kmip.LinkTypeCertificateLink, "cert")},
+		"cert": {link(kmip.LinkTypePublicKeyLink, "pub")},
+		"old":  {link(kmip.LinkTypeReplacementObjectLink, "priv"), link(kmip.LinkTypeParentLink, "gone")},
+	}
+	calls := 0
+	get := getter(objects, &calls)
+
+	root := loadFamily(get, "priv")
+	if root.ID != "priv" || len(root.Children) != 2 {
+		t.Fatalf("root = %s with %d children, want priv with 2", root.ID, len(root.Children))
+	}
+	pub, old := root.Children[0], root.Children[1]
+	if pub.ID != "pub" || pub.Link != kmip.LinkTypePublicKeyLink || old.ID != "old" || old.Link != kmip.LinkTypeReplacedObjectLink {
+		t.Fatalf("children = %s (%v), %s (%v)", pub.ID, pub.Link, old.ID, old.Link)
+	}
+	// priv, already shown, isn't listed again below pub.
+	if len(pub.Children) != 1 || pub.Children[0].ID != "cert" || len(pub.Children[0].Children) != 0 {
+		t.Fatalf("pub children = %v, want cert only", pub.Children)
+	}
+	if len(old.Children) != 1 || old.Children[0].Err == nil {
+		t.Fatalf("old children = %v, want gone with an error", old.Children)
+	}
+	if calls != 5 {
+		t.Fatalf("%d objects fetched, want 5", calls)
+	}
+}
+
+func TestLoadFamilyBounded(t *testing.T) {
+	// A rekey chain longer than maxFamily.
+	get := func(id string) (*payloads.GetAttributesResponsePayload, error) {
+		next := id + "+"
+		return &payloads.GetAttributesResponsePayload{UniqueIdentifier: id, Attribute: []kmip.Attribute{{
+			AttributeName:  kmip.AttributeNameLink,
+			AttributeValue: kmip.Link{LinkType: kmip.LinkTypeReplacementObjectLink, LinkedObjectIdentifier: next},
+		}}}, nil
+	}
+	obj := loadFamily(get, "k")
+	depth := 1
+	for ; len(obj.Children) > 0; depth++ {
+		obj = obj.Children[0]
+	}
+	if depth != maxFamily+1 {
+		t.Fatalf("chain of %d objects, want %d", depth, maxFamily+1)
+	}
+	if obj.Attributes != nil || obj.Err != nil {
+		t.Fatalf("last object loaded, want it listed without its attributes")
+	}
+}
@@ -0,0 +1,850 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memkms is an in-memory KMIP server, standing in for a real one to
+// test or demonstrate the explorer without any infrastructure.
+//
+// It keeps the objects and their attributes and follows their lifecycle
+// (activation, revocation, destruction, rekeying), and generates the material
+// of the keys it creates, but it is no key manager: the keys can't be used for
+// any cryptographic operation, which Query tells the clients. The identifiers
+// of the objects are the same from one run to the other, in the order the
+// objects are created.
+//
+//	srv := memkms.New()
+//	ep, err := srv.Start(dir)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer ep.Close()
+//	client, err := kmipclient.Dial(ep.Addr,
+//		kmipclient.WithRootCAFile(ep.CAFile),
+//		kmipclient.WithClientCertFiles(ep.CertFile, ep.KeyFile),
+//	)
+package memkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipserver"
+	"github.com/ovh/kmip-go/payloads"
+)
+
+// versions are the protocol versions the server speaks, preferred first.
+var versions = []kmip.ProtocolVersion{
+	{ProtocolVersionMajor: 1, ProtocolVersionMinor: 4},
+	{ProtocolVersionMajor: 1, ProtocolVersionMinor: 3},
+	{ProtocolVersionMajor: 1, ProtocolVersionMinor: 2},
+	{ProtocolVersionMajor: 1, ProtocolVersionMinor: 1},
+	{ProtocolVersionMajor: 1, ProtocolVersionMinor: 0},
+}
+
+// operations are those the server handles, answered to Query.
+var operations = []kmip.Operation{
+	kmip.OperationDiscoverVersions,
+	kmip.OperationQuery,
+	kmip.OperationCreate,
+	kmip.OperationCreateKeyPair,
+	kmip.OperationRegister,
+	kmip.OperationLocate,
+	kmip.OperationGet,
+	kmip.OperationGetAttributes,
+	kmip.OperationAddAttribute,
+	kmip.OperationModifyAttribute,
+	kmip.OperationDeleteAttribute,
+	kmip.OperationActivate,
+	kmip.OperationRevoke,
+	kmip.OperationDestroy,
+	kmip.OperationRekey,
+}
+
+// objectTypes are the types of the objects the server creates or registers,
+// answered to Query.
+var objectTypes = []kmip.ObjectType{
+	kmip.ObjectTypeSymmetricKey,
+	kmip.ObjectTypePublicKey,
+	kmip.ObjectTypePrivateKey,
+	kmip.ObjectTypeCertificate,
+	kmip.ObjectTypeSecretData,
+}
+
+// managedAttributes are set by the server only: they are ignored in the
+// templates of new objects, and can't be added, modified or deleted.
+var managedAttributes = []kmip.AttributeName{
+	kmip.AttributeNameUniqueIdentifier,
+	kmip.AttributeNameObjectType,
+	kmip.AttributeNameState,
+	kmip.AttributeNameInitialDate,
+	kmip.AttributeNameLastChangeDate,
+	kmip.AttributeNameDestroyDate,
+	kmip.AttributeNameCompromiseDate,
+	kmip.AttributeNameCompromiseOccurrenceDate,
+	kmip.AttributeNameRevocationReason,
+}
+
+// immutableAttributes can be given in the template of a new object, but not
+// changed afterwards.
+var immutableAttributes = []kmip.AttributeName{
+	kmip.AttributeNameCryptographicAlgorithm,
+	kmip.AttributeNameCryptographicLength,
+}
+
+// multiInstance reports whether an object can have several attributes of the
+// given name.
+func multiInstance(name kmip.AttributeName) bool {
+	switch name {
+	case kmip.AttributeNameName, kmip.AttributeNameLink, kmip.AttributeNameObjectGroup:
+		return true
+	}
+	return strings.HasPrefix(string(name), "x-") || strings.HasPrefix(string(name), "y-")
+}
+
+// Server is an in-memory KMIP server. Serve it with [Server.Start], or with
+// [kmipserver.NewServer] and [Server.Handler]. It is safe for concurrent use.
+type Server struct {
+	mu      sync.Mutex
+	objects map[string]*object
+	// ids are the identifiers of the objects, oldest first.
+	ids []string
+	// lastID is the identifier of the latest object created, the default of
+	// requests without one.
+	lastID string
+}
+
+// object is a managed object: its attributes, and its value if known.
+type object struct {
+	attrs []kmip.Attribute
+	value kmip.Object
+}
+
+// New returns a server without any object.
+func New() *Server {
+	return &Server{objects: map[string]*object{}}
+}
+
+// Handler returns the handler of the requests sent to the server, those of
+// operations. The cryptographic operations and RekeyKeyPair are not supported.
+func (s *Server) Handler() kmipserver.RequestHandler {
+	exec := kmipserver.NewBatchExecutor()
+	exec.Route(kmip.OperationDiscoverVersions, kmipserver.HandleFunc(s.discoverVersions))
+	exec.Route(kmip.OperationQuery, kmipserver.HandleFunc(s.query))
+	exec.Route(kmip.OperationCreate, kmipserver.HandleFunc(s.create))
+	exec.Route(kmip.OperationCreateKeyPair, kmipserver.HandleFunc(s.createKeyPair))
+	exec.Route(kmip.OperationRegister, kmipserver.HandleFunc(s.register))
+	exec.Route(kmip.OperationLocate, kmipserver.HandleFunc(s.locate))
+	exec.Route(kmip.OperationGet, kmipserver.HandleFunc(s.get))
+	exec.Route(kmip.OperationGetAttributes, kmipserver.HandleFunc(s.getAttributes))
+	exec.Route(kmip.OperationAddAttribute, kmipserver.HandleFunc(s.addAttribute))
+	exec.Route(kmip.OperationModifyAttribute, kmipserver.HandleFunc(s.modifyAttribute))
+	exec.Route(kmip.OperationDeleteAttribute, kmipserver.HandleFunc(s.deleteAttribute))
+	exec.Route(kmip.OperationActivate, kmipserver.HandleFunc(s.activate))
+	exec.Route(kmip.OperationRevoke, kmipserver.HandleFunc(s.revoke))
+	exec.Route(kmip.OperationDestroy, kmipserver.HandleFunc(s.destroy))
+	exec.Route(kmip.OperationRekey, kmipserver.HandleFunc(s.rekey))
+	return exec
+}
+
+// Add stores an object without going through the protocol, e.g. to seed the
+// server, and returns its identifier. value is nil for an object without key
+// material. The attributes the server manages, such as the state or the
+// dates of the lifecycle, are ignored in attrs: set them with
+// [Server.SetAttribute].
+func (s *Server) Add(objectType kmip.ObjectType, value kmip.Object, attrs ...kmip.Attribute) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.newObject(objectType, now(), &kmip.TemplateAttribute{Attribute: attrs})
+	o.value = value
+	return o.id()
+}
+
+// SetAttribute sets an attribute of an object, replacing any other of the same
+// name, whatever the attribute. It allows putting objects in states the
+// protocol can't, such as keys created years ago.
+func (s *Server) SetAttribute(id string, name kmip.AttributeName, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(id)
+	if err != nil {
+		return err
+	}
+	o.set(name, value)
+	return nil
+}
+
+// Attributes returns the attributes of an object, nil if there is none of
+// that identifier.
+func (s *Server) Attributes(id string) []kmip.Attribute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[id]
+	if !ok {
+		return nil
+	}
+	o.tick(now())
+	return slices.Clone(o.attrs)
+}
+
+// IDs returns the identifiers of the objects, oldest first.
+func (s *Server) IDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.ids)
+}
+
+// now is the current time, to the second as KMIP dates are.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// newObject stores a new object of the given type, pre-active unless its
+// template sets an activation date which has passed.
+func (s *Server) newObject(objectType kmip.ObjectType, date time.Time, templates ...*kmip.TemplateAttribute) *object {
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte(strconv.Itoa(len(s.ids)+1))).String()
+	o := &object{}
+	o.set(kmip.AttributeNameUniqueIdentifier, id)
+	o.set(kmip.AttributeNameObjectType, objectType)
+	o.set(kmip.AttributeNameState, kmip.StatePreActive)
+	o.set(kmip.AttributeNameInitialDate, date)
+	o.set(kmip.AttributeNameLastChangeDate, date)
+	for _, tmpl := range templates {
+		if tmpl == nil {
+			continue
+		}
+		for _, name := range tmpl.Name {
+			o.add(kmip.AttributeNameName, name)
+		}
+		for _, attr := range tmpl.Attribute {
+			if slices.Contains(managedAttributes, attr.AttributeName) {
+				continue
+			}
+			if multiInstance(attr.AttributeName) {
+				o.add(attr.AttributeName, attr.AttributeValue)
+			} else {
+				o.set(attr.AttributeName, attr.AttributeValue)
+			}
+		}
+	}
+	o.tick(date)
+	s.objects[id] = o
+	s.ids = append(s.ids, id)
+	s.lastID = id
+	return o
+}
+
+// object returns the object of the given identifier, the latest created one if
+// empty.
+func (s *Server) object(id string) (*object, error) {
+	if id == "" {
+		id = s.lastID
+	}
+	o, ok := s.objects[id]
+	if !ok {
+		return nil, kmipserver.Errorf(kmip.ResultReasonItemNotFound, "Object %q not found", id)
+	}
+	o.tick(now())
+	return o, nil
+}
+
+func (o *object) id() string {
+	v, _ := o.get(kmip.AttributeNameUniqueIdentifier)
+	id, _ := v.(string)
+	return id
+}
+
+func (o *object) state() kmip.State {
+	v, _ := o.get(kmip.AttributeNameState)
+	st, _ := v.(kmip.State)
+	return st
+}
+
+func (o *object) objectType() kmip.ObjectType {
+	v, _ := o.get(kmip.AttributeNameObjectType)
+	ot, _ := v.(kmip.ObjectType)
+	return ot
+}
+
+// get returns the first attribute of the given name.
+func (o *object) get(name kmip.AttributeName) (any, bool) {
+	for _, attr := range o.attrs {
+		if attr.AttributeName == name {
+			return attr.AttributeValue, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the attributes of the given name with a single one.
+func (o *object) set(name kmip.AttributeName, value any) {
+	o.attrs = slices.DeleteFunc(o.attrs, func(attr kmip.Attribute) bool { return attr.AttributeName == name })
+	o.add(name, value)
+}
+
+// add appends an attribute, indexed after the others of the same name.
+func (o *object) add(name kmip.AttributeName, value any) kmip.Attribute {
+	var index int32
+	for _, attr := range o.attrs {
+		if attr.AttributeName == name {
+			index++
+		}
+	}
+	attr := kmip.Attribute{AttributeName: name, AttributeIndex: &index, AttributeValue: value}
+	o.attrs = append(o.attrs, attr)
+	return attr
+}
+
+// find returns the position in attrs of the attribute of the given name and
+// index, -1 if there is none.
+func (o *object) find(name kmip.AttributeName, index int32) int {
+	for i, attr := range o.attrs {
+		if attr.AttributeName == name && attr.AttributeIndex != nil && *attr.AttributeIndex == index {
+			return i
+		}
+	}
+	return -1
+}
+
+// reindex numbers again the attributes of the given name, after one of them
+// was deleted.
+func (o *object) reindex(name kmip.AttributeName) {
+	var index int32
+	for i, attr := range o.attrs {
+		if attr.AttributeName == name {
+			idx := index
+			o.attrs[i].AttributeIndex = &idx
+			index++
+		}
+	}
+}
+
+// tick moves the object to the state its dates tell at t: active once its
+// activation date passed, deactivated once its deactivation date did.
+func (o *object) tick(t time.Time) {
+	date := func(name kmip.AttributeName) (time.Time, bool) {
+		v, _ := o.get(name)
+		d, ok := v.(time.Time)
+		return d, ok && !d.After(t)
+	}
+	if _, ok := date(kmip.AttributeNameActivationDate); ok && o.state() == kmip.StatePreActive {
+		o.set(kmip.AttributeNameState, kmip.StateActive)
+	}
+	if _, ok := date(kmip.AttributeNameDeactivationDate); ok && (o.state() == kmip.StatePreActive || o.state() == kmip.StateActive) {
+		o.set(kmip.AttributeNameState, kmip.StateDeactivated)
+	}
+}
+
+// changed records a change of the object.
+func (o *object) changed() {
+	o.set(kmip.AttributeNameLastChangeDate, now())
+}
+
+// matches reports whether the object has every attribute of the Locate
+// criteria. Names match on their value only. As in KMIP, two instances of a date
+// attribute are a range, matching the dates between them, bounds included.
+func (o *object) matches(criteria []kmip.Attribute) bool {
+	var names []kmip.AttributeName
+	values := map[kmip.AttributeName][]any{}
+	for _, c := range criteria {
+		if _, ok := values[c.AttributeName]; !ok {
+			names = append(names, c.AttributeName)
+		}
+		values[c.AttributeName] = append(values[c.AttributeName], c.AttributeValue)
+	}
+	for _, name := range names {
+		if from, to, ok := dateRange(values[name]); ok {
+			if !o.hasDateIn(name, from, to) {
+				return false
+			}
+			continue
+		}
+		for _, v := range values[name] {
+			if !o.has(name, v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dateRange returns the range of dates the criteria values make, if they are a
+// pair of dates.
+func dateRange(values []any) (from, to time.Time, ok bool) {
+	if len(values) != 2 {
+		return time.Time{}, time.Time{}, false
+	}
+	from, ok1 := values[0].(time.Time)
+	to, ok2 := values[1].(time.Time)
+	if to.Before(from) {
+		from, to = to, from
+	}
+	return from, to, ok1 && ok2
+}
+
+// has reports whether the object has the attribute name with value v.
+func (o *object) has(name kmip.AttributeName, v any) bool {
+	for _, attr := range o.attrs {
+		if attr.AttributeName != name {
+			continue
+		}
+		switch v := v.(type) {
+		case kmip.Name:
+			if got, _ := attr.AttributeValue.(kmip.Name); got.NameValue == v.NameValue {
+				return true
+			}
+		case time.Time:
+			if got, ok := attr.AttributeValue.(time.Time); ok && got.Equal(v) {
+				return true
+			}
+		default:
+			if reflect.DeepEqual(attr.AttributeValue, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasDateIn reports whether the object has the date attribute name between from
+// and to.
+func (o *object) hasDateIn(name kmip.AttributeName, from, to time.Time) bool {
+	for _, attr := range o.attrs {
+		if d, ok := attr.AttributeValue.(time.Time); ok && attr.AttributeName == name && !d.Before(from) && !d.After(to) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) discoverVersions(_ context.Context, req *payloads.DiscoverVersionsRequestPayload) (*payloads.DiscoverVersionsResponsePayload, error) {
+	if len(req.ProtocolVersion) == 0 {
+		return &payloads.DiscoverVersionsResponsePayload{ProtocolVersion: versions}, nil
+	}
+	resp := &payloads.DiscoverVersionsResponsePayload{}
+	for _, v := range versions {
+		if slices.Contains(req.ProtocolVersion, v) {
+			resp.ProtocolVersion = append(resp.ProtocolVersion, v)
+		}
+	}
+	return resp, nil
+}
+
+// query tells the operations and object types the server supports.
+func (s *Server) query(_ context.Context, req *payloads.QueryRequestPayload) (*payloads.QueryResponsePayload, error) {
+	resp := &payloads.QueryResponsePayload{}
+	for _, fn := range req.QueryFunction {
+		switch fn {
+		case kmip.QueryFunctionQueryOperations:
+			resp.Operation = operations
+		case kmip.QueryFunctionQueryObjects:
+			resp.ObjectType = objectTypes
+		case kmip.QueryFunctionQueryServerInformation:
+			resp.VendorIdentification = "memkms, in-memory KMIP server"
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) create(_ context.Context, req *payloads.CreateRequestPayload) (*payloads.CreateResponsePayload, error) {
+	if req.ObjectType != kmip.ObjectTypeSymmetricKey {
+		return nil, kmipserver.Errorf(kmip.ResultReasonInvalidField, "Only symmetric keys can be created")
+	}
+	value, err := symmetricKey(&req.TemplateAttribute)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.newObject(req.ObjectType, now(), &req.TemplateAttribute)
+	o.value = value
+	return &payloads.CreateResponsePayload{ObjectType: req.ObjectType, UniqueIdentifier: o.id()}, nil
+}
+
+func (s *Server) createKeyPair(_ context.Context, req *payloads.CreateKeyPairRequestPayload) (*payloads.CreateKeyPairResponsePayload, error) {
+	privValue, pubValue, err := keyPair(req.CommonTemplateAttribute, req.PrivateKeyTemplateAttribute)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	date := now()
+	priv := s.newObject(kmip.ObjectTypePrivateKey, date, req.CommonTemplateAttribute, req.PrivateKeyTemplateAttribute)
+	pub := s.newObject(kmip.ObjectTypePublicKey, date, req.CommonTemplateAttribute, req.PublicKeyTemplateAttribute)
+	priv.value, pub.value = privValue, pubValue
+	priv.add(kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypePublicKeyLink, LinkedObjectIdentifier: pub.id()})
+	pub.add(kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypePrivateKeyLink, LinkedObjectIdentifier: priv.id()})
+	s.lastID = priv.id()
+	return &payloads.CreateKeyPairResponsePayload{
+		PrivateKeyUniqueIdentifier: priv.id(),
+		PublicKeyUniqueIdentifier:  pub.id(),
+	}, nil
+}
+
+func (s *Server) register(_ context.Context, req *payloads.RegisterRequestPayload) (*payloads.RegisterResponsePayload, error) {
+	if req.Object == nil {
+		return nil, kmipserver.Errorf(kmip.ResultReasonMissingData, "No object to register")
+	}
+	objectType := req.ObjectType
+	if objectType == 0 {
+		objectType = req.Object.ObjectType()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.newObject(objectType, now(), &req.TemplateAttribute)
+	o.value = req.Object
+	return &payloads.RegisterResponsePayload{UniqueIdentifier: o.id()}, nil
+}
+
+func (s *Server) locate(_ context.Context, req *payloads.LocateRequestPayload) (*payloads.LocateResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, id := range s.ids {
+		o := s.objects[id]
+		o.tick(now())
+		if o.matches(req.Attribute) {
+			ids = append(ids, id)
+		}
+	}
+	//nolint: gosec // the number of objects is far below the int32 limit
+	total := int32(len(ids))
+	ids = ids[min(int(max(req.OffsetItems, 0)), len(ids)):]
+	if req.MaximumItems > 0 && int(req.MaximumItems) < len(ids) {
+		ids = ids[:req.MaximumItems]
+	}
+	return &payloads.LocateResponsePayload{LocatedItems: &total, UniqueIdentifier: ids}, nil
+}
+
+func (s *Server) get(_ context.Context, req *payloads.GetRequestPayload) (*payloads.GetResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if o.value == nil {
+		return nil, kmipserver.Errorf(kmip.ResultReasonKeyValueNotPresent, "Object %s has no key material", o.id())
+	}
+	return &payloads.GetResponsePayload{ObjectType: o.objectType(), UniqueIdentifier: o.id(), Object: o.value}, nil
+}
+
+func (s *Server) getAttributes(_ context.Context, req *payloads.GetAttributesRequestPayload) (*payloads.GetAttributesResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	resp := &payloads.GetAttributesResponsePayload{UniqueIdentifier: o.id()}
+	for _, attr := range o.attrs {
+		if len(req.AttributeName) == 0 || slices.Contains(req.AttributeName, attr.AttributeName) {
+			resp.Attribute = append(resp.Attribute, attr)
+		}
+	}
+	return resp, nil
+}
+
+// checkModifiable refuses changing the attributes managed by the server.
+func checkModifiable(name kmip.AttributeName) error {
+	if slices.Contains(managedAttributes, name) || slices.Contains(immutableAttributes, name) {
+		return kmipserver.Errorf(kmip.ResultReasonPermissionDenied, "Attribute %s is read-only", name)
+	}
+	return nil
+}
+
+func (s *Server) addAttribute(_ context.Context, req *payloads.AddAttributeRequestPayload) (*payloads.AddAttributeResponsePayload, error) {
+	name := req.Attribute.AttributeName
+	if err := checkModifiable(name); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := o.get(name); ok && !multiInstance(name) {
+		return nil, kmipserver.Errorf(kmip.ResultReasonIllegalOperation, "Object %s already has attribute %s", o.id(), name)
+	}
+	attr := o.add(name, req.Attribute.AttributeValue)
+	o.tick(now())
+	o.changed()
+	return &payloads.AddAttributeResponsePayload{UniqueIdentifier: o.id(), Attribute: attr}, nil
+}
+
+func (s *Server) modifyAttribute(_ context.Context, req *payloads.ModifyAttributeRequestPayload) (*payloads.ModifyAttributeResponsePayload, error) {
+	name := req.Attribute.AttributeName
+	if err := checkModifiable(name); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	var index int32
+	if req.Attribute.AttributeIndex != nil {
+		index = *req.Attribute.AttributeIndex
+	}
+	i := o.find(name, index)
+	if i < 0 {
+		return nil, kmipserver.Errorf(kmip.ResultReasonItemNotFound, "Object %s has no attribute %s at index %d", o.id(), name, index)
+	}
+	o.attrs[i].AttributeValue = req.Attribute.AttributeValue
+	attr := o.attrs[i]
+	o.tick(now())
+	o.changed()
+	return &payloads.ModifyAttributeResponsePayload{UniqueIdentifier: o.id(), Attribute: &attr}, nil
+}
+
+func (s *Server) deleteAttribute(_ context.Context, req *payloads.DeleteAttributeRequestPayload) (*payloads.DeleteAttributeResponsePayload, error) {
+	if err := checkModifiable(req.AttributeName); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	var index int32
+	if req.AttributeIndex != nil {
+		index = *req.AttributeIndex
+	}
+	i := o.find(req.AttributeName, index)
+	if i < 0 {
+		return nil, kmipserver.Errorf(kmip.ResultReasonItemNotFound, "Object %s has no attribute %s at index %d", o.id(), req.AttributeName, index)
+	}
+	attr := o.attrs[i]
+	o.attrs = slices.Delete(o.attrs, i, i+1)
+	o.reindex(req.AttributeName)
+	o.changed()
+	return &payloads.DeleteAttributeResponsePayload{UniqueIdentifier: o.id(), Attribute: &attr}, nil
+}
+
+func (s *Server) activate(_ context.Context, req *payloads.ActivateRequestPayload) (*payloads.ActivateResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if o.state() != kmip.StatePreActive {
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is not pre-active", o.id())
+	}
+	o.set(kmip.AttributeNameActivationDate, now())
+	o.set(kmip.AttributeNameState, kmip.StateActive)
+	o.changed()
+	return &payloads.ActivateResponsePayload{UniqueIdentifier: o.id()}, nil
+}
+
+// revoke deactivates the object, or marks it compromised for a key or CA
+// compromise.
+func (s *Server) revoke(_ context.Context, req *payloads.RevokeRequestPayload) (*payloads.RevokeResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	date := now()
+	switch code := req.RevocationReason.RevocationReasonCode; {
+	case o.state() == kmip.StateDestroyed || o.state() == kmip.StateDestroyedCompromised:
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is destroyed", o.id())
+	case code == kmip.RevocationReasonCodeKeyCompromise || code == kmip.RevocationReasonCodeCACompromise:
+		occurrence := date
+		if req.CompromiseOccurrenceDate != nil {
+			occurrence = req.CompromiseOccurrenceDate.UTC()
+		}
+		o.set(kmip.AttributeNameCompromiseOccurrenceDate, occurrence)
+		o.set(kmip.AttributeNameCompromiseDate, date)
+		o.set(kmip.AttributeNameState, kmip.StateCompromised)
+	case o.state() == kmip.StatePreActive || o.state() == kmip.StateActive:
+		o.set(kmip.AttributeNameDeactivationDate, date)
+		o.set(kmip.AttributeNameState, kmip.StateDeactivated)
+	default:
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is not active", o.id())
+	}
+	o.set(kmip.AttributeNameRevocationReason, req.RevocationReason)
+	o.changed()
+	return &payloads.RevokeResponsePayload{UniqueIdentifier: o.id()}, nil
+}
+
+// destroy drops the value of an object which is not active, keeping its
+// attributes.
+func (s *Server) destroy(_ context.Context, req *payloads.DestroyRequestPayload) (*payloads.DestroyResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	switch o.state() {
+	case kmip.StateActive:
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is active, revoke it first", o.id())
+	case kmip.StateDestroyed, kmip.StateDestroyedCompromised:
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is already destroyed", o.id())
+	case kmip.StateCompromised:
+		o.set(kmip.AttributeNameState, kmip.StateDestroyedCompromised)
+	default:
+		o.set(kmip.AttributeNameState, kmip.StateDestroyed)
+	}
+	o.set(kmip.AttributeNameDestroyDate, now())
+	o.value = nil
+	o.changed()
+	return &payloads.DestroyResponsePayload{UniqueIdentifier: o.id()}, nil
+}
+
+// rekey replaces a symmetric key with a new one, which takes over its names and
+// is activated after the offset if any, right away otherwise.
+func (s *Server) rekey(_ context.Context, req *payloads.RekeyRequestPayload) (*payloads.RekeyResponsePayload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.object(req.UniqueIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if old.objectType() != kmip.ObjectTypeSymmetricKey {
+		return nil, kmipserver.Errorf(kmip.ResultReasonIllegalOperation, "Object %s is not a symmetric key", old.id())
+	}
+	if st := old.state(); st == kmip.StateDestroyed || st == kmip.StateDestroyedCompromised {
+		return nil, kmipserver.Errorf(kmip.ResultReasonWrongKeyLifecycleState, "Object %s is destroyed", old.id())
+	}
+
+	date := now()
+	tmpl := &kmip.TemplateAttribute{}
+	for _, attr := range old.attrs {
+		switch attr.AttributeName {
+		case kmip.AttributeNameLink, kmip.AttributeNameActivationDate, kmip.AttributeNameDeactivationDate,
+			kmip.AttributeNameProcessStartDate, kmip.AttributeNameProtectStopDate:
+			continue
+		}
+		tmpl.Attribute = append(tmpl.Attribute, attr)
+	}
+	var offset time.Duration
+	if req.Offset != nil {
+		offset = *req.Offset
+	}
+	tmpl.Attribute = append(tmpl.Attribute, kmip.Attribute{AttributeName: kmip.AttributeNameActivationDate, AttributeValue: date.Add(offset)})
+	value, err := symmetricKey(tmpl, req.TemplateAttribute)
+	if err != nil {
+		return nil, err
+	}
+	o := s.newObject(kmip.ObjectTypeSymmetricKey, date, tmpl, req.TemplateAttribute)
+	o.value = value
+
+	old.attrs = slices.DeleteFunc(old.attrs, func(attr kmip.Attribute) bool { return attr.AttributeName == kmip.AttributeNameName })
+	old.add(kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypeReplacementObjectLink, LinkedObjectIdentifier: o.id()})
+	old.changed()
+	o.add(kmip.AttributeNameLink, kmip.Link{LinkType: kmip.LinkTypeReplacedObjectLink, LinkedObjectIdentifier: old.id()})
+	return &payloads.RekeyResponsePayload{UniqueIdentifier: o.id()}, nil
+}
+
+// keyParams returns the algorithm and length of a key given in its templates,
+// zero if missing.
+func keyParams(templates ...*kmip.TemplateAttribute) (alg kmip.CryptographicAlgorithm, length int32) {
+	for _, tmpl := range templates {
+		if tmpl == nil {
+			continue
+		}
+		for _, attr := range tmpl.Attribute {
+			switch v := attr.AttributeValue.(type) {
+			case kmip.CryptographicAlgorithm:
+				alg = v
+			case int32:
+				if attr.AttributeName == kmip.AttributeNameCryptographicLength {
+					length = v
+				}
+			}
+		}
+	}
+	return alg, length
+}
+
+// keyBlock holds the plain key material of a key.
+func keyBlock(format kmip.KeyFormatType, alg kmip.CryptographicAlgorithm, length int32, material []byte) kmip.KeyBlock {
+	return kmip.KeyBlock{
+		KeyFormatType:          format,
+		CryptographicAlgorithm: alg,
+		CryptographicLength:    length,
+		KeyValue:               &kmip.KeyValue{Plain: &kmip.PlainKeyValue{KeyMaterial: kmip.KeyMaterial{Bytes: &material}}},
+	}
+}
+
+// symmetricKey generates random key material of the length given in the
+// templates.
+func symmetricKey(templates ...*kmip.TemplateAttribute) (*kmip.SymmetricKey, error) {
+	alg, length := keyParams(templates...)
+	if length <= 0 || length%8 != 0 {
+		return nil, kmipserver.Errorf(kmip.ResultReasonInvalidField, "Invalid or missing cryptographic length %d", length)
+	}
+	material := make([]byte, length/8)
+	if _, err := rand.Read(material); err != nil {
+		return nil, err
+	}
+	return &kmip.SymmetricKey{KeyBlock: keyBlock(kmip.KeyFormatTypeRaw, alg, length, material)}, nil
+}
+
+// keyPair generates an RSA key pair of the length given in the templates, or
+// an ECDSA one on the curve of that size, P-256 by default. The private key is
+// in PKCS#8, the public one in X.509.
+func keyPair(templates ...*kmip.TemplateAttribute) (*kmip.PrivateKey, *kmip.PublicKey, error) {
+	alg, length := keyParams(templates...)
+	var (
+		key crypto.Signer
+		err error
+	)
+	switch alg {
+	case kmip.CryptographicAlgorithmRSA:
+		if length < 1024 {
+			return nil, nil, kmipserver.Errorf(kmip.ResultReasonInvalidField, "Invalid or missing RSA modulus size %d", length)
+		}
+		key, err = rsa.GenerateKey(rand.Reader, int(length))
+	case kmip.CryptographicAlgorithmEC, kmip.CryptographicAlgorithmECDSA:
+		curve := map[int32]elliptic.Curve{384: elliptic.P384(), 521: elliptic.P521()}[length]
+		if curve == nil {
+			curve, length = elliptic.P256(), 256
+		}
+		key, err = ecdsa.GenerateKey(curve, rand.Reader)
+	default:
+		return nil, nil, kmipserver.Errorf(kmip.ResultReasonInvalidField, "Only RSA and EC key pairs can be created")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return &kmip.PrivateKey{KeyBlock: keyBlock(kmip.KeyFormatTypePKCS_8, alg, length, privDER)},
+		&kmip.PublicKey{KeyBlock: keyBlock(kmip.KeyFormatTypeX_509, alg, length, pubDER)},
+		nil
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memkms
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/payloads"
+	"github.com/phsym/kmip-explorer/internal/query"
+)
+
+func attribute(t *testing.T, s *Server, id string, name kmip.AttributeName) any {
+	t.Helper()
+	for _, attr := range s.Attributes(id) {
+		if attr.AttributeName == name {
+			return attr.AttributeValue
+		}
+	}
+	return nil
+}
+
+func keyMaterial(t *testing.T, s *Server, id string) []byte {
+	t.Helper()
+	resp, err := s.get(context.Background(), &payloads.GetRequestPayload{UniqueIdentifier: id})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	key, ok := resp.Object.(*kmip.SymmetricKey)
+	if !ok {
+		t.Fatalf("get returned a %T, want a symmetric key", resp.Object)
+	}
+	material, err := key.KeyMaterial()
+	if err != nil {
+		t.Fatalf("key material: %v", err)
+	}
+	return material
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	created, err := s.create(ctx, &payloads.CreateRequestPayload{
+		ObjectType: kmip.ObjectTypeSymmetricKey,
+		TemplateAttribute: kmip.TemplateAttribute{Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "k1"}},
+			{AttributeName: kmip.AttributeNameCryptographicAlgorithm, AttributeValue: kmip.CryptographicAlgorithmAES},
+			{AttributeName: kmip.AttributeNameCryptographicLength, AttributeValue: int32(256)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := created.UniqueIdentifier
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StatePreActive {
+		t.Fatalf("state after create = %v, want pre-active", st)
+	}
+
+	material := keyMaterial(t, s, id)
+	if len(material) != 32 {
+		t.Fatalf("key material of %d bytes, want 32", len(material))
+	}
+	if _, err := s.activate(ctx, &payloads.ActivateRequestPayload{UniqueIdentifier: id}); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, err := s.activate(ctx, &payloads.ActivateRequestPayload{UniqueIdentifier: id}); err == nil {
+		t.Fatalf("second activate succeeded")
+	}
+	if _, err := s.destroy(ctx, &payloads.DestroyRequestPayload{UniqueIdentifier: id}); err == nil {
+		t.Fatalf("destroy of an active key succeeded")
+	}
+
+	rekeyed, err := s.rekey(ctx, &payloads.RekeyRequestPayload{UniqueIdentifier: id})
+	if err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	newID := rekeyed.UniqueIdentifier
+	if newMaterial := keyMaterial(t, s, newID); len(newMaterial) != 32 || string(newMaterial) == string(material) {
+		t.Fatalf("key material of the new key = %x, want 32 other bytes", newMaterial)
+	}
+	if st := attribute(t, s, newID, kmip.AttributeNameState); st != kmip.StateActive {
+		t.Fatalf("state of the new key = %v, want active", st)
+	}
+	if name := attribute(t, s, newID, kmip.AttributeNameName); name != (kmip.Name{NameValue: "k1"}) {
+		t.Fatalf("name of the new key = %v, want k1", name)
+	}
+	if name := attribute(t, s, id, kmip.AttributeNameName); name != nil {
+		t.Fatalf("old key still named %v", name)
+	}
+	want := kmip.Link{LinkType: kmip.LinkTypeReplacementObjectLink, LinkedObjectIdentifier: newID}
+	if link := attribute(t, s, id, kmip.AttributeNameLink); link != want {
+		t.Fatalf("link of the old key = %v, want %v", link, want)
+	}
+
+	if _, err := s.revoke(ctx, &payloads.RevokeRequestPayload{
+		UniqueIdentifier: id,
+		RevocationReason: kmip.RevocationReason{RevocationReasonCode: kmip.RevocationReasonCodeSuperseded},
+	}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateDeactivated {
+		t.Fatalf("state after revoke = %v, want deactivated", st)
+	}
+	if _, err := s.destroy(ctx, &payloads.DestroyRequestPayload{UniqueIdentifier: id}); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateDestroyed {
+		t.Fatalf("state after destroy = %v, want destroyed", st)
+	}
+	if attribute(t, s, id, kmip.AttributeNameDestroyDate) == nil {
+		t.Fatalf("no destroy date")
+	}
+}
+
+func TestCreateKeyPair(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	created, err := s.createKeyPair(ctx, &payloads.CreateKeyPairRequestPayload{
+		CommonTemplateAttribute: &kmip.TemplateAttribute{Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameCryptographicAlgorithm, AttributeValue: kmip.CryptographicAlgorithmECDSA},
+			{AttributeName: kmip.AttributeNameCryptographicLength, AttributeValue: int32(384)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("createKeyPair: %v", err)
+	}
+	priv, err := s.get(ctx, &payloads.GetRequestPayload{UniqueIdentifier: created.PrivateKeyUniqueIdentifier})
+	if err != nil {
+		t.Fatalf("get private key: %v", err)
+	}
+	if _, err := priv.Object.(*kmip.PrivateKey).Pkcs8Pem(); err != nil {
+		t.Fatalf("private key: %v", err)
+	}
+	pub, err := s.get(ctx, &payloads.GetRequestPayload{UniqueIdentifier: created.PublicKeyUniqueIdentifier})
+	if err != nil {
+		t.Fatalf("get public key: %v", err)
+	}
+	if _, err := pub.Object.(*kmip.PublicKey).PkixPem(); err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	resp, err := New().query(context.Background(), &payloads.QueryRequestPayload{
+		QueryFunction: []kmip.QueryFunction{kmip.QueryFunctionQueryOperations, kmip.QueryFunctionQueryObjects},
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if !slices.Contains(resp.Operation, kmip.OperationRekey) || slices.Contains(resp.Operation, kmip.OperationRekeyKeyPair) ||
+		slices.Contains(resp.Operation, kmip.OperationEncrypt) {
+		t.Fatalf("operations = %v, want Rekey without RekeyKeyPair nor Encrypt", resp.Operation)
+	}
+	if !slices.Contains(resp.ObjectType, kmip.ObjectTypeSymmetricKey) {
+		t.Fatalf("object types = %v, want symmetric keys", resp.ObjectType)
+	}
+}
+
+func TestRevokeCompromise(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	id := s.Add(kmip.ObjectTypeSecretData, nil)
+	if _, err := s.revoke(ctx, &payloads.RevokeRequestPayload{
+		UniqueIdentifier: id,
+		RevocationReason: kmip.RevocationReason{RevocationReasonCode: kmip.RevocationReasonCodeKeyCompromise},
+	}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateCompromised {
+		t.Fatalf("state = %v, want compromised", st)
+	}
+	if _, err := s.destroy(ctx, &payloads.DestroyRequestPayload{UniqueIdentifier: id}); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateDestroyedCompromised {
+		t.Fatalf("state = %v, want destroyed compromised", st)
+	}
+}
+
+func TestDates(t *testing.T) {
+	s := New()
+	id := s.Add(kmip.ObjectTypeSymmetricKey, nil)
+	if err := s.SetAttribute(id, kmip.AttributeNameActivationDate, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateActive {
+		t.Fatalf("state once the activation date passed = %v, want active", st)
+	}
+	if err := s.SetAttribute(id, kmip.AttributeNameDeactivationDate, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if st := attribute(t, s, id, kmip.AttributeNameState); st != kmip.StateDeactivated {
+		t.Fatalf("state once the deactivation date passed = %v, want deactivated", st)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	aes := kmip.Attribute{AttributeName: kmip.AttributeNameCryptographicAlgorithm, AttributeValue: kmip.CryptographicAlgorithmAES}
+	for range 3 {
+		s.Add(kmip.ObjectTypeSymmetricKey, nil, aes)
+	}
+	s.Add(kmip.ObjectTypeSecretData, nil, kmip.Attribute{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "pw"}})
+
+	for _, tc := range []struct {
+		name      string
+		req       payloads.LocateRequestPayload
+		want      int
+		wantTotal int32
+	}{
+		{"all", payloads.LocateRequestPayload{}, 4, 4},
+		{"by type", payloads.LocateRequestPayload{Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameObjectType, AttributeValue: kmip.ObjectTypeSymmetricKey},
+		}}, 3, 3},
+		{"by name", payloads.LocateRequestPayload{Attribute: []kmip.Attribute{
+			{AttributeName: kmip.AttributeNameName, AttributeValue: kmip.Name{NameValue: "pw", NameType: kmip.NameTypeUninterpretedTextString}},
+		}}, 1, 1},
+		{"paged", payloads.LocateRequestPayload{MaximumItems: 2, OffsetItems: 1}, 2, 4},
+		{"past the end", payloads.LocateRequestPayload{OffsetItems: 10}, 0, 4},
+	} {
+		resp, err := s.locate(ctx, &tc.req)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(resp.UniqueIdentifier) != tc.want || *resp.LocatedItems != tc.wantTotal {
+			t.Fatalf("%s: got %d of %d objects, want %d of %d", tc.name, len(resp.UniqueIdentifier), *resp.LocatedItems, tc.want, tc.wantTotal)
+		}
+	}
+}
+
+func TestLocateDates(t *testing.T) {
+	s := New()
+	for _, day := range []string{"2024-06-01", "2025-03-01", "2025-09-01"} {
+		d, _ := time.Parse(time.DateOnly, day)
+		s.Add(kmip.ObjectTypeSymmetricKey, nil, kmip.Attribute{AttributeName: kmip.AttributeNameActivationDate, AttributeValue: d})
+	}
+	for q, want := range map[string]int{
+		"activation>=2025-01-01":                       2,
+		"activation<2025-01-01":                        1,
+		"activation>=2025-01-01 activation<2025-06-01": 1,
+		"activation=2025-03-01":                        1,
+		"activation>2026-01-01":                        0,
+	} {
+		criteria, err := query.Parse(q)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", q, err)
+		}
+		resp, err := s.locate(context.Background(), &payloads.LocateRequestPayload{Attribute: criteria})
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if len(resp.UniqueIdentifier) != want {
+			t.Fatalf("%s: got %d objects, want %d", q, len(resp.UniqueIdentifier), want)
+		}
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	id := s.Add(kmip.ObjectTypeSymmetricKey, nil)
+	add := func(name kmip.AttributeName, value any) error {
+		_, err := s.addAttribute(ctx, &payloads.AddAttributeRequestPayload{
+			UniqueIdentifier: id,
+			Attribute:        kmip.Attribute{AttributeName: name, AttributeValue: value},
+		})
+		return err
+	}
+	if err := add("x-team", "a"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := add("x-team", "b"); err != nil {
+		t.Fatalf("add of a second custom attribute: %v", err)
+	}
+	if err := add(kmip.AttributeNameState, kmip.StateActive); err == nil {
+		t.Fatalf("add of the state succeeded")
+	}
+	if err := add(kmip.AttributeNameContactInformation, "me"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := add(kmip.AttributeNameContactInformation, "you"); err == nil {
+		t.Fatalf("add of a second contact information succeeded")
+	}
+
+	zero := int32(0)
+	if _, err := s.deleteAttribute(ctx, &payloads.DeleteAttributeRequestPayload{
+		UniqueIdentifier: id, AttributeName: "x-team", AttributeIndex: &zero,
+	}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	resp, err := s.modifyAttribute(ctx, &payloads.ModifyAttributeRequestPayload{
+		UniqueIdentifier: id,
+		Attribute:        kmip.Attribute{AttributeName: "x-team", AttributeIndex: &zero, AttributeValue: "c"},
+	})
+	if err != nil {
+		t.Fatalf("modify of the remaining custom attribute at index 0: %v", err)
+	}
+	if resp.Attribute.AttributeValue != "c" {
+		t.Fatalf("modified value = %v, want c", resp.Attribute.AttributeValue)
+	}
+}
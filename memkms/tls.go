@@ -0,0 +1,154 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memkms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ovh/kmip-go/kmipserver"
+)
+
+// Endpoint is a server started with [Server.Start]: its address, and the files
+// a client needs to connect to it.
+type Endpoint struct {
+	// Addr is the host:port the server listens on.
+	Addr string
+	// CAFile is the PEM certificate of the authority which signed the
+	// certificates of the server and of the client.
+	CAFile string
+	// CertFile and KeyFile are the PEM certificate and private key of the
+	// client.
+	CertFile string
+	KeyFile  string
+
+	srv *kmipserver.Server
+}
+
+// Close stops the server.
+func (ep *Endpoint) Close() error {
+	return ep.srv.Shutdown()
+}
+
+// Start serves the KMIP protocol over TLS on a random port of the loopback
+// interface, until the returned Endpoint is closed. It writes the certificate
+// authority and the client certificate, all generated for the occasion, to
+// dir.
+func (s *Server) Start(dir string) (*Endpoint, error) {
+	ca, caKey, err := newCertificate("memkms CA", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	srvCert, srvKey, err := newCertificate("localhost", ca, caKey)
+	if err != nil {
+		return nil, err
+	}
+	cliCert, cliKey, err := newCertificate("memkms client", ca, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ep := &Endpoint{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+	if err := writePEM(ep.CAFile, "CERTIFICATE", ca.Raw); err != nil {
+		return nil, err
+	}
+	if err := writePEM(ep.CertFile, "CERTIFICATE", cliCert.Raw); err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cliKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writePEM(ep.KeyFile, "PRIVATE KEY", keyDER); err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{srvCert.Raw}, PrivateKey: srvKey, Leaf: srvCert}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ep.Addr = listener.Addr().String()
+	ep.srv = kmipserver.NewServer(listener, s.Handler())
+	go ep.srv.Serve() //nolint: errcheck // it fails once shut down
+	return ep, nil
+}
+
+// newCertificate generates a certificate for name, signed by the given
+// authority, or a self-signed authority if nil. Server certificates are valid
+// for localhost and the loopback addresses.
+func newCertificate(name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	switch {
+	case ca == nil:
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+		ca, caKey = tmpl, key
+	case name == "localhost":
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{"localhost"}
+		tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	default:
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating certificate for %s: %w", name, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func writePEM(path, blockType string, der []byte) error {
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ovh/kmip-go"
+	"github.com/ovh/kmip-go/kmipclient"
+)
+
+// ErrReadOnly is returned for the requests [ReadOnlyMiddleware] refuses.
+var ErrReadOnly = errors.New("read-only mode")
+
+// readOnlyOperations are the operations which leave the objects of the server
+// untouched, the only ones [ReadOnlyMiddleware] lets through.
+var readOnlyOperations = []kmip.Operation{
+	kmip.OperationLocate,
+	kmip.OperationCheck,
+	kmip.OperationGet,
+	kmip.OperationGetAttributes,
+	kmip.OperationGetAttributeList,
+	kmip.OperationValidate,
+	kmip.OperationQuery,
+	kmip.OperationDiscoverVersions,
+	kmip.OperationEncrypt,
+	kmip.OperationDecrypt,
+	kmip.OperationSign,
+	kmip.OperationSignatureVerify,
+	kmip.OperationMAC,
+	kmip.OperationMACVerify,
+}
+
+// mutatingActions are the help keys of the actions which modify objects, which
+// are removed in read-only mode (see [WithReadOnly]).
+var mutatingActions = []string{"<shift+c>", "<shift+r>", "<a>", "<r>", "<ctrl+d>", "<ctrl+t>", "<e>"}
+
+// ReadOnlyMiddleware returns a client middleware refusing, with [ErrReadOnly],
+// the requests which could modify objects on the server: only lookups,
+// queries and cryptographic operations are sent. Any other operation, including
+// those it doesn't know, is refused without reaching the server.
+func ReadOnlyMiddleware() kmipclient.Middleware {
+	return func(next kmipclient.Next, ctx context.Context, msg *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		for _, item := range msg.BatchItem {
+			if !slices.Contains(readOnlyOperations, item.Operation) {
+				return nil, fmt.Errorf("%w: %v refused", ErrReadOnly, item.Operation)
+			}
+		}
+		return next(ctx, msg)
+	}
+}
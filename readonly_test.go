@@ -0,0 +1,63 @@
+// Copyright 2025 Pierre-Henri Symoneaux
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ovh/kmip-go"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	mw := ReadOnlyMiddleware()
+	sent := 0
+	next := func(context.Context, *kmip.RequestMessage) (*kmip.ResponseMessage, error) {
+		sent++
+		return &kmip.ResponseMessage{}, nil
+	}
+	request := func(ops ...kmip.Operation) *kmip.RequestMessage {
+		msg := &kmip.RequestMessage{}
+		for _, op := range ops {
+			msg.BatchItem = append(msg.BatchItem, kmip.RequestBatchItem{Operation: op})
+		}
+		return msg
+	}
+
+	for _, op := range []kmip.Operation{kmip.OperationLocate, kmip.OperationGetAttributes, kmip.OperationQuery, kmip.OperationEncrypt} {
+		if _, err := mw(next, context.Background(), request(op)); err != nil {
+			t.Fatalf("operation %v: err = %v, want it sent", op, err)
+		}
+	}
+	if sent != 4 {
+		t.Fatalf("%d requests sent, want 4", sent)
+	}
+
+	sent = 0
+	for _, op := range []kmip.Operation{kmip.OperationCreate, kmip.OperationRegister, kmip.OperationActivate,
+		kmip.OperationRevoke, kmip.OperationDestroy, kmip.OperationRekey, kmip.OperationModifyAttribute} {
+		if _, err := mw(next, context.Background(), request(op)); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("operation %v: err = %v, want ErrReadOnly", op, err)
+		}
+	}
+	// A batch is refused as a whole when any of its items would modify objects.
+	if _, err := mw(next, context.Background(), request(kmip.OperationGet, kmip.OperationDestroy)); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("mixed batch: err = %v, want ErrReadOnly", err)
+	}
+	if sent != 0 {
+		t.Fatalf("%d refused requests sent to the server, want none", sent)
+	}
+}